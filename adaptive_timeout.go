@@ -0,0 +1,154 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: adaptive_timeout.go
+// Description: 按域名的历史抓取耗时自动设置超时（ADAPTIVE_TIMEOUT），
+// 慢但稳定的博客不再频繁超时，长期无法连接的域名也不再每次等满 HTTP_TIMEOUT 并反复重试
+
+package main
+
+import (
+	"errors"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// 自适应超时的参数
+const (
+	latencyHistorySize   = 20              // 每个域名保留的最近抓取记录数
+	adaptiveMinSamples   = 5               // 记录数少于该值时使用 HTTP_TIMEOUT
+	adaptiveTimeoutFloor = 5 * time.Second // 自适应超时的下限
+	adaptiveMargin       = 2 * time.Second // 在 p95 耗时的 1.5 倍之上额外留出的余量
+	latencyFailed        = -1              // 网络错误（超时、无法连接）的记录值
+	adaptiveProbeEvery   = 24 * time.Hour  // 失联的域名每隔该时长用 HTTP_TIMEOUT 试探一次，避免短超时下永远无法恢复
+)
+
+// recordLatency 记录一次抓取的耗时（毫秒），网络错误记为 latencyFailed，只保留最近 latencyHistorySize 条
+func (c *fetchCache) recordLatency(host string, ms int) {
+	if c == nil || host == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Latencies == nil {
+		c.Latencies = make(map[string][]int)
+	}
+	samples := append(c.Latencies[host], ms)
+	if len(samples) > latencyHistorySize {
+		samples = samples[len(samples)-latencyHistorySize:]
+	}
+	c.Latencies[host] = samples
+}
+
+// latencies 返回域名的抓取耗时记录
+func (c *fetchCache) latencies(host string) []int {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]int(nil), c.Latencies[host]...)
+}
+
+// latencyProbeDue 判断失联的域名是否到了用 HTTP_TIMEOUT 试探的时间，到期时记录本次试探时间
+func (c *fetchCache) latencyProbeDue(host string, now time.Time) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.Sub(c.LatencyProbes[host]) < adaptiveProbeEvery {
+		return false
+	}
+	if c.LatencyProbes == nil {
+		c.LatencyProbes = make(map[string]time.Time)
+	}
+	c.LatencyProbes[host] = now
+	return true
+}
+
+// adaptiveTimeout 根据耗时记录计算超时
+//
+// Description:
+//
+//	最近的记录全部是网络错误时视为失联，使用 adaptiveTimeoutFloor 与 HTTP_TIMEOUT 中较小的值；
+//	否则成功记录足够多时取 p95 耗时的 1.5 倍加 adaptiveMargin，限制在 adaptiveTimeoutFloor 与 limit（ADAPTIVE_TIMEOUT_MAX）之间；
+//	limit 不大于 0 时以 base 为上限，不会得到 0（不限时）的超时
+//
+// Returns:
+//   - time.Duration: 超时
+//   - bool         : 是否失联（调用方据此只尝试一次）
+//   - bool         : 记录不足时为 false，应使用 HTTP_TIMEOUT
+func adaptiveTimeout(samples []int, base, limit time.Duration) (time.Duration, bool, bool) {
+	if len(samples) < adaptiveMinSamples {
+		return 0, false, false
+	}
+	var ok []int
+	for _, ms := range samples {
+		if ms != latencyFailed {
+			ok = append(ok, ms)
+		}
+	}
+	if len(ok) == 0 {
+		return min(base, adaptiveTimeoutFloor), true, true
+	}
+	if len(ok) < adaptiveMinSamples {
+		return 0, false, false
+	}
+	slices.Sort(ok)
+	p95 := time.Duration(ok[(len(ok)*95+99)/100-1]) * time.Millisecond
+	timeout := p95*3/2 + adaptiveMargin
+	if limit <= 0 {
+		limit = max(base, adaptiveTimeoutFloor)
+	}
+	return min(max(timeout, adaptiveTimeoutFloor), limit), false, true
+}
+
+// adaptiveFetchOptions 在 fetchOptionsFor 的基础上按历史耗时调整超时
+//
+// Description:
+//
+//	只在开启 ADAPTIVE_TIMEOUT 且订阅未在 RSS 列表中指定 timeout 时生效；
+//	失联的域名未指定 retries 时只尝试一次，每 adaptiveProbeEvery 用 HTTP_TIMEOUT 试探一次，
+//	恢复后（出现成功记录）自动回到正常的超时与重试次数
+func adaptiveFetchOptions(e feedEntry, cfg *Config, cache *fetchCache) fetchOptions {
+	opts := fetchOptionsFor(e, cfg)
+	if !cfg.AdaptiveTimeout || e.Timeout > 0 {
+		return opts
+	}
+	host := urlnorm.Host(e.URL)
+	timeout, unreachable, ok := adaptiveTimeout(cache.latencies(host), opts.Timeout,
+		time.Duration(cfg.AdaptiveTimeoutMax)*time.Second)
+	if !ok {
+		return opts
+	}
+	if !unreachable || !cache.latencyProbeDue(host, time.Now()) {
+		opts.Timeout = timeout
+	}
+	if unreachable && e.Retries == 0 {
+		opts.Retry.MaxAttempts = 1
+	}
+	return opts
+}
+
+// recordFetchLatency 按抓取结果记录耗时，未开启 ADAPTIVE_TIMEOUT 时不记录
+//
+// Description:
+//
+//	只有网络层面的失败（超时、DNS 解析失败、无法连接等）记为失败；
+//	HTTP 错误状态码、解析失败说明服务器仍可连接，不计入耗时记录
+func recordFetchLatency(cfg *Config, cache *fetchCache, rssLink string, info fetchInfo, err error) {
+	if !cfg.AdaptiveTimeout {
+		return
+	}
+	host := urlnorm.Host(rssLink)
+	if err == nil {
+		cache.recordLatency(host, int(info.Latency.Milliseconds()))
+		return
+	}
+	var netErr net.Error
+	if errors.Is(err, ErrTimeout) || errors.As(err, &netErr) {
+		cache.recordLatency(host, latencyFailed)
+	}
+}
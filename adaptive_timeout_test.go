@@ -0,0 +1,47 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: adaptive_timeout_test.go
+// Description: 自适应超时的测试
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveTimeoutZeroLimit(t *testing.T) {
+	samples := []int{800, 900, 1000, 1100, 1200}
+	got, _, ok := adaptiveTimeout(samples, 30*time.Second, 0)
+	if !ok || got <= 0 || got > 30*time.Second {
+		t.Errorf("limit 为 0 时超时 = %v, want 在 (0, 30s] 之间", got)
+	}
+}
+
+func TestAdaptiveFetchOptionsProbesUnreachableHost(t *testing.T) {
+	cfg := &Config{HTTPTimeout: 30, AdaptiveTimeout: true, AdaptiveTimeoutMax: 60, Retry: retryStrategy{MaxAttempts: 3}}
+	cache := &fetchCache{}
+	for range latencyHistorySize {
+		cache.recordLatency("a.com", latencyFailed)
+	}
+	e := feedEntry{URL: "https://a.com/feed"}
+
+	// 首次失联：用 HTTP_TIMEOUT 试探一次，之后在间隔内使用短超时
+	if opts := adaptiveFetchOptions(e, cfg, cache); opts.Timeout != 30*time.Second || opts.Retry.MaxAttempts != 1 {
+		t.Errorf("试探: Timeout = %v, MaxAttempts = %d, want 30s, 1", opts.Timeout, opts.Retry.MaxAttempts)
+	}
+	if opts := adaptiveFetchOptions(e, cfg, cache); opts.Timeout != adaptiveTimeoutFloor {
+		t.Errorf("间隔内: Timeout = %v, want %v", opts.Timeout, adaptiveTimeoutFloor)
+	}
+
+	// 超过间隔后再次试探
+	cache.LatencyProbes["a.com"] = time.Now().Add(-adaptiveProbeEvery)
+	if opts := adaptiveFetchOptions(e, cfg, cache); opts.Timeout != 30*time.Second {
+		t.Errorf("超过间隔: Timeout = %v, want 30s", opts.Timeout)
+	}
+
+	// 试探成功后不再视为失联，成功记录不足时回到 HTTP_TIMEOUT 与正常的重试次数
+	cache.recordLatency("a.com", 1200)
+	if opts := adaptiveFetchOptions(e, cfg, cache); opts.Timeout != 30*time.Second || opts.Retry.MaxAttempts != 3 {
+		t.Errorf("恢复后: Timeout = %v, MaxAttempts = %d, want 30s, 3", opts.Timeout, opts.Retry.MaxAttempts)
+	}
+}
@@ -0,0 +1,133 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: archived_feeds.go
+// Description: 已归档的订阅（RSS 列表中 archived=true），如博主已停更、域名已转手的友链，
+// 不再抓取，但在 data.json 中保留其最后的文章并标记 archived
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// splitArchivedFeeds 将订阅分为需要抓取的订阅与已归档的订阅，保持原有顺序
+func splitArchivedFeeds(entries []feedEntry) (active, archived []feedEntry) {
+	for _, e := range entries {
+		if e.Archived {
+			archived = append(archived, e)
+		} else {
+			active = append(active, e)
+		}
+	}
+	return active, archived
+}
+
+// keepArchivedArticles 将已归档订阅最后的文章加入本次的文章列表
+//
+// Description:
+//
+//	文章取自上次的 data.json，其中没有时（如上次读取失败、数据被覆盖）取自抓取缓存中保存的副本；
+//	取到的文章标记 archived，分组与置顶以 RSS 列表中的配置为准，并更新抓取缓存中的副本；
+//	本次列表中已有的文章（如固定数据中的同一篇）不重复加入
+//
+// Parameters:
+//   - articles : 本次生成的文章，已排序
+//   - previous : 上次的 data.json 文章，读取失败时为空
+//   - archived : 已归档的订阅
+//   - cache    : 抓取缓存，可为 nil
+//
+// Returns:
+//   - []Article: 加入归档文章并重新排序后的文章列表
+func keepArchivedArticles(articles, previous []Article, archived []feedEntry, cache *fetchCache) []Article {
+	keys := make(map[string]bool, len(archived))
+	for _, e := range archived {
+		keys[urlnorm.Key(e.URL)] = true
+	}
+	cache.retainArchived(keys)
+	if len(archived) == 0 {
+		return articles
+	}
+	byFeed := make(map[string][]Article)
+	for _, p := range previous {
+		if p.Feed != "" {
+			key := urlnorm.Key(p.Feed)
+			byFeed[key] = append(byFeed[key], p)
+		}
+	}
+	present := make(map[string]bool, len(articles))
+	for _, a := range articles {
+		present[a.Link] = true
+	}
+
+	merged := make([]timedArticle, 0, len(articles)+len(archived))
+	for _, a := range articles {
+		merged = append(merged, timedArticle{a, publishedTime(a)})
+	}
+	for _, e := range archived {
+		key := urlnorm.Key(e.URL)
+		kept := byFeed[key]
+		if len(kept) == 0 {
+			kept = cache.archivedArticles(key)
+		}
+		if len(kept) == 0 {
+			fmt.Printf("[WARN] 已归档的订阅 %s 没有保存过的文章\n", e.URL)
+			continue
+		}
+		for i := range kept {
+			kept[i].Archived = true
+			kept[i].Group = e.Group
+			kept[i].Pinned = e.Pinned
+			kept[i].weight = e.Weight
+			if !present[kept[i].Link] {
+				present[kept[i].Link] = true
+				merged = append(merged, timedArticle{kept[i], publishedTime(kept[i])})
+			}
+		}
+		cache.setArchivedArticles(key, kept)
+	}
+
+	sortTimedArticles(merged)
+	result := make([]Article, 0, len(merged))
+	for _, m := range merged {
+		result = append(result, m.article)
+	}
+	return result
+}
+
+// archivedArticles 读取抓取缓存中保存的归档订阅文章
+func (c *fetchCache) archivedArticles(feedKey string) []Article {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Article(nil), c.Archived[feedKey]...)
+}
+
+// retainArchived 删除已不再归档（恢复抓取或从列表中删除）的订阅的文章副本
+func (c *fetchCache) retainArchived(feedKeys map[string]bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.Archived {
+		if !feedKeys[key] {
+			delete(c.Archived, key)
+		}
+	}
+}
+
+// setArchivedArticles 保存归档订阅的文章副本
+func (c *fetchCache) setArchivedArticles(feedKey string, articles []Article) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Archived == nil {
+		c.Archived = make(map[string][]Article)
+	}
+	c.Archived[feedKey] = articles
+}
@@ -0,0 +1,106 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: article_language.go
+// Description: 按文章语言过滤（ARTICLE_LANGUAGES），根据标题与正文使用的文字判断语言，
+// 双语博客只收录指定语言的文章（如只要中文文章）
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// articleLanguages 支持过滤的语言，按文字判断：en 表示拉丁字母书写的文章（英文及其他西文）
+var articleLanguages = []string{"zh", "ja", "ko", "en", "ru", "ar", "th"}
+
+// validateArticleLanguages 检查 ARTICLE_LANGUAGES 中的语言是否受支持
+func validateArticleLanguages(langs []string) error {
+	for _, lang := range langs {
+		if !slices.Contains(articleLanguages, lang) {
+			return fmt.Errorf("ARTICLE_LANGUAGES 中的语言无效: %s (可选: %s)", lang, strings.Join(articleLanguages, ", "))
+		}
+	}
+	return nil
+}
+
+// detectLanguage 根据文本中各类文字的数量判断语言，无法判断（没有文字）时返回空
+//
+// Description:
+//
+//	汉字、假名、谚文等按字计数，拉丁字母按单词计数，避免中文文章中夹杂的英文术语使其被判为英文；
+//	含有一定比例假名的中日文混排视为日文
+func detectLanguage(text string) string {
+	var han, kana, hangul, cyrillic, arabic, thai, latinWords int
+	inLatinWord := false
+	for _, r := range text {
+		isLatin := unicode.Is(unicode.Latin, r)
+		if isLatin && !inLatinWord {
+			latinWords++
+		}
+		inLatinWord = isLatin
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Arabic, r):
+			arabic++
+		case unicode.Is(unicode.Thai, r):
+			thai++
+		}
+	}
+
+	counts := map[string]int{"zh": han, "ko": hangul, "ru": cyrillic, "ar": arabic, "th": thai, "en": latinWords}
+	if kana > 0 && kana*5 >= han+kana {
+		counts["ja"], counts["zh"] = han+kana, 0
+	}
+	lang, best := "", 0
+	for _, l := range articleLanguages {
+		if counts[l] > best {
+			lang, best = l, counts[l]
+		}
+	}
+	return lang
+}
+
+// itemLanguage 判断文章的语言，标题与正文开头无法判断时使用订阅声明的语言（<language>）
+func itemLanguage(feed *gofeed.Feed, item *gofeed.Item) string {
+	body := item.Description
+	if body == "" {
+		body = item.Content
+	}
+	if lang := detectLanguage(item.Title + " " + htmlToText(body, 500)); lang != "" {
+		return lang
+	}
+	lang, _, _ := strings.Cut(strings.ToLower(feed.Language), "-")
+	return lang
+}
+
+// filterItemsByLanguage 只保留指定语言的文章，langs 为空时不过滤
+//
+// Description:
+//
+//	无法判断语言的文章（如只有图片）予以保留；返回被过滤掉的文章数
+func filterItemsByLanguage(feed *gofeed.Feed, langs []string) int {
+	if len(langs) == 0 || feed == nil {
+		return 0
+	}
+	kept := feed.Items[:0]
+	for _, item := range feed.Items {
+		if lang := itemLanguage(feed, item); lang == "" || slices.Contains(langs, lang) {
+			kept = append(kept, item)
+		}
+	}
+	removed := len(feed.Items) - len(kept)
+	clear(feed.Items[len(kept):])
+	feed.Items = kept
+	return removed
+}
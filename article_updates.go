@@ -0,0 +1,109 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: article_updates.go
+// Description: 识别已发布文章被修改（标题或更新时间变化）的情况，并在输出中标记
+
+package main
+
+import "time"
+
+// updateTimeTolerance 更新时间相差不超过该值时视为未变化，容忍博客程序重新生成订阅时的时钟偏差
+const updateTimeTolerance = time.Minute
+
+// markUpdatedArticles 对比新旧数据，为标题或更新时间发生变化的文章打上更新标记
+//
+// Description:
+//
+//	按文章链接匹配旧数据：若标题改变，记录旧标题；若 RSS 提供的更新时间改变，保留新的更新时间；
+//	两种情况都会设置 IsUpdated，前端可据此显示"更新于 ..."。一旦标记，后续运行会继续保留
+//	更新时间只是时区写法不同或有轻微的时钟偏差、发布时间只是格式不同或因时区相差一天时，沿用旧值，
+//	调整时区等配置后不会让所有文章都显示为已变化
+func markUpdatedArticles(articles, previous []Article) {
+	prevByLink := make(map[string]Article, len(previous))
+	for _, p := range previous {
+		prevByLink[p.Link] = p
+	}
+
+	for i := range articles {
+		a := &articles[i]
+		prev, ok := prevByLink[a.Link]
+		if !ok {
+			continue
+		}
+
+		titleChanged := prev.Title != "" && prev.Title != a.Title
+		updatedChanged := prev.UpdatedAt != "" && a.UpdatedAt != "" && !sameUpdateTime(prev.UpdatedAt, a.UpdatedAt)
+
+		switch {
+		case titleChanged:
+			a.IsUpdated = true
+			a.PrevTitle = prev.Title
+		case updatedChanged:
+			a.IsUpdated = true
+			a.PrevTitle = prev.PrevTitle
+		case prev.IsUpdated:
+			// 保留之前的更新标记
+			a.IsUpdated = true
+			a.PrevTitle = prev.PrevTitle
+		}
+		// RSS 本次未提供更新时间，或只是时区、格式不同时，沿用旧值，避免 data.json 无意义地变化
+		if a.UpdatedAt == "" || sameUpdateTime(prev.UpdatedAt, a.UpdatedAt) {
+			a.UpdatedAt = prev.UpdatedAt
+		}
+		if published, ok := samePublishedDate(prev.Published, a.Published); ok {
+			a.Published = published
+		}
+	}
+}
+
+// sameUpdateTime 判断两个更新时间是否表示同一时刻
+//
+// Description:
+//
+//	按时刻而不是字符串比较：同一时间的 +08:00 与 Z 两种写法相同，相差不超过 updateTimeTolerance 的也视为相同；
+//	任一方无法解析时退回字符串比较
+func sameUpdateTime(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ta.Sub(tb).Abs() <= updateTimeTolerance
+}
+
+// samePublishedDate 判断两个发布日期是否只有写法或时区上的差异
+//
+// Description:
+//
+//	两者都能解析且相差不超过一天时视为同一日期，返回按统一格式（Jan 02, 2006）书写的旧日期
+func samePublishedDate(prev, current string) (string, bool) {
+	if prev == "" || current == "" || prev == current {
+		return "", false
+	}
+	prevNorm, prevTime, err := normalizeDate(prev)
+	if err != nil {
+		return "", false
+	}
+	_, curTime, err := normalizeDate(current)
+	if err != nil {
+		return "", false
+	}
+	if prevTime.Sub(curTime).Abs() > 24*time.Hour {
+		return "", false
+	}
+	return prevNorm, true
+}
+
+// newArticlesSince 返回 articles 中链接不在 previous 里的文章，即本次新增的文章
+func newArticlesSince(articles, previous []Article) []Article {
+	known := make(map[string]bool, len(previous))
+	for _, p := range previous {
+		known[p.Link] = true
+	}
+	var added []Article
+	for _, a := range articles {
+		if !known[a.Link] {
+			added = append(added, a)
+		}
+	}
+	return added
+}
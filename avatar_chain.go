@@ -0,0 +1,183 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: avatar_chain.go
+// Description: 按 AVATAR_ORDER 配置的顺序依次尝试各头像来源，普通博客与平台订阅（GitHub 等）共用同一条解析链
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+	"github.com/mmcdole/gofeed"
+)
+
+// avatarSources AVATAR_ORDER 中可用的头像来源
+//
+// Description:
+//
+//	map            : 头像映射（AVATAR_MAP_URL）中按域名指定的头像，不检查可用性
+//	feed           : RSS 自带的 <image>，平台订阅为平台提供的用户头像
+//	page           : 博客主页 <head> 中的 <link rel="icon">、og:image，平台订阅为用户主页的 og:image
+//	gravatar       : 按 RSS 中作者邮箱生成的 Gravatar 头像（没有对应头像时视为不可用）
+//	favicon-service: 第三方图标服务（AVATAR_FAVICON_SERVICE）按域名提供的图标
+//	favicon        : 博客域名下的 /favicon.ico
+//	default        : 停止尝试，使用默认头像（未写出时也会在最后使用默认头像）
+var avatarSources = []string{"map", "feed", "page", "gravatar", "favicon-service", "favicon", "default"}
+
+// validateAvatarOrder 校验 AVATAR_ORDER，来源不能重复，default 只能位于最后
+func validateAvatarOrder(order []string) error {
+	for i, src := range order {
+		if !slices.Contains(avatarSources, src) {
+			return fmt.Errorf("AVATAR_ORDER 中的头像来源无效: %s (可选: %s)", src, strings.Join(avatarSources, ", "))
+		}
+		if slices.Contains(order[:i], src) {
+			return fmt.Errorf("AVATAR_ORDER 中的头像来源重复: %s", src)
+		}
+		if src == "default" && i != len(order)-1 {
+			return fmt.Errorf("AVATAR_ORDER 中的 default 只能位于最后")
+		}
+	}
+	return nil
+}
+
+// avatarResolver 按 AVATAR_ORDER 解析订阅的头像
+type avatarResolver struct {
+	order          []string
+	faviconService string // 图标服务地址模板，{host} 替换为博客域名
+	mapper         *AvatarMapper
+	robots         *robotsChecker
+	checker        *avatarChecker
+}
+
+// newAvatarResolver 根据配置创建头像解析链
+func newAvatarResolver(cfg *Config, mapper *AvatarMapper, robots *robotsChecker, checker *avatarChecker) *avatarResolver {
+	return &avatarResolver{
+		order:          cfg.AvatarOrder,
+		faviconService: cfg.AvatarFaviconService,
+		mapper:         mapper,
+		robots:         robots,
+		checker:        checker,
+	}
+}
+
+// mapped 头像映射位于解析链首位时返回映射中的头像
+//
+// Description:
+//
+//	映射是人工指定的，位于首位时应在读取头像缓存之前使用，修改映射后下一次运行即可生效；
+//	位于其他位置时随解析链一起缓存
+func (r *avatarResolver) mapped(rssLink string) (string, bool) {
+	if len(r.order) == 0 || r.order[0] != "map" {
+		return "", false
+	}
+	return r.mapper.GetAvatarByURL(rssLink)
+}
+
+// resolve 依次尝试各来源，返回第一个可访问的头像
+//
+// Parameters:
+//   - rssLink : 订阅地址，用于匹配头像映射
+//   - feed    : 解析后的订阅，主页地址取 feed.Link
+//   - platform: 平台订阅的规范化信息，普通博客为 nil
+//
+// Returns:
+//   - string : 头像地址；所有来源都没有头像时为空，找到过头像但都无法访问时为 "BROKEN"
+func (r *avatarResolver) resolve(ctx context.Context, rssLink string, feed *gofeed.Feed, platform *platformFeed) string {
+	tried := make(map[string]bool)
+	for _, src := range r.order {
+		if src == "default" {
+			break
+		}
+		if src == "map" {
+			if avatar, ok := r.mapper.GetAvatarByURL(rssLink); ok {
+				return avatar
+			}
+			continue
+		}
+		for _, candidate := range r.candidates(ctx, src, feed, platform) {
+			if candidate == "" || tried[candidate] {
+				continue
+			}
+			tried[candidate] = true
+			if r.checker.available(ctx, candidate) {
+				return candidate
+			}
+		}
+	}
+	if len(tried) > 0 {
+		return "BROKEN" // 找到过头像但都无法访问，暂记为BROKEN
+	}
+	return ""
+}
+
+// candidates 返回某个来源提供的候选头像地址，按优先级排列
+func (r *avatarResolver) candidates(ctx context.Context, src string, feed *gofeed.Feed, platform *platformFeed) []string {
+	switch src {
+	case "feed":
+		if platform != nil {
+			return []string{platform.Avatar}
+		}
+		if feed.Image != nil {
+			return []string{feed.Image.URL}
+		}
+	case "page":
+		if platform != nil {
+			// 平台主页的站点图标是平台自己的图标，只有 og:image 对应用户头像
+			if platform.AvatarPage == "" {
+				return nil
+			}
+			_, ogImage := fetchHeadImages(ctx, platform.AvatarPage, r.robots)
+			return []string{ogImage}
+		}
+		if feed.Link != "" {
+			iconHref, ogImage := fetchHeadImages(ctx, feed.Link, r.robots)
+			return []string{iconHref, ogImage}
+		}
+	case "gravatar":
+		if email := feedAuthorEmail(feed); email != "" {
+			return []string{gravatarURL(email)}
+		}
+	case "favicon-service":
+		// 平台订阅的域名是平台自己的，按域名获取的图标不代表订阅作者
+		if platform == nil && r.faviconService != "" {
+			if host := urlnorm.Host(feed.Link); host != "" {
+				return []string{strings.ReplaceAll(r.faviconService, "{host}", url.QueryEscape(host))}
+			}
+		}
+	case "favicon":
+		if platform == nil && feed.Link != "" {
+			return []string{fallbackFavicon(feed.Link)}
+		}
+	}
+	return nil
+}
+
+// feedAuthorEmail 返回 RSS 中第一个带邮箱的作者的邮箱
+func feedAuthorEmail(feed *gofeed.Feed) string {
+	if feed.Author != nil && feed.Author.Email != "" {
+		return feed.Author.Email
+	}
+	for _, author := range feed.Authors {
+		if author != nil && author.Email != "" {
+			return author.Email
+		}
+	}
+	return ""
+}
+
+// gravatarURL 按邮箱生成 Gravatar 头像地址
+//
+// Description:
+//
+//	邮箱去掉首尾空白并转为小写后取 SHA-256，d=404 使没有设置头像的邮箱返回 404，
+//	从而在可用性检查中失败并继续尝试下一个来源，而不是得到 Gravatar 的默认图案
+func gravatarURL(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return "https://www.gravatar.com/avatar/" + hex.EncodeToString(sum[:]) + "?s=128&d=404"
+}
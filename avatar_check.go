@@ -0,0 +1,107 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: avatar_check.go
+// Description: 头像可用性检查，检查方式（HEAD / GET 首字节 / 识别文件头）、超时和检查间隔均可配置，结果按头像地址缓存
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// avatarChecker 头像可用性检查器
+//
+// Description:
+//
+//	部分 CDN 拒绝 HEAD 请求，可改用只请求首字节的 GET（Range: bytes=0-0）；
+//	sniff 模式只读取文件开头的一段字节，按文件头确认是图片并检查尺寸（见 avatar_sniff.go）；
+//	状态码 2xx/3xx 视为可用；检查结果按头像地址缓存在抓取缓存中，检查间隔内不重复请求
+type avatarChecker struct {
+	method   string        // head | get | sniff | off
+	client   *http.Client  // 检查使用的 HTTP 客户端
+	interval time.Duration // 同一头像地址的检查间隔，<= 0 表示每次都检查
+	cache    *fetchCache   // 检查结果缓存，可为 nil
+
+	maxDimension int // sniff 模式下允许的最大宽高（像素），<= 0 表示不限制
+}
+
+// newAvatarChecker 根据配置创建头像可用性检查器
+func newAvatarChecker(cfg *Config, cache *fetchCache) *avatarChecker {
+	return &avatarChecker{
+		method:   cfg.AvatarCheckMethod,
+		client:   &http.Client{Timeout: time.Duration(cfg.AvatarCheckTimeout) * time.Second},
+		interval: time.Duration(cfg.AvatarCheckInterval) * time.Hour,
+		cache:    cache,
+
+		maxDimension: cfg.AvatarMaxDimension,
+	}
+}
+
+// available 检查头像地址是否可访问
+//
+// Description:
+//
+//	method 为 off 时不检查，直接视为可用；
+//	head 模式下服务器不支持 HEAD（405/501）或拒绝 HEAD（403）时，再用 GET 首字节重试一次；
+//	网络错误不写入缓存，避免临时故障在整个检查间隔内生效
+func (ac *avatarChecker) available(ctx context.Context, urlStr string) bool {
+	if ac.method == "off" {
+		return true
+	}
+	if lc, ok := ac.cache.avatarCheckResult(urlStr); ok && ac.interval > 0 && time.Since(lc.CheckedAt) < ac.interval {
+		return isAvailableStatus(lc.Status)
+	}
+
+	var status int
+	var err error
+	switch ac.method {
+	case "get":
+		status, err = ac.probe(ctx, urlStr, "GET")
+	case "sniff":
+		status, err = ac.sniff(ctx, urlStr)
+	default:
+		status, err = ac.probe(ctx, urlStr, "HEAD")
+		if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented || status == http.StatusForbidden) {
+			status, err = ac.probe(ctx, urlStr, "GET")
+		}
+	}
+	if err != nil {
+		return false
+	}
+	ac.cache.setAvatarCheckResult(urlStr, linkCheck{Status: status, CheckedAt: time.Now()})
+	return isAvailableStatus(status)
+}
+
+// probe 发送一次检查请求，GET 请求只要求首字节，不读取响应体
+func (ac *avatarChecker) probe(ctx context.Context, urlStr, method string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+	if method == "GET" {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	resp, err := ac.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// isAvailableStatus 2xx/3xx 视为可用
+func isAvailableStatus(status int) bool {
+	return status >= 200 && status < 400
+}
+
+// avatarCheckResult 读取头像地址的检查记录
+func (c *fetchCache) avatarCheckResult(urlStr string) (linkCheck, bool) {
+	return cacheLookup(c, func(c *fetchCache) *map[string]linkCheck { return &c.AvatarChecks }, urlStr)
+}
+
+// setAvatarCheckResult 保存头像地址的检查记录
+func (c *fetchCache) setAvatarCheckResult(urlStr string, lc linkCheck) {
+	cacheAssign(c, func(c *fetchCache) *map[string]linkCheck { return &c.AvatarChecks }, urlStr, lc)
+}
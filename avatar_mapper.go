@@ -1,13 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
-	"strings"
 	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
 )
 
 // AvatarMapping 表示头像映射的数据结构
@@ -27,19 +27,21 @@ type AvatarMapper struct {
     avatarMap map[string]string
     nameMap   map[string]string
     config    *Config
+    cache     *fetchCache
 }
 
-// NewAvatarMapper 创建新的头像映射器
-func NewAvatarMapper(config *Config) *AvatarMapper {
+// NewAvatarMapper 创建新的头像映射器，cache 为 nil 时不使用条件请求
+func NewAvatarMapper(config *Config, cache *fetchCache) *AvatarMapper {
     return &AvatarMapper{
         avatarMap: make(map[string]string),
         nameMap:   make(map[string]string),
         config:    config,
+        cache:     cache,
     }
 }
 
 // LoadAvatarMap 从远程URL加载头像映射数据
-func (am *AvatarMapper) LoadAvatarMap() error {
+func (am *AvatarMapper) LoadAvatarMap(ctx context.Context) error {
 	if am.config.AvatarMapURL == "" {
 		return fmt.Errorf("avatar map URL not configured")
 	}
@@ -49,22 +51,13 @@ func (am *AvatarMapper) LoadAvatarMap() error {
 		Timeout: 30 * time.Second,
 	}
 
-	// 发送GET请求
-	resp, err := client.Get(am.config.AvatarMapURL)
+	// 发送条件GET请求，未变化时复用缓存内容
+	body, notModified, err := am.cache.conditionalGet(ctx, client, am.config.AvatarMapURL)
 	if err != nil {
 		return fmt.Errorf("failed to fetch avatar map: %w", err)
 	}
-	defer resp.Body.Close()
-
-	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to fetch avatar map, status code: %d", resp.StatusCode)
-	}
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read avatar map response: %w", err)
+	if notModified {
+		fmt.Printf("[INFO] 头像映射未变化(304), 使用缓存\n")
 	}
 
 	// 解析JSON数据
@@ -94,29 +87,22 @@ func (am *AvatarMapper) LoadAvatarMap() error {
 
 // extractDomain 从URL中提取域名
 func (am *AvatarMapper) extractDomain(urlStr string) string {
-	// 如果URL不包含协议，添加http://前缀
-	if !strings.HasPrefix(urlStr, "http://") && !strings.HasPrefix(urlStr, "https://") {
-		urlStr = "http://" + urlStr
-	}
-
-	parsedURL, err := url.Parse(urlStr)
-	if err != nil {
-		return ""
-	}
-
-	// 返回主机名（域名）
-	return strings.ToLower(parsedURL.Host)
+	// 返回规范化的主机名（小写、punycode、去掉默认端口）
+	return urlnorm.Host(urlStr)
 }
 
 // GetAvatarByDomain 根据域名获取对应的头像URL
 func (am *AvatarMapper) GetAvatarByDomain(domain string) (string, bool) {
-    domain = strings.ToLower(domain)
+    domain = urlnorm.CanonicalHost(domain)
     avatar, exists := am.avatarMap[domain]
     return avatar, exists
 }
 
-// GetAvatarByURL 根据URL获取对应的头像URL
+// GetAvatarByURL 根据URL获取对应的头像URL，am 为 nil 时视为没有映射
 func (am *AvatarMapper) GetAvatarByURL(urlStr string) (string, bool) {
+    if am == nil {
+        return "", false
+    }
     domain := am.extractDomain(urlStr)
     if domain == "" {
         return "", false
@@ -129,7 +115,7 @@ func (am *AvatarMapper) GetAvatarByURL(urlStr string) (string, bool) {
 }
 
 func (am *AvatarMapper) GetNameByDomain(domain string) (string, bool) {
-    domain = strings.ToLower(domain)
+    domain = urlnorm.CanonicalHost(domain)
     name, exists := am.nameMap[domain]
     return name, exists
 }
@@ -0,0 +1,130 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: avatar_sniff.go
+// Description: 头像内容识别（AVATAR_CHECK_METHOD=sniff），只请求文件开头的一段字节，按文件头识别图片格式与尺寸，
+// 排除误填为视频、网页的头像以及尺寸过大的图片，不下载完整文件
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// avatarSniffBytes 识别头像时最多读取的字节数，足以覆盖常见格式的文件头（JPEG 的尺寸信息可能位于 EXIF 之后）
+const avatarSniffBytes = 64 << 10
+
+// 识别结果不可用时写入检查记录的状态码，与 HTTP 状态码一样按 isAvailableStatus 判断
+const (
+	statusNotImage      = http.StatusUnsupportedMediaType  // 内容不是图片（视频、网页等）
+	statusImageTooLarge = http.StatusRequestEntityTooLarge // 图片宽或高超过 AVATAR_MAX_DIMENSION
+)
+
+// sniff 以 Range 请求读取头像开头的 avatarSniffBytes 字节并识别内容
+//
+// Description:
+//
+//	服务器不支持 Range 而返回完整内容时，同样只读取前 avatarSniffBytes 字节后断开；
+//	识别出格式但无法从文件头得到尺寸时（如 SVG、尺寸信息不在开头的 JPEG）只检查格式
+func (ac *avatarChecker) sniff(ctx context.Context, urlStr string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", avatarSniffBytes-1))
+	resp, err := ac.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if !isAvailableStatus(resp.StatusCode) {
+		return resp.StatusCode, nil
+	}
+
+	head, err := io.ReadAll(io.LimitReader(resp.Body, avatarSniffBytes))
+	if err != nil && len(head) == 0 {
+		return 0, err
+	}
+	format, width, height := sniffImage(head)
+	if format == "" {
+		fmt.Printf("[WARN] 头像不是图片: %s (%s)\n", urlStr, http.DetectContentType(head))
+		return statusNotImage, nil
+	}
+	if ac.maxDimension > 0 && (width > ac.maxDimension || height > ac.maxDimension) {
+		fmt.Printf("[WARN] 头像尺寸过大: %s (%s %dx%d)\n", urlStr, format, width, height)
+		return statusImageTooLarge, nil
+	}
+	return resp.StatusCode, nil
+}
+
+// sniffImage 按文件头识别图片格式与尺寸
+//
+// Returns:
+//   - format : 图片格式（png、jpeg、gif、webp、ico、svg 等），不是图片时为空
+//   - width  : 宽度，无法从文件头得到时为 0
+//   - height : 高度，无法从文件头得到时为 0
+func sniffImage(data []byte) (format string, width, height int) {
+	if cfg, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		return format, cfg.Width, cfg.Height
+	}
+	if len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP" {
+		width, height = webpSize(data)
+		return "webp", width, height
+	}
+	if len(data) >= 8 && bytes.Equal(data[0:4], []byte{0, 0, 1, 0}) {
+		// ICO 取第一个图标的尺寸，0 表示 256
+		width, height = int(data[6]), int(data[7])
+		if width == 0 {
+			width = 256
+		}
+		if height == 0 {
+			height = 256
+		}
+		return "ico", width, height
+	}
+	contentType := http.DetectContentType(data)
+	// 网页中也可能内联 <svg> 图标，只有不是网页的文本才按 SVG 处理
+	if !strings.HasPrefix(contentType, "text/html") && strings.Contains(strings.ToLower(string(data[:min(len(data), 1024)])), "<svg") {
+		return "svg", 0, 0
+	}
+	// 尺寸信息不在开头的 JPEG 等其他图片格式只识别格式
+	if strings.HasPrefix(contentType, "image/") {
+		return strings.TrimPrefix(contentType, "image/"), 0, 0
+	}
+	return "", 0, 0
+}
+
+// webpSize 从 WebP 文件头读取尺寸，支持有损（VP8）、无损（VP8L）与扩展（VP8X）格式
+func webpSize(data []byte) (width, height int) {
+	if len(data) < 30 {
+		return 0, 0
+	}
+	switch string(data[12:16]) {
+	case "VP8X":
+		width = 1 + (int(data[24]) | int(data[25])<<8 | int(data[26])<<16)
+		height = 1 + (int(data[27]) | int(data[28])<<8 | int(data[29])<<16)
+	case "VP8L":
+		if data[20] != 0x2f {
+			return 0, 0
+		}
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		width = 1 + int(bits&0x3fff)
+		height = 1 + int(bits>>14&0x3fff)
+	case "VP8 ":
+		if !bytes.Equal(data[23:26], []byte{0x9d, 0x01, 0x2a}) {
+			return 0, 0
+		}
+		width = int(binary.LittleEndian.Uint16(data[26:28]) & 0x3fff)
+		height = int(binary.LittleEndian.Uint16(data[28:30]) & 0x3fff)
+	}
+	return width, height
+}
@@ -0,0 +1,103 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: backfill.go
+// Description: 新订阅首次加入时回填最近的多篇历史文章，而不仅是最新一篇
+
+package main
+
+import (
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// markKnownFeeds 记录本次抓取成功的订阅，返回此前从未抓取成功过的订阅
+//
+// Description:
+//
+//	只记录抓取成功的订阅（succeededFeeds 的结果），新订阅首次抓取失败时不记录，之后第一次成功时仍会回填；
+//	缓存中还没有任何记录时（首次运行或缓存丢失），只记录不返回，避免把所有订阅都当作新订阅回填
+func (c *fetchCache) markKnownFeeds(succeeded map[string]bool) map[string]bool {
+	newFeeds := make(map[string]bool)
+	if c == nil {
+		return newFeeds
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	firstRun := len(c.KnownFeeds) == 0
+	if c.KnownFeeds == nil {
+		c.KnownFeeds = make(map[string]time.Time)
+	}
+	for u := range succeeded {
+		if _, ok := c.KnownFeeds[u]; ok {
+			continue
+		}
+		c.KnownFeeds[u] = time.Now()
+		if !firstRun {
+			newFeeds[u] = true
+		}
+	}
+	return newFeeds
+}
+
+// backfillHistory 为首次加入且配置了 backfill=N 的订阅生成第 2~N 篇文章
+//
+// Description:
+//
+//	历史文章沿用最新文章的博客名称、头像、分组等信息（已完成映射替换），
+//	只包含抓取成功的订阅；最新一篇文章仍由常规流程输出
+func backfillHistory(results []feedResult, entries []feedEntry, newFeeds map[string]bool) []timedArticle {
+	backfill := make(map[string]int)
+	for _, e := range entries {
+		if e.Backfill > 1 && newFeeds[e.URL] {
+			backfill[e.URL] = e.Backfill
+		}
+	}
+
+	var history []timedArticle
+	for _, r := range results {
+		n := backfill[r.FeedLink]
+		if n == 0 || r.Err != nil || r.Article == nil || r.Feed == nil {
+			continue
+		}
+		for i := 1; i < n && i < len(r.Feed.Items); i++ {
+			item := r.Feed.Items[i]
+			a := *r.Article
+			a.Title = item.Title
+			a.Link = item.Link
+			applyItemExtensions(&a, item)
+			guardArticleFields(&a, r.Homepage)
+			a.UpdatedAt = ""
+			if item.UpdatedParsed != nil {
+				a.UpdatedAt = item.UpdatedParsed.Format(time.RFC3339)
+			}
+			a.Summary, a.Topic, a.TranslatedTitle = "", "", ""
+			a.content = htmlToText(itemBody(item), 1500)
+			t := itemPublished(item)
+			a.Published = t.Format("Jan 02, 2006")
+			history = append(history, timedArticle{a, t})
+		}
+	}
+	return history
+}
+
+// itemPublished 解析文章发布时间，无法解析时返回当前时间
+func itemPublished(item *gofeed.Item) time.Time {
+	if item.PublishedParsed != nil {
+		return *item.PublishedParsed
+	}
+	if item.Published != "" {
+		if t, err := parseTime(item.Published); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
+
+// itemBody 返回文章的描述，没有描述时返回正文
+func itemBody(item *gofeed.Item) string {
+	if item.Description != "" {
+		return item.Description
+	}
+	return item.Content
+}
@@ -0,0 +1,24 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: backfill_test.go
+// Description: markKnownFeeds 的测试，首次抓取失败的新订阅在之后第一次成功时仍视为新订阅
+
+package main
+
+import "testing"
+
+func TestMarkKnownFeedsSkipsFailedFeeds(t *testing.T) {
+	c := &fetchCache{}
+	if got := c.markKnownFeeds(map[string]bool{"https://old.example/feed": true}); len(got) != 0 {
+		t.Fatalf("首次运行返回了新订阅: %v", got)
+	}
+
+	// 新订阅 down.example 本次抓取失败，不在 succeeded 中
+	if got := c.markKnownFeeds(map[string]bool{"https://old.example/feed": true, "https://new.example/feed": true}); !got["https://new.example/feed"] || len(got) != 1 {
+		t.Fatalf("markKnownFeeds() = %v, want 只有 new.example", got)
+	}
+
+	got := c.markKnownFeeds(map[string]bool{"https://old.example/feed": true, "https://down.example/feed": true})
+	if !got["https://down.example/feed"] || len(got) != 1 {
+		t.Fatalf("首次失败的订阅恢复后 markKnownFeeds() = %v, want 只有 down.example", got)
+	}
+}
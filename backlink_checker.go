@@ -0,0 +1,186 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: backlink_checker.go
+// Description: 友链互链检查，抓取每位朋友的主页/友链页，确认是否仍然链接回本站
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html"
+)
+
+// defaultLinkPages 常见的友链页面路径
+var defaultLinkPages = []string{"/", "/links", "/links.html", "/link", "/link.html", "/friends", "/friends.html", "/friend", "/blogroll"}
+
+// backlinkResult 单个博客的互链检查结果
+type backlinkResult struct {
+	FeedLink string // RSS 地址
+	Homepage string // 博客主页
+	FoundOn  string // 找到回链的页面，为空表示未找到
+	Err      error  // 检查过程中的错误（主页无法访问等）
+}
+
+// runBacklinksCommand 友链互链检查子命令
+//
+// Description:
+//
+//	读取 RSS 列表，解析每个 RSS 得到博客主页，依次访问主页及常见的友链页面，
+//	查找指向 -site 域名的链接，最后输出缺失回链的博客报告
+func runBacklinksCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("backlinks", flag.ContinueOnError)
+	site := fs.String("site", os.Getenv("SITE_URL"), "本站地址或域名，如 lhasa.icu (默认读取 SITE_URL)")
+	pages := fs.String("pages", strings.Join(defaultLinkPages, ","), "要检查的页面路径，以逗号分隔")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	siteHost := urlnorm.SiteHost(*site)
+	if siteHost == "" {
+		fmt.Fprintln(os.Stderr, "[ERROR] 请通过 -site 或 SITE_URL 指定本站域名")
+		return 2
+	}
+
+	cfg := LoadConfig()
+	rssLinks, err := fetchRSSLinks(ctx, cfg, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] 拉取RSS链接失败: %v\n", err)
+		return 1
+	}
+
+	results := checkBacklinks(ctx, feedURLs(rssLinks), siteHost, splitList(*pages, ","))
+	fmt.Print(summarizeBacklinks(results, siteHost))
+	return 0
+}
+
+// checkBacklinks 并发检查所有博客是否链接回本站
+func checkBacklinks(ctx context.Context, rssLinks []string, siteHost string, pages []string) []backlinkResult {
+	client := &http.Client{Timeout: 15 * time.Second}
+	sem := make(chan struct{}, 10)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var results []backlinkResult
+
+	for _, link := range rssLinks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(rssLink string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			r := backlinkResult{FeedLink: rssLink, Homepage: feedHomepage(rssLink)}
+			for _, p := range pages {
+				pageURL := makeAbsoluteURL(r.Homepage, p)
+				found, err := pageLinksTo(ctx, client, pageURL, siteHost)
+				if err != nil && p == "/" {
+					// 主页都无法访问时，直接记录错误
+					r.Err = err
+					break
+				}
+				if found {
+					r.FoundOn = pageURL
+					break
+				}
+			}
+
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+		}(link)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Homepage < results[j].Homepage })
+	return results
+}
+
+// feedHomepage 解析 RSS 得到博客主页，失败时回退为 RSS 地址的站点根路径
+func feedHomepage(rssLink string) string {
+	if feed, err := fetchFeed(rssLink, gofeed.NewParser(), 15*time.Second, nil, false); err == nil && feed.Link != "" {
+		return feed.Link
+	}
+	return siteRoot(rssLink)
+}
+
+// pageLinksTo 检查页面中是否存在指向 siteHost 的 <a> 链接
+func pageLinksTo(ctx context.Context, client *http.Client, pageURL, siteHost string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("http error: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	// 最多读取 2MB，避免异常页面占用过多内存
+	z := html.NewTokenizer(io.LimitReader(resp.Body, 2<<20))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return false, nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "a" || !hasAttr {
+				continue
+			}
+			for {
+				key, val, more := z.TagAttr()
+				if string(key) == "href" {
+					h := urlnorm.SiteHost(string(val))
+					if h == siteHost || strings.HasSuffix(h, "."+siteHost) {
+						return true, nil
+					}
+				}
+				if !more {
+					break
+				}
+			}
+		}
+	}
+}
+
+// summarizeBacklinks 生成互链检查报告
+func summarizeBacklinks(results []backlinkResult, siteHost string) string {
+	var missing, unreachable []backlinkResult
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			unreachable = append(unreachable, r)
+		case r.FoundOn == "":
+			missing = append(missing, r)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("友链互链检查结果 (本站: %s):\n", siteHost))
+	sb.WriteString(fmt.Sprintf("共 %d 个博客, %d 个仍有回链.\n", len(results), len(results)-len(missing)-len(unreachable)))
+	if len(missing) > 0 {
+		sb.WriteString(fmt.Sprintf("✘ 有 %d 个博客未找到回链:\n", len(missing)))
+		for _, r := range missing {
+			sb.WriteString("  - " + r.Homepage + "\n")
+		}
+	}
+	if len(unreachable) > 0 {
+		sb.WriteString(fmt.Sprintf("✘ 有 %d 个博客主页无法访问:\n", len(unreachable)))
+		for _, r := range unreachable {
+			sb.WriteString(fmt.Sprintf("  - %s (%v)\n", r.Homepage, r.Err))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,363 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: backup.go
+// Description: backup / restore 子命令，将 data.json、RSS 列表、头像映射、抓取缓存与各类报告打包为一个 tar.gz，
+// 并可按当前配置写回，用于更换存储后端（如 GitHub 迁移到 COS）和灾难恢复
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// backupManifestName 快照中记录文件清单的文件名
+const backupManifestName = "manifest.json"
+
+// backupItem 快照中的一个文件，读写位置由当前配置决定
+type backupItem struct {
+	Name   string                                       // 快照中的文件名
+	Source string                                       // 当前配置下的位置，用于输出
+	Read   func(ctx context.Context) ([]byte, error)    // 读取内容，不存在时返回 nil, nil
+	Write  func(ctx context.Context, data []byte) error // 写回内容
+}
+
+// backupManifest 快照清单
+type backupManifest struct {
+	Version string               `json:"version"` // 生成快照的程序版本
+	Created string               `json:"created"` // 生成时间
+	Files   []backupManifestFile `json:"files"`
+}
+
+// backupManifestFile 快照清单中的一个文件
+type backupManifestFile struct {
+	Name   string `json:"name"`
+	Source string `json:"source"` // 备份时的位置
+	Size   int    `json:"size"`
+}
+
+// backupItems 按当前配置列出快照包含的文件
+//
+// Description:
+//
+//	data.json 及同目录下的 blogs.json、problems.json 等报告按 SAVE_TARGET 读写；
+//	RSS 列表、头像映射、名称映射与固定数据按地址读写（HTTP(S) 地址视为 COS 对象，其余视为 GitHub 仓库内路径）；
+//	抓取缓存按 CACHE_STORE 读写。未配置的项不包含在内，gist: 形式的 RSS 列表无法写回，同样跳过
+func backupItems(cfg *Config) ([]backupItem, error) {
+	var items []backupItem
+	stored := func(name, target string) {
+		items = append(items, backupItem{
+			Name:   name,
+			Source: target,
+			Read:   func(ctx context.Context) ([]byte, error) { return readStoredFile(ctx, cfg, target) },
+			Write: func(ctx context.Context, data []byte) error {
+				return saveStoredFile(ctx, cfg, target, data, "Restore "+name)
+			},
+		})
+	}
+	located := func(name, loc string) {
+		items = append(items, backupItem{
+			Name:   name,
+			Source: loc,
+			Read:   func(ctx context.Context) ([]byte, error) { return readBackupLocation(ctx, cfg, loc) },
+			Write: func(ctx context.Context, data []byte) error {
+				return writeBackupLocation(ctx, cfg, loc, data, "Restore "+name)
+			},
+		})
+	}
+
+	if cfg.DataURL != "" {
+		stored("data.json", cfg.DataURL)
+		for _, name := range []string{"blogs.json", "problems.json", "spotlight.json", "onthisday.json"} {
+			stored("reports/"+name, siblingPath(cfg.DataURL, name))
+		}
+	}
+	for i, src := range cfg.RssLists {
+		if strings.HasPrefix(src, "gist:") {
+			fmt.Printf("[WARN] 跳过 Gist 中的RSS列表: %s\n", src)
+			continue
+		}
+		located(fmt.Sprintf("lists/%d-%s", i+1, pathBase(src)), src)
+	}
+	if cfg.AvatarMapURL != "" {
+		located("avatar_map.json", cfg.AvatarMapURL)
+	}
+	if cfg.NameMappingURL != "" {
+		located("name_mapping.json", cfg.NameMappingURL)
+	}
+	if cfg.ForeverBlogURL != "" {
+		located("foreverblog.json", cfg.ForeverBlogURL)
+	}
+
+	store, err := newCacheStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if store != nil {
+		items = append(items, backupItem{
+			Name:   "fetch_cache.json",
+			Source: store.String(),
+			Read:   store.Load,
+			Write:  store.Save,
+		})
+	}
+	return items, nil
+}
+
+// pathBase 返回地址或路径的文件名部分
+func pathBase(loc string) string {
+	loc, _, _ = strings.Cut(loc, "?")
+	return loc[strings.LastIndex(loc, "/")+1:]
+}
+
+// readBackupLocation 读取 HTTP(S) 地址（COS 对象）或 GitHub 仓库内路径的文件，不存在时返回 nil, nil
+//
+// Description:
+//
+//	仓库内路径优先读取本地检出的文件，与 RSS_SOURCE=GITHUB 时读取 RSS 列表的方式一致
+func readBackupLocation(ctx context.Context, cfg *Config, loc string) ([]byte, error) {
+	if isRemoteURL(loc) {
+		return readCosObject(ctx, cfg.TencentSecretID, cfg.TencentSecretKey, loc, cfg.Retry)
+	}
+	if data, err := os.ReadFile(loc); err == nil {
+		return data, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if cfg.GitHubName == "" || cfg.GitHubRepo == "" {
+		return nil, nil
+	}
+	content, sha, err := getGitHubFileContent(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, loc)
+	if err != nil {
+		return nil, wrapErrorf(err, "从 GitHub 获取 %s 失败", loc)
+	}
+	if sha == "" {
+		return nil, nil
+	}
+	return []byte(content), nil
+}
+
+// writeBackupLocation 将文件写入 HTTP(S) 地址（COS 对象）或 GitHub 仓库内路径
+func writeBackupLocation(ctx context.Context, cfg *Config, loc string, data []byte, commitMsg string) error {
+	if isRemoteURL(loc) {
+		return uploadToCos(ctx, cfg.TencentSecretID, cfg.TencentSecretKey, loc, data, cosUploadOptions{
+			ContentType: storedContentType(loc),
+		})
+	}
+	sha, err := getGitHubFileSHA(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, loc)
+	if err != nil {
+		return wrapErrorf(err, "获取 %s 文件SHA失败", loc)
+	}
+	return putGitHubFile(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, loc, sha, string(data), commitMsg, cfg.commitSignature())
+}
+
+// writeBackupArchive 将文件打包为 tar.gz，清单写在最前面
+func writeBackupArchive(w io.Writer, manifest backupManifest, files map[string][]byte) error {
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	modTime := time.Now()
+
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: modTime}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add(backupManifestName, manifestData); err != nil {
+		return err
+	}
+	for _, f := range manifest.Files {
+		if err := add(f.Name, files[f.Name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// readBackupArchive 解包 tar.gz 快照，返回清单与各文件内容
+func readBackupArchive(data []byte) (backupManifest, map[string][]byte, error) {
+	var manifest backupManifest
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return manifest, nil, wrapErrorf(err, "快照不是 tar.gz 文件")
+	}
+	defer zr.Close()
+
+	files := make(map[string][]byte)
+	ar := tar.NewReader(zr)
+	for {
+		hdr, err := ar.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return manifest, nil, wrapErrorf(err, "读取快照失败")
+		}
+		content, err := io.ReadAll(ar)
+		if err != nil {
+			return manifest, nil, wrapErrorf(err, "读取快照失败")
+		}
+		files[hdr.Name] = content
+	}
+
+	raw, ok := files[backupManifestName]
+	if !ok {
+		return manifest, nil, fmt.Errorf("快照中缺少 %s", backupManifestName)
+	}
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return manifest, nil, wrapErrorf(err, "解析快照清单失败")
+	}
+	return manifest, files, nil
+}
+
+// runBackupCommand 快照子命令
+//
+// Description:
+//
+//	backup [-o 文件] [-upload 地址]
+//	按当前配置读取所有状态文件并打包为 tar.gz，默认保存为当前目录下的 lhasarss-backup-<时间>.tar.gz；
+//	-upload 同时上传到 COS 地址或 GitHub 仓库内路径。读取失败的文件跳过并告警，此时返回 1
+func runBackupCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	output := fs.String("o", "", "快照保存路径，默认 lhasarss-backup-<时间>.tar.gz")
+	upload := fs.String("upload", "", "同时上传快照到该位置（HTTP(S) 地址视为 COS 对象，其余视为 GitHub 仓库内路径）")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := LoadConfig()
+	items, err := backupItems(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 2
+	}
+
+	now := time.Now()
+	manifest := backupManifest{Version: versionString(), Created: now.Format(time.RFC3339), Files: []backupManifestFile{}}
+	files := make(map[string][]byte, len(items))
+	exitCode := 0
+	for _, item := range items {
+		data, err := item.Read(ctx)
+		switch {
+		case err != nil:
+			fmt.Printf("[WARN] 读取 %s 失败, 不包含在快照中: %v\n", item.Source, err)
+			exitCode = 1
+		case data == nil:
+			fmt.Printf("[INFO] %s 不存在, 跳过\n", item.Source)
+		default:
+			files[item.Name] = data
+			manifest.Files = append(manifest.Files, backupManifestFile{Name: item.Name, Source: item.Source, Size: len(data)})
+			fmt.Printf("[INFO] %-28s %8d 字节  %s\n", item.Name, len(data), item.Source)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeBackupArchive(&buf, manifest, files); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] 打包快照失败: %v\n", err)
+		return 1
+	}
+	if *output == "" {
+		*output = "lhasarss-backup-" + now.Format("20060102-150405") + ".tar.gz"
+	}
+	if err := os.WriteFile(*output, buf.Bytes(), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] 保存快照失败: %v\n", err)
+		return 1
+	}
+	fmt.Printf("已保存快照 %s (%d 个文件, %d 字节)\n", *output, len(manifest.Files), buf.Len())
+
+	if *upload != "" {
+		if err := writeBackupLocation(ctx, cfg, *upload, buf.Bytes(), "Upload lhasaRSS backup"); err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] 上传快照失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("已上传快照到 %s\n", *upload)
+	}
+	return exitCode
+}
+
+// runRestoreCommand 恢复子命令
+//
+// Description:
+//
+//	restore [-dry-run] <快照文件或地址>
+//	将快照中的文件写回当前配置对应的位置（而不是备份时的位置），备份与恢复使用不同的
+//	SAVE_TARGET、CACHE_STORE 等配置即可完成存储后端迁移；当前配置中不存在的项跳过并告警
+func runRestoreCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "只列出将要写回的文件及位置，不实际写入")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: restore [-dry-run] <快照文件或地址>")
+		return 2
+	}
+	src := fs.Arg(0)
+
+	cfg := LoadConfig()
+	data, err := os.ReadFile(src)
+	if os.IsNotExist(err) {
+		data, err = readBackupLocation(ctx, cfg, src)
+		if err == nil && data == nil {
+			err = fmt.Errorf("快照不存在: %s", src)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] 读取快照失败: %v\n", err)
+		return 1
+	}
+	manifest, files, err := readBackupArchive(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 1
+	}
+	fmt.Printf("快照版本: %s, 生成时间: %s, 共 %d 个文件\n", manifest.Version, manifest.Created, len(manifest.Files))
+
+	items, err := backupItems(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 2
+	}
+	targets := make(map[string]backupItem, len(items))
+	for _, item := range items {
+		targets[item.Name] = item
+	}
+
+	exitCode := 0
+	for _, f := range manifest.Files {
+		item, ok := targets[f.Name]
+		if !ok {
+			fmt.Printf("[WARN] 当前配置中没有 %s 的位置, 跳过 (备份时位于 %s)\n", f.Name, f.Source)
+			continue
+		}
+		if *dryRun {
+			fmt.Printf("[DRY-RUN] %-28s => %s\n", f.Name, item.Source)
+			continue
+		}
+		if err := item.Write(ctx, files[f.Name]); err != nil {
+			fmt.Printf("[ERROR] 写回 %s 失败: %v\n", item.Source, err)
+			exitCode = 1
+			continue
+		}
+		fmt.Printf("[INFO] %-28s => %s\n", f.Name, item.Source)
+	}
+	return exitCode
+}
@@ -0,0 +1,69 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: badges.go
+// Description: 生成 shields.io endpoint 格式的徽章 JSON（订阅数、更新时间、失败数），可用于博客与 README 的动态徽章
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// shieldsBadge shields.io endpoint 徽章格式
+//
+// Description:
+//
+//	使用方式: https://img.shields.io/endpoint?url=<徽章 JSON 地址>
+//	参见 https://shields.io/badges/endpoint-badge
+type shieldsBadge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// badgeFile 待写入的徽章文件
+type badgeFile struct {
+	Name  string       // 文件名，与 data.json 位于同一目录
+	Badge shieldsBadge // 徽章内容
+}
+
+// statsBadges 生成订阅数与失败数徽章
+func statsBadges(total, successCount int) []badgeFile {
+	failures := total - successCount
+	failColor := "brightgreen"
+	switch {
+	case failures > total/10:
+		failColor = "red"
+	case failures > 0:
+		failColor = "yellow"
+	}
+	return []badgeFile{
+		{Name: "badge-feeds.json", Badge: shieldsBadge{SchemaVersion: 1, Label: "feeds", Message: fmt.Sprintf("%d", total), Color: "blue"}},
+		{Name: "badge-failures.json", Badge: shieldsBadge{SchemaVersion: 1, Label: "failures", Message: fmt.Sprintf("%d", failures), Color: failColor}},
+	}
+}
+
+// updatedBadge 生成数据更新时间徽章
+func updatedBadge(t time.Time) badgeFile {
+	return badgeFile{Name: "badge-updated.json", Badge: shieldsBadge{SchemaVersion: 1, Label: "updated", Message: t.Format("2006-01-02 15:04"), Color: "blue"}}
+}
+
+// badgeOutputs 将徽章转换为待上传的输出文件（与 data.json 位于同一目录），内容未变化的徽章不会重复上传
+func badgeOutputs(cfg *Config, badges []badgeFile) ([]outputFile, error) {
+	var files []outputFile
+	for _, b := range badges {
+		data, err := json.Marshal(b.Badge)
+		if err != nil {
+			return nil, wrapErrorf(err, "徽章序列化失败: %s", b.Name)
+		}
+		files = append(files, outputFile{
+			Target:        siblingPath(cfg.DataURL, b.Name),
+			Data:          data,
+			CommitMsg:     "Update " + b.Name,
+			SkipUnchanged: true,
+		})
+	}
+	return files, nil
+}
@@ -0,0 +1,82 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: bandwidth.go
+// Description: 统计每次运行抓取订阅时各域名（按可注册域名 eTLD+1 合并子域名）下载的数据量，在运行摘要中列出流量最多的域名，
+// 便于发现输出全文、单次响应数 MB 的订阅（可改用摘要订阅，或确认对方支持条件请求）
+
+package main
+
+import (
+	"io"
+	"sort"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// countingBody 统计已读取字节数的响应体
+type countingBody struct {
+	io.ReadCloser
+	n *int
+}
+
+// Read 读取并累加字节数
+func (b countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	*b.n += n
+	return n, err
+}
+
+// domainBandwidth 单个域名本次运行下载的数据量
+type domainBandwidth struct {
+	Host  string // 可注册域名（eTLD+1），如 blog.example.com 与 www.example.com 合并为 example.com
+	Bytes int    // 下载的字节数（含重试）
+	Feeds int    // 该域名下的订阅数
+}
+
+// bandwidthStats 本次运行抓取订阅的流量统计
+type bandwidthStats struct {
+	Total int               // 所有订阅的下载字节数
+	Top   []domainBandwidth // 流量最多的域名，按字节数从大到小排列
+}
+
+// collectBandwidth 按域名汇总抓取订阅下载的数据量，返回流量最多的 top 个域名（top <= 0 时不列出）
+//
+// Description:
+//
+//	同一站点的多个子域名通常由同一台服务器提供，按 urlnorm.RegistrableDomain 合并统计；
+//	github.io 等公共后缀下不同用户的站点互不合并；
+//	字节数为响应体的大小，包含失败重试的请求；条件请求命中（304）的订阅没有响应体，不计流量；
+//	Go 自动协商并解压的 gzip 响应按解压后的大小计算
+func collectBandwidth(results []feedResult, top int) bandwidthStats {
+	var st bandwidthStats
+	byHost := make(map[string]*domainBandwidth)
+	for _, r := range results {
+		if r.Bytes == 0 {
+			continue
+		}
+		st.Total += r.Bytes
+		host := urlnorm.RegistrableDomain(r.FeedLink)
+		d, ok := byHost[host]
+		if !ok {
+			d = &domainBandwidth{Host: host}
+			byHost[host] = d
+		}
+		d.Bytes += r.Bytes
+		d.Feeds++
+	}
+	if top <= 0 {
+		return st
+	}
+	for _, d := range byHost {
+		st.Top = append(st.Top, *d)
+	}
+	sort.Slice(st.Top, func(i, j int) bool {
+		if st.Top[i].Bytes != st.Top[j].Bytes {
+			return st.Top[i].Bytes > st.Top[j].Bytes
+		}
+		return st.Top[i].Host < st.Top[j].Host
+	})
+	if len(st.Top) > top {
+		st.Top = st.Top[:top]
+	}
+	return st
+}
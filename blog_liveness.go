@@ -0,0 +1,221 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: blog_liveness.go
+// Description: 生成 blogs.json（每个博客的订阅状态、备注、元数据与内容变化时间），
+//   开启 BLOG_LIVENESS 时独立于 RSS 抓取结果检查每个博客主页能否访问
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// blogsJSONEnabled 判断是否需要生成 blogs.json
+//
+// Description:
+//
+//	开启 BLOG_LIVENESS、BLOG_METADATA、BLOGS_JSON 或 RSS 列表中填写了备注（note=）时生成；
+//	订阅状态、文章数与 last_changed_at 随 blogs.json 一起输出，不需要检查主页
+func blogsJSONEnabled(cfg *Config, results []feedResult) bool {
+	if cfg.BlogLiveness || cfg.BlogMetadata || cfg.BlogsJSON {
+		return true
+	}
+	return slices.ContainsFunc(results, func(r feedResult) bool { return r.Note != "" })
+}
+
+// updateBlogsJSON 生成新的 blogs.json
+//
+// Description:
+//
+//  1. 从抓取结果中整理出每个博客的主页（RSS 抓取失败时回退为 RSS 地址的站点根路径）
+//  2. 读取上次的 blogs.json，继承博客名称，并比较订阅内容记录最近一次变化的时间
+//  3. 开启 BLOG_LIVENESS 时检查主页存活状态（见 updateBlogLiveness），否则沿用上次的存活记录
+//  4. 返回待上传的 blogs.json，由调用方与其他输出文件一起上传
+func updateBlogsJSON(ctx context.Context, cfg *Config, results []feedResult, problems map[string][]string) (outputFile, error) {
+	blogsPath := siblingPath(cfg.DataURL, "blogs.json")
+
+	previous := make(map[string]BlogStatus)
+	if raw, err := readStoredFile(ctx, cfg, blogsPath); err != nil {
+		fmt.Printf("[WARN] 读取旧 blogs.json 失败: %v\n", err)
+	} else if len(raw) > 0 {
+		var old BlogsData
+		if err := json.Unmarshal(raw, &old); err != nil {
+			fmt.Printf("[WARN] 解析旧 blogs.json 失败: %v\n", err)
+		}
+		for _, b := range old.Items {
+			previous[b.FeedLink] = b
+		}
+	}
+
+	blogs := make([]BlogStatus, 0, len(results))
+	for _, r := range results {
+		b := BlogStatus{FeedLink: r.FeedLink, Homepage: r.Homepage, FeedStatus: feedStatusOf(r), Posts: r.ItemCount, Note: r.Note}
+		if r.Article != nil {
+			b.Name = r.Article.BlogName
+		} else if r.Name != "" {
+			b.Name = r.Name
+		} else if r.Empty && r.Feed != nil && r.Feed.Title != "" {
+			b.Name = r.Feed.Title
+		} else if prev, ok := previous[r.FeedLink]; ok {
+			b.Name = prev.Name
+			if b.Homepage == "" {
+				b.Homepage = prev.Homepage
+			}
+		}
+		if b.Homepage == "" {
+			b.Homepage = siteRoot(r.FeedLink)
+		}
+		if cfg.BlogMetadata {
+			setBlogMetadata(&b, r, previous[r.FeedLink])
+		}
+		setFeedFreshness(&b, r, previous[r.FeedLink], time.Now())
+		blogs = append(blogs, b)
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	if cfg.BlogLiveness {
+		updateBlogLiveness(ctx, cfg, blogs, previous, now, problems)
+	} else {
+		for i := range blogs {
+			b, prev := &blogs[i], previous[blogs[i].FeedLink]
+			b.Alive, b.HTTPStatus, b.Error, b.CheckedAt = prev.Alive, prev.HTTPStatus, prev.Error, prev.CheckedAt
+			b.LastAliveAt, b.DeadRuns = prev.LastAliveAt, prev.DeadRuns
+		}
+	}
+
+	jsonBytes, err := marshalJSON(BlogsData{Items: blogs, Updated: now}, cfg.OutputIndent)
+	if err != nil {
+		return outputFile{}, wrapErrorf(err, "blogs.json 序列化失败")
+	}
+	return outputFile{Target: blogsPath, Data: jsonBytes, CommitMsg: "Update blogs.json", SkipUnchanged: true, Volatile: []string{"updated", "checked_at"}}, nil
+}
+
+// updateBlogLiveness 检查所有博客主页的存活状态，结果写回 blogs
+//
+// Description:
+//
+//	并发检查主页，继承上次的最近存活时间和连续失联次数，
+//	连续 LivenessDeadAfter 次无法访问的博客记入 problems["lostBlogs"]
+func updateBlogLiveness(ctx context.Context, cfg *Config, blogs []BlogStatus, previous map[string]BlogStatus, now string, problems map[string][]string) {
+	checkHomepages(ctx, blogs)
+
+	for i := range blogs {
+		b := &blogs[i]
+		b.CheckedAt = now
+		prev := previous[b.FeedLink]
+		if b.Alive {
+			// 同一天内保留上次记录的时间，避免 last_alive_at 每次运行都变化、每次都重新上传 blogs.json
+			b.LastAliveAt = now
+			if prev.Alive && len(prev.LastAliveAt) >= 10 && strings.HasPrefix(now, prev.LastAliveAt[:10]) {
+				b.LastAliveAt = prev.LastAliveAt
+			}
+			b.DeadRuns = 0
+			continue
+		}
+		b.LastAliveAt = prev.LastAliveAt
+		b.DeadRuns = prev.DeadRuns + 1
+		if b.DeadRuns >= cfg.LivenessDeadAfter {
+			problems["lostBlogs"] = append(problems["lostBlogs"],
+				trf("%s (连续 %d 次无法访问, 最近可访问: %s)", b.Homepage, b.DeadRuns, orDash(b.LastAliveAt)))
+		}
+	}
+}
+
+// setBlogMetadata 从本次解析的 RSS 中提取博客简介、语言和博客程序，抓取失败时沿用上次的值
+func setBlogMetadata(b *BlogStatus, r feedResult, prev BlogStatus) {
+	if r.Feed == nil || r.Err != nil {
+		b.Description, b.Language, b.Generator = prev.Description, prev.Language, prev.Generator
+		return
+	}
+	b.Description = htmlToText(r.Feed.Description, 200)
+	b.Language = strings.TrimSpace(r.Feed.Language)
+	b.Generator = strings.TrimSpace(r.Feed.Generator)
+}
+
+// feedStatusOf 返回单个订阅本次抓取状态的文字表示
+func feedStatusOf(r feedResult) string {
+	var statusErr *httpStatusError
+	var pageErr *htmlPageError
+	switch {
+	case r.Empty, errors.Is(r.Err, ErrEmptyFeed):
+		return "empty"
+	case errors.As(r.Err, &statusErr) && statusErr.authRequired(), errors.As(r.Err, &pageErr) && pageErr.LoginWall:
+		return "auth"
+	case r.Err != nil:
+		return "error"
+	default:
+		return "ok"
+	}
+}
+
+// checkHomepages 并发检查博客主页能否访问，结果写回 blogs
+//
+// Description:
+//
+//	优先使用 HEAD 请求，服务器不支持 HEAD（405/501）时改用 GET，状态码 2xx/3xx 视为存活
+func checkHomepages(ctx context.Context, blogs []BlogStatus) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	sem := make(chan struct{}, 10)
+	var wg sync.WaitGroup
+
+	for i := range blogs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(b *BlogStatus) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer recoverPanic(trf("检查博客主页 %s", b.Homepage), nil)
+
+			status, err := probeURL(ctx, client, b.Homepage, "HEAD")
+			if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+				status, err = probeURL(ctx, client, b.Homepage, "GET")
+			}
+			b.HTTPStatus = status
+			if err != nil {
+				b.Error = err.Error()
+			}
+			b.Alive = err == nil && status >= 200 && status < 400
+		}(&blogs[i])
+	}
+	wg.Wait()
+}
+
+// probeURL 发送一次请求并返回状态码，不读取响应体
+func probeURL(ctx context.Context, client *http.Client, urlStr, method string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// siteRoot 返回 URL 的站点根路径 "scheme://host/"
+func siteRoot(urlStr string) string {
+	u, err := url.Parse(urlStr)
+	if err != nil || u.Host == "" {
+		return urlStr
+	}
+	return fmt.Sprintf("%s://%s/", u.Scheme, u.Host)
+}
+
+// orDash 空字符串显示为 "-"
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
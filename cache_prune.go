@@ -0,0 +1,158 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: cache_prune.go
+// Description: 清理抓取缓存中已不再使用的记录（已删除的订阅、已移出 data.json 的文章、长期未刷新的检查结果），避免缓存无限增长；
+//   多个配置方案共用缓存时按所有方案仍在使用的记录清理
+
+package main
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// cacheRetention 按时间清理的记录（头像、头像检查、规范地址）超过该时长未刷新即删除；
+// 仍在使用的记录会按各自的检查间隔刷新，长期未刷新说明对应的文章或订阅已不存在
+const cacheRetention = 30 * 24 * time.Hour
+
+// pruneScope 一个配置方案上次运行时仍在使用的记录，多个方案共用抓取缓存时按所有方案的并集清理
+type pruneScope struct {
+	Feeds     []string  `json:"feeds"`             // RSS 列表中的订阅地址（含已归档的订阅）
+	Links     []string  `json:"links"`             // data.json 中的文章链接
+	Entries   []string  `json:"entries,omitempty"` // 读取过的远程文件地址（Entries 的键）
+	UpdatedAt time.Time `json:"refreshed_at"`      // 记录时间，超过 cacheRetention 未更新的方案视为已删除
+}
+
+// prune 清理缓存中已不再使用的记录
+//
+// Description:
+//
+//	先用本次运行的订阅、文章与读取过的远程文件更新 scope（配置方案名称，未使用方案时为空）的记录，
+//	再以所有方案记录的并集为准清理，多个方案（PROFILES）共用 FETCH_CACHE 时不会删除其他方案的记录：
+//	按键清理：KnownFeeds、FeedProfiles、Quarantine 只保留 RSS 列表中的订阅；LinkChecks、Enrichments 只保留 data.json 中的文章；
+//	Entries 只保留读取过的远程文件（RSS 列表、头像映射等）
+//	按时间清理：Avatars（键可能是平台订阅的缓存键，无法与订阅对应）、AvatarChecks、CanonicalLinks（键为替换前的文章链接）、LatencyProbes
+//	超过 cacheRetention 未刷新的记录
+//	方案首次记录时只记录不清理；只应在 RSS 列表加载成功后调用，否则列表为空会清掉该方案所有订阅的记录
+//
+// Parameters:
+//   - scope    : 配置方案名称（activeProfile）
+//   - listed   : RSS 列表中的订阅地址（含已归档的订阅）
+//   - articles : 本次将要发布的全部文章
+//   - now      : 当前时间
+func (c *fetchCache) prune(scope string, listed map[string]bool, articles []Article, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := pruneScope{Feeds: slices.Sorted(maps.Keys(listed)), Entries: slices.Sorted(maps.Keys(c.used)), UpdatedAt: now}
+	for _, a := range articles {
+		current.Links = append(current.Links, a.Link)
+	}
+	slices.Sort(current.Links)
+	current.Links = slices.Compact(current.Links)
+	if c.PruneScopes == nil {
+		c.PruneScopes = make(map[string]pruneScope)
+	}
+	_, seen := c.PruneScopes[scope]
+	c.PruneScopes[scope] = current
+	if !seen {
+		// 方案首次记录时其他方案可能尚未记录，本次只记录不清理，避免删除其他方案的记录
+		return
+	}
+
+	feeds, links, entries := make(map[string]bool), make(map[string]bool), make(map[string]bool)
+	for name, sc := range c.PruneScopes {
+		if now.Sub(sc.UpdatedAt) > cacheRetention {
+			delete(c.PruneScopes, name)
+			continue
+		}
+		for _, u := range sc.Feeds {
+			feeds[u] = true
+		}
+		for _, l := range sc.Links {
+			links[l] = true
+		}
+		for _, u := range sc.Entries {
+			entries[u] = true
+		}
+	}
+	feedKeys := make(map[string]bool, len(feeds))
+	for u := range feeds {
+		feedKeys[urlnorm.Key(u)] = true
+	}
+
+	for u := range c.KnownFeeds {
+		if !feeds[u] {
+			delete(c.KnownFeeds, u)
+		}
+	}
+	for u := range c.FeedProfiles {
+		if !feeds[u] {
+			delete(c.FeedProfiles, u)
+		}
+	}
+	for u := range c.Quarantine {
+		if !feeds[u] {
+			delete(c.Quarantine, u)
+		}
+	}
+	for link := range c.LinkChecks {
+		if !links[link] {
+			delete(c.LinkChecks, link)
+		}
+	}
+	for key := range c.Enrichments {
+		if !enrichmentInUse(key, links) {
+			delete(c.Enrichments, key)
+		}
+	}
+	for u := range c.Entries {
+		if !entries[u] {
+			delete(c.Entries, u)
+		}
+	}
+	for key, rec := range c.Avatars {
+		if !feedKeys[key] && now.Sub(rec.CheckedAt) > cacheRetention {
+			delete(c.Avatars, key)
+		}
+	}
+	for u, lc := range c.AvatarChecks {
+		if now.Sub(lc.CheckedAt) > cacheRetention {
+			delete(c.AvatarChecks, u)
+		}
+	}
+	for link, rec := range c.CanonicalLinks {
+		if now.Sub(rec.CheckedAt) > cacheRetention {
+			delete(c.CanonicalLinks, link)
+		}
+	}
+	for host, at := range c.LatencyProbes {
+		if now.Sub(at) > cacheRetention {
+			delete(c.LatencyProbes, host)
+		}
+	}
+}
+
+// enrichmentInUse 判断增强内容的键（"类型:文章链接"，标题翻译为 "类型:文章链接#标题"）是否对应仍在发布的文章
+func enrichmentInUse(key string, links map[string]bool) bool {
+	_, rest, ok := strings.Cut(key, ":")
+	if !ok {
+		return false
+	}
+	if links[rest] {
+		return true
+	}
+	// 文章链接本身也可能包含 #，逐个尝试
+	for i := range len(rest) {
+		if rest[i] == '#' && links[rest[:i]] {
+			return true
+		}
+	}
+	return false
+}
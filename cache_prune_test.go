@@ -0,0 +1,118 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: cache_prune_test.go
+// Description: 抓取缓存清理与 GitHub 后端提交频率限制的测试
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+func TestFetchCachePrune(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-2 * cacheRetention)
+	c := &fetchCache{
+		Entries:        map[string]*cacheEntry{"https://x/rss.txt": {}, "https://x/old-mapping.json": {}},
+		KnownFeeds:     map[string]time.Time{"https://a/feed": old, "https://removed/feed": old},
+		FeedProfiles:   map[string]feedProfile{"https://a/feed": {}, "https://removed/feed": {}},
+		LinkChecks:     map[string]linkCheck{"https://a/post": {}, "https://a/gone": {}},
+		Enrichments:    map[string]string{"summary:https://a/post": "s", "translate-en:https://a/post#标题": "t", "summary:https://a/gone": "s"},
+		Avatars:        map[string]avatarRecord{urlnorm.Key("https://a/feed"): {CheckedAt: old}, "youtube:old": {CheckedAt: old}, "youtube:new": {CheckedAt: now}},
+		AvatarChecks:   map[string]linkCheck{"https://a/avatar.png": {CheckedAt: now}, "https://b/avatar.png": {CheckedAt: old}},
+		CanonicalLinks: map[string]canonicalRecord{"https://a/post?utm=1": {CheckedAt: now}, "https://a/gone": {CheckedAt: old}},
+		PruneScopes:    map[string]pruneScope{"": {UpdatedAt: now}},
+	}
+	c.get("https://x/rss.txt")
+	c.prune("", map[string]bool{"https://a/feed": true}, []Article{{Link: "https://a/post"}}, now)
+
+	checks := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"Entries", len(c.Entries), 1},
+		{"KnownFeeds", len(c.KnownFeeds), 1},
+		{"FeedProfiles", len(c.FeedProfiles), 1},
+		{"LinkChecks", len(c.LinkChecks), 1},
+		{"Enrichments", len(c.Enrichments), 2},
+		{"Avatars", len(c.Avatars), 2},
+		{"AvatarChecks", len(c.AvatarChecks), 1},
+		{"CanonicalLinks", len(c.CanonicalLinks), 1},
+	}
+	for _, ck := range checks {
+		if ck.got != ck.want {
+			t.Errorf("%s 剩余 %d 条, want %d", ck.name, ck.got, ck.want)
+		}
+	}
+	if _, ok := c.Avatars["youtube:old"]; ok {
+		t.Error("长期未刷新的平台订阅头像未被清理")
+	}
+}
+
+func TestFetchCachePruneKeepsOtherProfiles(t *testing.T) {
+	now := time.Now()
+	c := &fetchCache{
+		KnownFeeds: map[string]time.Time{"https://a/feed": now, "https://b/feed": now},
+		LinkChecks: map[string]linkCheck{"https://a/post": {}, "https://b/post": {}},
+	}
+	// 两次运行：第一次各方案只记录不清理，第二次按两个方案的并集清理
+	for range 2 {
+		c.prune("A", map[string]bool{"https://a/feed": true}, []Article{{Link: "https://a/post"}}, now)
+		c.prune("B", map[string]bool{"https://b/feed": true}, []Article{{Link: "https://b/post"}}, now)
+	}
+	if len(c.KnownFeeds) != 2 || len(c.LinkChecks) != 2 {
+		t.Fatalf("两个方案交替运行后 KnownFeeds = %v, LinkChecks = %v, want 各保留 2 条", c.KnownFeeds, c.LinkChecks)
+	}
+
+	// 方案 B 长期未运行（已从 PROFILES 中删除）后，其记录随之清理
+	c.prune("A", map[string]bool{"https://a/feed": true}, []Article{{Link: "https://a/post"}}, now.Add(2*cacheRetention))
+	if _, ok := c.KnownFeeds["https://b/feed"]; ok {
+		t.Error("已删除方案的订阅记录未被清理")
+	}
+}
+
+// countingStore 记录保存次数的 cacheStore
+type countingStore struct {
+	interval time.Duration
+	saves    int
+}
+
+func (s *countingStore) Load(ctx context.Context) ([]byte, error)    { return nil, nil }
+func (s *countingStore) Save(ctx context.Context, data []byte) error { s.saves++; return nil }
+func (s *countingStore) String() string                              { return "counting" }
+func (s *countingStore) saveInterval() time.Duration                 { return s.interval }
+
+func TestFetchCacheSaveInterval(t *testing.T) {
+	ctx := context.Background()
+	store := &countingStore{interval: time.Hour}
+	c := loadFetchCache(ctx, store)
+	c.setFeedProfile("https://a/feed", feedProfile{Title: "A"})
+	c.setAvatar("https://a/feed", "https://a/avatar.png")
+	if err := c.save(ctx); err != nil || store.saves != 1 {
+		t.Fatalf("首次保存: saves = %d, err = %v, want 1, nil", store.saves, err)
+	}
+
+	// 间隔内只有抓取耗时、检查时间变化：不提交
+	c.Latencies = map[string][]int{"a": {120}}
+	c.setAvatar("https://a/feed", "https://a/avatar.png")
+	if err := c.save(ctx); err != nil || store.saves != 1 {
+		t.Fatalf("只有易变字段变化: saves = %d, err = %v, want 1, nil", store.saves, err)
+	}
+
+	// 其他内容变化：立即提交
+	c.setFeedProfile("https://b/feed", feedProfile{Title: "B"})
+	if err := c.save(ctx); err != nil || store.saves != 2 {
+		t.Fatalf("内容变化: saves = %d, err = %v, want 2, nil", store.saves, err)
+	}
+
+	// 超过间隔后易变字段的变化也会提交
+	c.SavedAt = time.Now().Add(-2 * time.Hour)
+	c.Latencies["a"] = append(c.Latencies["a"], 80)
+	if err := c.save(ctx); err != nil || store.saves != 3 {
+		t.Fatalf("超过间隔: saves = %d, err = %v, want 3, nil", store.saves, err)
+	}
+}
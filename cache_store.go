@@ -0,0 +1,152 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: cache_store.go
+// Description: 跨运行状态（抓取缓存）的存储后端：本地文件、COS 对象或 GitHub 仓库文件
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// cacheStore 跨运行状态的存储后端
+//
+// Description:
+//
+//	ETag 缓存、头像缓存、订阅特征、订阅健康事件、隔离列表、链接检测记录等跨运行状态都保存在 fetchCache 中，
+//	由同一个 cacheStore 整体读写，更换后端时无需改动各功能的代码
+//	整个缓存是一个 JSON 文档，没有提供 bolt/SQLite 等按键读写的后端：缓存每次运行整体加载、整体写回，
+//	体积由 fetchCache.prune 控制
+type cacheStore interface {
+	// Load 读取已保存的内容，尚未保存过时返回 nil, nil
+	Load(ctx context.Context) ([]byte, error)
+	// Save 覆盖保存内容
+	Save(ctx context.Context, data []byte) error
+	// String 返回便于日志输出的存储位置
+	String() string
+}
+
+// newCacheStore 根据 CACHE_STORE 创建存储后端
+//
+// Description:
+//
+//	file（默认）: FETCH_CACHE 为本地路径，需配合 actions/cache 等方式在运行之间保留
+//	cos         : FETCH_CACHE 为 COS 对象地址，使用腾讯云凭证读写（对象可为私有）
+//	github      : FETCH_CACHE 为仓库内路径，内容变化时产生一次提交；只有检查时间、抓取耗时变化时
+//	              最多每 CACHE_COMMIT_INTERVAL 小时提交一次
+//	FETCH_CACHE 为空时返回 nil，即不持久化缓存
+func newCacheStore(cfg *Config) (cacheStore, error) {
+	if cfg.FetchCachePath == "" {
+		return nil, nil
+	}
+	switch cfg.CacheStore {
+	case "file":
+		return fileCacheStore{path: cfg.FetchCachePath}, nil
+	case "cos":
+		if !isRemoteURL(cfg.FetchCachePath) {
+			return nil, fmt.Errorf("CACHE_STORE=cos 时 FETCH_CACHE 必须是 COS 对象地址: %s", cfg.FetchCachePath)
+		}
+		return cosCacheStore{secretID: cfg.TencentSecretID, secretKey: cfg.TencentSecretKey, url: cfg.FetchCachePath}, nil
+	case "github":
+		return githubCacheStore{cfg: cfg, path: repoPath(cfg.FetchCachePath), interval: time.Duration(cfg.CacheCommitInterval) * time.Hour}, nil
+	default:
+		return nil, fmt.Errorf("CACHE_STORE 值无效: %s (只能是 file、cos 或 github)", cfg.CacheStore)
+	}
+}
+
+// fileCacheStore 本地文件
+type fileCacheStore struct {
+	path string
+}
+
+func (s fileCacheStore) Load(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s fileCacheStore) Save(ctx context.Context, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return wrapErrorf(err, "创建缓存目录失败: %s", s.path)
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
+
+func (s fileCacheStore) String() string {
+	return s.path
+}
+
+// cosCacheStore COS 对象，通过 SDK 带签名读写，不依赖对象的公共读权限
+type cosCacheStore struct {
+	secretID  string
+	secretKey string
+	url       string
+}
+
+func (s cosCacheStore) Load(ctx context.Context) ([]byte, error) {
+	client, key, err := newCosClient(s.secretID, s.secretKey, s.url)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Object.Get(ctx, key, nil)
+	if err != nil {
+		if cos.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (s cosCacheStore) Save(ctx context.Context, data []byte) error {
+	return uploadToCos(ctx, s.secretID, s.secretKey, s.url, data, cosUploadOptions{
+		ContentType:  "application/json; charset=utf-8",
+		CacheControl: "no-cache",
+	})
+}
+
+func (s cosCacheStore) String() string {
+	return s.url
+}
+
+// githubCacheStore GitHub 仓库中的文件
+type githubCacheStore struct {
+	cfg      *Config
+	path     string
+	interval time.Duration // 只有易变字段变化时两次提交的最短间隔
+}
+
+// saveInterval 每次保存都是一次提交，只有易变字段变化时按 CACHE_COMMIT_INTERVAL 限制提交频率
+func (s githubCacheStore) saveInterval() time.Duration {
+	return s.interval
+}
+
+func (s githubCacheStore) Load(ctx context.Context) ([]byte, error) {
+	content, sha, err := getGitHubFileContent(ctx, s.cfg.GitHubToken, s.cfg.GitHubName, s.cfg.GitHubRepo, s.path)
+	if err != nil || sha == "" {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+func (s githubCacheStore) Save(ctx context.Context, data []byte) error {
+	sha, err := getGitHubFileSHA(ctx, s.cfg.GitHubToken, s.cfg.GitHubName, s.cfg.GitHubRepo, s.path)
+	if err != nil {
+		return err
+	}
+	return putGitHubFile(ctx, s.cfg.GitHubToken, s.cfg.GitHubName, s.cfg.GitHubRepo, s.path, sha,
+		string(data), "Update fetch cache", s.cfg.commitSignature())
+}
+
+func (s githubCacheStore) String() string {
+	return fmt.Sprintf("github:%s/%s/%s", s.cfg.GitHubName, s.cfg.GitHubRepo, s.path)
+}
@@ -0,0 +1,177 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: canonical.go
+// Description: 通过文章页面的 rel=canonical 解析规范地址，使转载文章与镜像域名的文章合并为作者的原始地址
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+	"golang.org/x/net/html"
+)
+
+// canonicalRecord 单个文章链接的规范地址解析结果，保存在抓取缓存中
+type canonicalRecord struct {
+	URL       string    `json:"url,omitempty"` // 规范地址，页面未声明时为空
+	CheckedAt time.Time `json:"checked_at"`    // 解析时间
+}
+
+// resolveCanonicalLinks 将文章链接替换为页面声明的规范地址，并合并规范地址相同的文章
+//
+// Description:
+//
+//	每个链接在 interval 时间内只请求一次，结果保存在抓取缓存中；请求失败或 robots.txt 禁止抓取（RESPECT_ROBOTS）的链接保持不变；
+//	规范地址在解析时与写入前都会校验，只接受 http(s) 地址（与 guardArticleFields 一致），页面不能借此写入 javascript:、data: 等链接；
+//	规范地址相同的多篇文章只保留一篇：优先保留原链接即为规范地址的那篇（作者本人的站点），否则保留先出现的
+func resolveCanonicalLinks(ctx context.Context, items []timedArticle, interval time.Duration, cache *fetchCache, robots *robotsChecker) []timedArticle {
+	sem := make(chan struct{}, 10)
+	var wg sync.WaitGroup
+	for _, it := range items {
+		if rec, ok := cache.canonicalResult(it.article.Link); ok && time.Since(rec.CheckedAt) < interval {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(link string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer recoverPanic(trf("解析规范地址 %s", link), nil)
+			if !robots.allowed(ctx, link) {
+				return
+			}
+			canonical, err := fetchCanonicalURL(ctx, link)
+			if err != nil {
+				return
+			}
+			cache.setCanonicalResult(link, canonicalRecord{URL: canonical, CheckedAt: time.Now()})
+		}(it.article.Link)
+	}
+	wg.Wait()
+
+	type candidate struct {
+		index int  // 在结果中的位置
+		own   bool // 原链接即为规范地址
+	}
+	var result []timedArticle
+	byKey := make(map[string]candidate)
+	for _, it := range items {
+		own := true
+		if rec, ok := cache.canonicalResult(it.article.Link); ok && isSafeCanonical(rec.URL) {
+			own = urlnorm.Normalize(rec.URL) == urlnorm.Normalize(it.article.Link)
+			it.article.Link = rec.URL
+		}
+		key := urlnorm.Normalize(it.article.Link)
+		prev, dup := byKey[key]
+		switch {
+		case !dup:
+			byKey[key] = candidate{index: len(result), own: own}
+			result = append(result, it)
+		case own && !prev.own:
+			result[prev.index] = it
+			byKey[key] = candidate{index: prev.index, own: true}
+		}
+	}
+	return result
+}
+
+// isSafeCanonical 判断规范地址能否写入 data.json：只接受带主机名且不超过 maxLinkLength 的 http(s) 地址
+func isSafeCanonical(raw string) bool {
+	if raw == "" || len(raw) > maxLinkLength {
+		return false
+	}
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// fetchCanonicalURL 获取页面声明的规范地址
+//
+// Description:
+//
+//	优先使用响应头 Link: <...>; rel="canonical"，其次流式解析 <head> 中的 <link rel="canonical">，
+//	读到 </head> 即停止；页面未声明或声明的不是 http(s) 地址时返回空字符串
+func fetchCanonicalURL(ctx context.Context, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+	resp, err := pageClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil
+	}
+
+	for _, h := range resp.Header.Values("Link") {
+		for _, part := range strings.Split(h, ",") {
+			target, params, ok := strings.Cut(part, ";")
+			params = strings.ToLower(params)
+			if ok && (strings.Contains(params, `rel="canonical"`) || strings.Contains(params, "rel=canonical")) {
+				return safeCanonical(makeAbsoluteURL(resp.Request.URL.String(), strings.Trim(strings.TrimSpace(target), "<>"))), nil
+			}
+		}
+	}
+
+	z := html.NewTokenizer(io.LimitReader(resp.Body, logoPageMaxBytes))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return "", nil
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "head" {
+				return "", nil
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) == "body" {
+				return "", nil
+			}
+			if string(name) != "link" || !hasAttr {
+				continue
+			}
+			var rel, href string
+			for {
+				key, val, more := z.TagAttr()
+				switch strings.ToLower(string(key)) {
+				case "rel":
+					rel = strings.ToLower(string(val))
+				case "href":
+					href = strings.TrimSpace(string(val))
+				}
+				if !more {
+					break
+				}
+			}
+			if rel == "canonical" && href != "" {
+				return safeCanonical(makeAbsoluteURL(resp.Request.URL.String(), href)), nil
+			}
+		}
+	}
+}
+
+// safeCanonical 不能写入 data.json 的规范地址按未声明处理
+func safeCanonical(raw string) string {
+	if !isSafeCanonical(raw) {
+		return ""
+	}
+	return raw
+}
+
+// canonicalResult 读取文章链接的规范地址解析结果
+func (c *fetchCache) canonicalResult(link string) (canonicalRecord, bool) {
+	return cacheLookup(c, func(c *fetchCache) *map[string]canonicalRecord { return &c.CanonicalLinks }, link)
+}
+
+// setCanonicalResult 保存文章链接的规范地址解析结果
+func (c *fetchCache) setCanonicalResult(link string, rec canonicalRecord) {
+	cacheAssign(c, func(c *fetchCache) *map[string]canonicalRecord { return &c.CanonicalLinks }, link, rec)
+}
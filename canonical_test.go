@@ -0,0 +1,38 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: canonical_test.go
+// Description: resolveCanonicalLinks 的测试，页面声明的非 http(s) 规范地址不能写入文章链接
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResolveCanonicalLinksRejectsUnsafeSchemes(t *testing.T) {
+	canonicals := map[string]string{
+		"/js":    "javascript:alert(1)",
+		"/data":  "data:text/html,<script>alert(1)</script>",
+		"/https": "https://origin.example/post",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<html><head><link rel="canonical" href="%s"></head><body></body></html>`, canonicals[r.URL.Path])
+	}))
+	defer srv.Close()
+
+	var items []timedArticle
+	for _, p := range []string{"/js", "/data", "/https"} {
+		items = append(items, timedArticle{article: Article{Link: srv.URL + p}})
+	}
+	got := resolveCanonicalLinks(context.Background(), items, time.Hour, &fetchCache{}, nil)
+	want := []string{srv.URL + "/js", srv.URL + "/data", "https://origin.example/post"}
+	for i, it := range got {
+		if it.article.Link != want[i] {
+			t.Errorf("items[%d].Link = %q, want %q", i, it.article.Link, want[i])
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: cert_expiry.go
+// Description: 根据抓取时记录的 HTTPS 证书到期时间，提醒即将过期的友链证书
+
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// checkCertExpiry 检查抓取结果中即将到期的 HTTPS 证书
+//
+// Description:
+//
+//	证书到期时间在抓取 RSS 时顺带记录，不会产生额外请求；
+//	剩余天数不超过 warnDays 的记入 problems["certExpiring"]，并返回同样的列表用于发送通知（按 RSS 地址去重）
+func checkCertExpiry(results []feedResult, warnDays int, problems map[string][]string) []notifyItem {
+	if warnDays <= 0 {
+		return nil
+	}
+	deadline := time.Now().AddDate(0, 0, warnDays)
+
+	var expiring []feedResult
+	for _, r := range results {
+		if r.CertExpiry.IsZero() || r.CertExpiry.After(deadline) {
+			continue
+		}
+		expiring = append(expiring, r)
+	}
+	sort.Slice(expiring, func(i, j int) bool { return expiring[i].CertExpiry.Before(expiring[j].CertExpiry) })
+
+	var lines []string
+	var items []notifyItem
+	for _, r := range expiring {
+		days := int(time.Until(r.CertExpiry).Hours() / 24)
+		var line string
+		if days < 0 {
+			line = trf("%s (证书已于 %s 过期)", r.FeedLink, r.CertExpiry.Format("2006-01-02"))
+		} else {
+			line = trf("%s (证书将于 %s 到期, 剩余 %d 天)", r.FeedLink, r.CertExpiry.Format("2006-01-02"), days)
+		}
+		lines = append(lines, line)
+		items = append(items, notifyItem{Key: "cert:" + r.FeedLink, Text: line})
+	}
+	problems["certExpiring"] = append(problems["certExpiring"], lines...)
+	return items
+}
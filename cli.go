@@ -0,0 +1,233 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: cli.go
+// Description: 命令行子命令的注册与分发，不带子命令运行时执行默认的抓取流程（可用 --only、--limit 只抓取部分订阅）
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// subcommand 表示一个命令行子命令
+type subcommand struct {
+	Usage string                                       // 简要说明
+	Run   func(ctx context.Context, args []string) int // 执行函数，返回进程退出码
+}
+
+// subcommands 所有已注册的子命令
+var subcommands = map[string]subcommand{
+	"backup": {
+		Usage: "将 data.json、RSS 列表、头像映射、抓取缓存与报告打包为一个 tar.gz 快照",
+		Run:   runBackupCommand,
+	},
+	"backlinks": {
+		Usage: "检查各博客的主页/友链页是否仍链接回本站",
+		Run:   runBacklinksCommand,
+	},
+	"diff": {
+		Usage: "比较两份 data.json（本地文件、COS 地址、仓库内路径或 remote），列出新增、删除、修改的文章与博客",
+		Run:   runDiffCommand,
+	},
+	"doctor": {
+		Usage: "诊断环境变量、Token 权限、COS 存储桶与 RSS 列表，并给出修复建议",
+		Run:   runDoctorCommand,
+	},
+	"import": {
+		Usage: "从 hexo-circle-of-friends 结果 JSON 或 FreshRSS/Miniflux 的 OPML 导入订阅与头像",
+		Run:   runImportCommand,
+	},
+	"restore": {
+		Usage: "将 backup 生成的快照写回当前配置的存储位置（迁移存储后端、灾难恢复）",
+		Run:   runRestoreCommand,
+	},
+	"forever": {
+		Usage: "管理固定数据 foreverblog.json (list/add/remove)",
+		Run:   runForeverCommand,
+	},
+}
+
+// printSubcommands 打印所有子命令的用法
+func printSubcommands() {
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "可用子命令:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, subcommands[name].Usage)
+	}
+}
+
+// runForeverCommand 固定数据管理子命令
+//
+// Description:
+//
+//	forever list                                   列出所有固定数据
+//	forever add -name -title -link -published ...  新增一条（链接已存在时覆盖）
+//	forever remove -link <url>                     按链接删除一条
+func runForeverCommand(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "用法: forever <list|add|remove> [参数]")
+		return 2
+	}
+
+	cfg := LoadConfig()
+	if cfg.ForeverBlogURL == "" {
+		fmt.Fprintln(os.Stderr, "[ERROR] 未配置 FOREVER_BLOG_URL")
+		return 1
+	}
+	data, err := loadForeverBlog(ctx, cfg, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] 读取固定数据失败: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "list":
+		for i, a := range data.Items {
+			fmt.Printf("%3d. [%s] %s - %s\n     %s\n", i+1, a.Published, a.BlogName, a.Title, a.Link)
+		}
+		fmt.Printf("共 %d 条固定数据\n", len(data.Items))
+		return 0
+
+	case "add":
+		fs := flag.NewFlagSet("forever add", flag.ContinueOnError)
+		var a Article
+		fs.StringVar(&a.BlogName, "name", "", "博客名称 (必填)")
+		fs.StringVar(&a.Title, "title", "", "文章标题 (必填)")
+		fs.StringVar(&a.Link, "link", "", "文章链接 (必填)")
+		fs.StringVar(&a.Published, "published", "", "发布时间，如 2025-06-01 (必填)")
+		fs.StringVar(&a.Avatar, "avatar", "", "头像地址")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if err := validateForeverEntry(&a); err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+			return 2
+		}
+		replaced := false
+		for i := range data.Items {
+			if data.Items[i].Link == a.Link {
+				data.Items[i] = a
+				replaced = true
+			}
+		}
+		if !replaced {
+			data.Items = append(data.Items, a)
+		}
+
+	case "remove":
+		fs := flag.NewFlagSet("forever remove", flag.ContinueOnError)
+		link := fs.String("link", "", "要删除的文章链接 (必填)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+		kept := data.Items[:0]
+		for _, a := range data.Items {
+			if a.Link != *link {
+				kept = append(kept, a)
+			}
+		}
+		if len(kept) == len(data.Items) {
+			fmt.Fprintf(os.Stderr, "[ERROR] 未找到链接: %s\n", *link)
+			return 1
+		}
+		data.Items = kept
+
+	default:
+		fmt.Fprintf(os.Stderr, "未知操作: %s\n", args[0])
+		return 2
+	}
+
+	if err := saveForeverBlog(ctx, cfg, data); err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] 保存固定数据失败: %v\n", err)
+		return 1
+	}
+	fmt.Printf("已保存, 共 %d 条固定数据\n", len(data.Items))
+	return 0
+}
+
+// runOptions 默认抓取流程的命令行参数
+type runOptions struct {
+	Only  *regexp.Regexp // 仅抓取 RSS 地址或名称匹配的订阅，为 nil 表示不过滤
+	Limit int            // 最多抓取的订阅数，<= 0 表示不限制
+
+	CacheDir string // 本地开发时缓存 GET 响应的目录，为空表示不缓存
+	DumpDir  string // 保存每个订阅原始响应、清理后 XML 与解析结果的目录，为空表示不保存
+
+	Version bool // 仅打印版本信息后退出
+	Force   bool // 文章数骤减时仍上传 data.json（跳过 DATA_SHRINK_GUARD_PERCENT 保护）
+}
+
+// parseRunFlags 解析默认抓取流程的命令行参数
+//
+// Description:
+//
+//	--only 支持正则表达式（不区分大小写），不是合法正则时按普通子串匹配；--limit 取过滤后的前 N 条订阅；
+//	--cache-dir 将 GET 响应缓存到磁盘，仅用于本地开发；--dump-dir 保存每个订阅的抓取诊断文件；
+//	--version 打印版本信息后退出；--force 在文章数骤减时仍上传 data.json
+func parseRunFlags(args []string) (runOptions, error) {
+	var opts runOptions
+	fs := flag.NewFlagSet("rssfetch", flag.ContinueOnError)
+	only := fs.String("only", "", "仅抓取 RSS 地址或名称匹配的订阅（子串或正则），用于排查单个博客")
+	fs.IntVar(&opts.Limit, "limit", 0, "最多抓取前 N 条订阅，用于冒烟测试")
+	fs.StringVar(&opts.CacheDir, "cache-dir", "", "将 GET 响应缓存到该目录，再次运行时直接使用缓存（仅用于本地开发）")
+	fs.StringVar(&opts.DumpDir, "dump-dir", "", "将每个订阅的原始响应、清理后的 XML 与解析结果写入该目录，便于复现解析问题")
+	fs.BoolVar(&opts.Version, "version", false, "打印版本号、提交与构建时间后退出")
+	fs.BoolVar(&opts.Force, "force", false, "文章数比上次骤减时仍上传 data.json（确认 RSS 列表确实删减过后使用）")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("多余的参数: %v", fs.Args())
+	}
+	if *only != "" {
+		re, err := regexp.Compile("(?i)" + *only)
+		if err != nil {
+			re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(*only))
+		}
+		opts.Only = re
+	}
+	return opts, nil
+}
+
+// subset 是否只抓取部分订阅
+func (o runOptions) subset() bool {
+	return o.Only != nil || o.Limit > 0
+}
+
+// filter 按 --only、--limit 筛选订阅
+func (o runOptions) filter(entries []feedEntry) []feedEntry {
+	var kept []feedEntry
+	for _, e := range entries {
+		if o.Only != nil && !o.Only.MatchString(e.URL) && (e.Name == "" || !o.Only.MatchString(e.Name)) {
+			continue
+		}
+		kept = append(kept, e)
+		if o.Limit > 0 && len(kept) >= o.Limit {
+			break
+		}
+	}
+	return kept
+}
+
+// printSubsetResults 打印部分抓取时每个订阅的结果
+func printSubsetResults(results []feedResult) {
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			fmt.Printf("[FAIL] %s\n       %v\n", r.FeedLink, r.Err)
+		case r.Article == nil:
+			fmt.Printf("[EMPTY] %s\n", r.FeedLink)
+		default:
+			fmt.Printf("[ OK ] %s (尝试 %d 次, %s)\n       %s | %s | %s\n",
+				r.FeedLink, r.Attempts, r.Strategy, r.Article.BlogName, r.Article.Title, r.Article.Published)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: cloudflare_kv.go
+// Description: 通过 Cloudflare API 读写 Workers KV（SAVE_TARGET=KV），输出文件以仓库内路径形式的键保存，
+// 由 Worker 在边缘直接返回
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// kvAPIBase Cloudflare API 地址
+const kvAPIBase = "https://api.cloudflare.com/client/v4"
+
+// kvNamespaceURL 构造 KV 命名空间的 API 地址
+func kvNamespaceURL(accountID, namespaceID string) string {
+	return fmt.Sprintf("%s/accounts/%s/storage/kv/namespaces/%s",
+		kvAPIBase, url.PathEscape(accountID), url.PathEscape(namespaceID))
+}
+
+// kvValueURL 构造 KV 键值的 API 地址，键中的 / 也需转义
+func kvValueURL(accountID, namespaceID, key string) string {
+	return kvNamespaceURL(accountID, namespaceID) + "/values/" + url.PathEscape(repoPath(key))
+}
+
+// kvRequest 发送带 API Token 的 Cloudflare API 请求
+func kvRequest(ctx context.Context, cfg *Config, method, apiURL string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.CFAPIToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	return client.Do(req)
+}
+
+// getKVValue 读取 KV 中指定键的值
+//
+// Returns:
+//   - []byte : 键对应的值，键不存在时返回 nil
+//   - error  : 请求失败或返回非 200/404 状态码时返回错误
+func getKVValue(ctx context.Context, cfg *Config, key string) ([]byte, error) {
+	resp, err := kvRequest(ctx, cfg, "GET", kvValueURL(cfg.CFAccountID, cfg.CFKVNamespaceID, key), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get KV value %s, status: %d, body: %s", key, resp.StatusCode, string(bodyBytes))
+	}
+}
+
+// putKVValue 将值写入 KV 中的指定键（已存在时覆盖）
+//
+// Description:
+//
+//	KV 为最终一致存储，写入后各地边缘节点最长约 60 秒内读到新值，对定时更新的友链数据足够
+func putKVValue(ctx context.Context, cfg *Config, key string, data []byte) error {
+	resp, err := kvRequest(ctx, cfg, "PUT", kvValueURL(cfg.CFAccountID, cfg.CFKVNamespaceID, key), data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to put KV value %s, status: %d, body: %s", key, resp.StatusCode, string(bodyBytes))
+	}
+	return nil
+}
+
+// checkKVAccess 校验 Cloudflare API Token 是否有效、KV 命名空间是否存在
+func checkKVAccess(ctx context.Context, cfg *Config) error {
+	resp, err := kvRequest(ctx, cfg, "GET", kvNamespaceURL(cfg.CFAccountID, cfg.CFKVNamespaceID), nil)
+	if err != nil {
+		return wrapErrorf(err, "Cloudflare KV预检失败: 无法访问 Cloudflare API")
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("Cloudflare KV预检失败: CF_API_TOKEN 无效或缺少 Workers KV Storage 编辑权限")
+	case http.StatusNotFound:
+		return fmt.Errorf("Cloudflare KV预检失败: 命名空间不存在, 请检查 CF_ACCOUNT_ID/CF_KV_NAMESPACE_ID")
+	default:
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Cloudflare KV预检失败, status: %d, body: %s", resp.StatusCode, string(bodyBytes))
+	}
+}
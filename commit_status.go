@@ -0,0 +1,125 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: commit_status.go
+// Description: 在 data.json 的提交上设置 commit status，记录本次运行的抓取成功率与新文章数，
+// 仓库的提交列表即可作为朋友圈的健康时间线
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// commitStatusContext commit status 的名称，GitHub 按名称区分同一提交上的多个状态
+const commitStatusContext = "lhasaRSS"
+
+// commitStatusDescription 生成 commit status 的描述，GitHub 限制为 140 个字符
+func commitStatusDescription(successCount, total, newPosts int) string {
+	ratio := 0.0
+	if total > 0 {
+		ratio = float64(successCount) / float64(total) * 100
+	}
+	desc := trf("抓取成功 %d/%d (%.0f%%), 新文章 %d 篇, 运行ID %s", successCount, total, ratio, newPosts, runID)
+	if r := []rune(desc); len(r) > 140 {
+		desc = string(r[:140])
+	}
+	return desc
+}
+
+// actionsRunURL 返回当前 GitHub Actions 运行的页面地址，不在 Actions 中运行时返回空
+func actionsRunURL() string {
+	server, repo, id := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if server == "" || repo == "" || id == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, id)
+}
+
+// latestGitHubCommitSHA 获取 main 分支上最后一次修改 filePath 的提交
+func latestGitHubCommitSHA(ctx context.Context, token, owner, repo, filePath string) (string, error) {
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/commits?sha=main&per_page=1&path=%s",
+		owner, repo, url.QueryEscape(repoPath(filePath)))
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to list commits of %s, status: %d, body: %s", filePath, resp.StatusCode, string(bodyBytes))
+	}
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&commits); err != nil {
+		return "", err
+	}
+	if len(commits) == 0 {
+		return "", fmt.Errorf("no commit found for %s", filePath)
+	}
+	return commits[0].SHA, nil
+}
+
+// setDataCommitStatus 在 data.json 最新的提交上设置 commit status
+//
+// Description:
+//
+//	仅 SAVE_TARGET=GITHUB 且开启 COMMIT_STATUS 时生效；Token 需要 Commit statuses 写权限。
+//	在 GitHub Actions 中运行时，状态链接到本次运行的页面
+func setDataCommitStatus(ctx context.Context, cfg *Config, description string) error {
+	if !cfg.CommitStatus || cfg.SaveTarget != "GITHUB" {
+		return nil
+	}
+	sha, err := latestGitHubCommitSHA(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, cfg.DataURL)
+	if err != nil {
+		return wrapErrorf(err, "设置提交状态失败")
+	}
+
+	payload := map[string]string{
+		"state":       "success",
+		"description": description,
+		"context":     commitStatusContext,
+	}
+	if runURL := actionsRunURL(); runURL != "" {
+		payload["target_url"] = runURL
+	}
+	jsonBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/statuses/%s", cfg.GitHubName, cfg.GitHubRepo, sha)
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, strings.NewReader(string(jsonBytes)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.GitHubToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return wrapErrorf(err, "设置提交状态失败")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return wrapErrorf(fmt.Errorf("HTTP状态码: %d, Body: %s", resp.StatusCode, string(bodyBytes)), "设置提交状态失败")
+	}
+	return nil
+}
@@ -6,8 +6,10 @@ package main
 
 import (
 	"fmt"
-	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Config 用于存放本项目需要的所有环境变量
@@ -19,32 +21,373 @@ type Config struct {
 	// RSS来源配置：
 	// 当 RSS_SOURCE = "COS" 时，RssListURL 应为远程txt文件的HTTP地址(如 COS地址)
 	// 当 RSS_SOURCE = "GITHUB" 时，RssListURL 可为本地路径，例如 "data/rss.txt"
-	RssSource  string // "COS" 或 "GITHUB"
-	RssListURL string // RSS列表txt文件的地址(远程或本地)
+	// 多个列表以逗号分隔，如 "data/rss.txt,https://example.com/class.opml"
+	// gist:ID/文件名 形式的地址通过 GitHub Gists API 读取
+	RssSource  string   // "COS" 或 "GITHUB"
+	RssListURL string   // RSS列表txt文件的地址(远程或本地)，原始配置值
+	RssLists   []string // 按逗号拆分后的RSS列表来源，支持 txt 与 OPML，合并去重后抓取
+	GistToken  string   // 读取 gist:ID/文件名 形式的RSS列表时使用的 Token，未设置时使用 TOKEN
+
+	// 读取 HTTP(S) 形式的RSS列表时的认证方式（none、basic、bearer、cos）及认证信息
+	RssAuth         string
+	RssAuthUsername string
+	RssAuthPassword string
+	RssAuthToken    string
 
 	// data.json 的目标存储配置
-	// 可选值: "GITHUB" 或 "COS"
+	// 可选值: "GITHUB"、"COS" 或 "KV"（Cloudflare Workers KV）
 	// 若未设置, 默认存至 "GITHUB"
 	SaveTarget    string
-	DataURL       string // data.json 在COS或GitHub的完整路径
+	DataURL       string // data.json 在COS或GitHub的完整路径，SAVE_TARGET=KV 时为 KV 中的键，{{date}} 等模板变量已展开
 	DefaultAvatar string // 默认头像URL
 	AvatarMapURL  string // 头像映射JSON文件的URL
 
+	// SAVE_TARGET=KV 时使用的 Cloudflare 账号、KV 命名空间与 API Token（需 Workers KV Storage 编辑权限）
+	CFAccountID     string
+	CFKVNamespaceID string
+	CFAPIToken      string
+
+	// 按分组配置的默认头像（GROUP_AVATARS="分组=头像URL,..."），未配置的分组使用 DefaultAvatar
+	GroupAvatars map[string]string
+
+	// 固定数据 foreverblog.json 的位置: HTTP(S) 地址视为 COS 对象，否则视为 GitHub 仓库内路径
+	ForeverBlogURL string
+
+	// 十年之约(foreverblog.cn)成员导入
+	ForeverblogMembersURL string   // 成员列表接口地址，为空则不导入
+	ForeverblogAllowlist  []string // 允许合并的成员域名白名单(opt-in)，为空时不合并任何成员
+
+	// 名称映射JSON文件的URL或本地路径，支持按RSS地址、域名、标题及正则匹配
+	NameMappingURL string
+
+	// COS 对象元数据
+	CosCacheControl string // 上传 COS 时设置的 Cache-Control
+	CosGzip         bool   // 是否在 COS 上同时上传 gzip 版本（地址加 .gz，Content-Encoding: gzip）
+
+	// COS 端备份
+	CosBackup              bool // 覆盖上传前是否将旧 data.json 复制到 backup/ 目录
+	CosBackupRetentionDays int  // 备份保留天数，<= 0 表示永久保留
+
+	// 博客存活监控
+	BlogLiveness      bool // 是否检查博客主页存活状态并输出 blogs.json
+	LivenessDeadAfter int  // 连续多少次无法访问后视为失联博客
+	BlogMetadata      bool // 是否在 blogs.json 中记录 RSS 的简介、语言和博客程序
+	BlogsJSON         bool // 未开启存活检查时是否也输出 blogs.json
+
+	// 文章链接失效检测
+	LinkRotMode          string // off: 不检测; flag: 标记失效文章; drop: 剔除失效文章
+	LinkRotIntervalHours int    // 同一链接的检测间隔(小时)
+
+	// 文章规范地址
+	CanonicalLinks         bool // 是否按文章页面的 rel=canonical 替换链接并去重
+	CanonicalIntervalHours int  // 同一链接的解析间隔(小时)
+
+	// 大模型（OpenAI 兼容接口）
+	LLMBaseURL     string // 接口地址，如 https://api.openai.com/v1
+	LLMAPIKey      string // API Key
+	LLMModel       string // 模型名称
+	LLMMaxPerRun   int    // 每次运行最多调用的次数
+	SummaryEnabled bool   // 是否为新文章生成一句话摘要
+
+	// 主题分类
+	TopicTagging  string   // off | rules | llm
+	TopicRulesURL string   // 关键词规则文件（URL 或本地路径），为空时使用内置规则
+	TopicList     []string // llm 模式下可选的主题列表
+
+	// 标题翻译
+	TranslateProvider string // off | deepl | openai | tencent
+	TranslateTarget   string // 目标语言，默认 zh
+	DeepLAPIKey       string // DeepL API Key
+	TMTRegion         string // 腾讯云机器翻译地域
+
+	// 流水线钩子（外部命令或 wasm:模块路径，通过标准输入输出交换 JSON）
+	HookPreFetch  string // 抓取前执行，输入输出 {"feeds": [...]}
+	HookPostParse string // 解析排序后执行，输入输出 {"items": [...]}
+	HookPreUpload string // 上传前执行，输入输出完整的 data.json
+
+	// HTTP 录制与回放，用于离线调试
+	HTTPRecordDir string // 录制所有 HTTP 响应到该目录
+	HTTPReplayDir string // 从该目录回放 HTTP 响应，不访问网络
+
+	OutputUpdated bool   // data.json 是否输出 updated 时间戳
+	OutputIndent  string // data.json 等输出文件的缩进，为空表示紧凑格式
+
+	// data.json 之外的输出格式（js、jsonp），供无法跨域读取 JSON 的旧页面使用
+	OutputFormats  []string
+	OutputJSVar    string // js 格式赋值的全局变量
+	OutputCallback string // jsonp 格式调用的回调函数
+
+	// data.json 体积告警
+	DataSizeWarnBytes   int     // 体积超过该字节数时告警，<= 0 表示不检查
+	DataGrowthWarnRatio float64 // 比上次增长超过该比例时告警（0.5 表示 50%），<= 0 表示不检查
+
+	// 文章数比上次减少超过该百分比时视为骤减（<= 0 表示不检查），
+	// DataShrinkMode 为 block 时阻止上传 data.json（可用 --force 跳过），为 warn 时仅告警
+	DataShrinkPercent float64
+	DataShrinkMode    string
+
+	// RSS 抓取的全局默认值，可在 RSS 列表中按订阅单独覆盖
+	HTTPTimeout int // 单次请求超时（秒）
+
+	// 按域名的历史抓取耗时自动设置超时（ADAPTIVE_TIMEOUT），订阅单独指定 timeout 时不生效
+	AdaptiveTimeout    bool
+	AdaptiveTimeoutMax int // 自适应超时的上限（秒）
+
+	// 重试策略（MAX_RETRIES、RETRY_BACKOFF、RETRY_MULTIPLIER、RETRY_JITTER、RETRY_MAX_ELAPSED），
+	// RSS 抓取与存储读取共用，尝试次数和初始间隔可在 RSS 列表中按订阅单独覆盖
+	Retry retryStrategy
+
+	Lang string // 运行日志语言：zh（默认）或 en
+
+	// 头像可用性检查
+	AvatarCheckMethod   string // head | get | sniff | off
+	AvatarCheckTimeout  int    // 单次检查超时（秒）
+	AvatarCheckInterval int    // 同一头像地址的检查间隔（小时），<= 0 表示每次都检查
+	AvatarMaxDimension  int    // AVATAR_CHECK_METHOD=sniff 时允许的头像最大宽高（像素），<= 0 表示不限制
+
+	RespectRobots bool // 抓取博客主页（解析头像等）前是否遵守 robots.txt
+
+	AvatarCacheTTL int // 头像解析结果的缓存时长（小时），<= 0 表示每次都重新解析
+
+	AvatarOrder          []string // 头像来源的尝试顺序，见 avatarSources
+	AvatarFaviconService string   // 图标服务地址模板，{host} 替换为博客域名
+
+	MinItems int // 订阅文章数少于该值时视为疑似被截断并提醒，<= 0 表示关闭
+
+	// RSS 解析
+	ParserStrict   bool // 严格解析：不清理非法XML字符，不使用忽略SSL等容错重试
+	FeedExtensions bool // 是否提取扩展字段（缩略图、作者）写入 data.json
+
+	// 原样写入文章 extra 字段的 gofeed 原始字段（guid、enclosures、extensions 等），为空表示不输出
+	RawFields []string
+
+	// 只收录这些语言的文章（zh、ja、ko、en、ru、ar、th），根据标题与正文判断，为空表示不过滤
+	ArticleLanguages []string
+
+	// 修复模式重试时允许跳过证书校验的域名（含子域名），"*" 表示全部，"none" 表示全部禁止
+	InsecureTLSDomains []string
+
+	// 抓取 RSS 时发送的 Accept-Encoding（如 "gzip, br"），为空时由 Go 自动协商 gzip；
+	// 无论是否设置，响应中的 br、gzip、deflate 压缩都会被透明解压
+	FeedAcceptEncoding string
+
+	ZombieCheck bool // 是否检测域名停放、被换成其他站点的僵尸订阅
+
+	QuarantineAfter      int // 连续多少次抓取失败后隔离订阅，<= 0 表示关闭
+	QuarantineRetryHours int // 隔离中的订阅每隔多少小时试探抓取一次
+
+	// 已确认迁移的订阅地址或域名（含子域名），僵尸检测不再对比其标题与主页域名的变化
+	ZombieAck []string
+
+	Badges bool // 是否生成 shields.io 徽章 JSON
+
+	ProblemsJSON bool // 是否在 data.json 同目录输出按类型整理的 problems.json
+
+	Spotlight bool // 是否在 data.json 同目录输出每日推荐博客 spotlight.json
+	OnThisDay bool // 是否在 data.json 同目录输出往年今日的文章 onthisday.json
+
+	// 友链健康状况 RSS 的保存位置（与 SAVE_TARGET 一致的 COS 地址或仓库内路径，只写文件名时保存在 data.json 同目录），为空时不生成
+	HealthFeed string
+
+	// data.json 更新时同时写入的快照位置，支持 {{date}} 等模板变量，只写文件名时保存在 data.json 同目录，为空表示不写快照
+	DataSnapshot string
+
+	StatsFile   string // 仓库内需要更新统计表的文件（如 README.md），为空时不更新
+	StatsRecent int    // 统计表中列出的最新文章数量
+
+	PublishMinSuccessRatio float64 // 成功抓取的订阅占比（0~1）低于该值时不上传 data.json，0 表示不限制
+
+	MergeMode    string // full: 每次整体覆盖 data.json; incremental: 基于上次数据增量合并
+	RemovedFeeds string // 增量合并时已从RSS列表删除的订阅的旧文章: drop 移除; retire 保留并标记 retired
+
+	// 证书到期提醒
+	CertExpiryWarnDays int // HTTPS 证书剩余天数不超过该值时提醒，<= 0 表示关闭
+
+	// 通知
+	NotifyWebhook  string // 通知 Webhook 地址，为空则不发送到 Webhook
+	NotifySummary  bool   // 每次更新数据后是否将运行摘要发送到通知渠道
+	NotifyTemplate string // 所有通知渠道共用的正文 text/template 模板文件路径，为空时直接使用通知内容
+
+	NotifyDedupWindow int // 同一告警的通知间隔（小时），<= 0 表示每次都通知
+
+	// Telegram 通知渠道，TELEGRAM_BOT_TOKEN 为空时不发送
+	TelegramBotToken string
+	TelegramChatID   string
+
+	// 邮件通知渠道，SMTP_HOST 为空时不发送
+	SMTPHost        string
+	SMTPPort        int
+	SMTPUsername    string
+	SMTPPassword    string
+	NotifyEmailFrom string   // 发件人，为空时使用 SMTP_USERNAME
+	NotifyEmailTo   []string // 收件人
+
+	// 运行心跳地址（healthchecks.io 的 Ping 地址或 Uptime Kuma 的 Push 地址），每次运行结束后上报结果与耗时，为空则不发送
+	HeartbeatURL string
+
+	// SAVE_TARGET=GITHUB 时是否在 data.json 的提交上设置 commit status（抓取成功率、新文章数）
+	CommitStatus bool
+
+	// 新文章通知摘要的发送时间点（HH:MM，按 TZ 所在时区），为空表示不发送
+	NotifyDigestTimes []string
+
+	// Webmention/Pingback：以聚合页地址为来源通知新收录文章的作者，为空表示不发送
+	WebmentionSource    string
+	WebmentionMaxPerRun int // 每次运行最多发送的数量
+
+	SummaryTemplate string // 运行摘要的 text/template 模板文件路径，为空时使用默认格式
+	BandwidthTop    int    // 运行摘要中列出的流量最多的域名数，<= 0 表示不列出
+
+	// 单个日志文件的大小上限（字节），当天日志超过后写入新的分卷，<= 0 表示不分卷
+	LogMaxBytes int
+
+	// 日志时间戳与按天切分日志文件使用的时区（如 Asia/Shanghai），为空时使用进程时区（TZ）
+	LogTimezone string
+
+	// 本次运行日志缓冲的大小上限（字节），超过后丢弃新的日志行并在日志末尾记录丢弃的行数，<= 0 表示不限制
+	LogBufferMaxBytes int
+
+	// 抓取缓存
+	FetchCachePath string // 抓取缓存的位置（本地路径、COS 对象地址或仓库内路径），保存 ETag、头像等跨运行状态，为空则不使用缓存
+	CacheStore     string // 抓取缓存的存储后端: file / cos / github
+	// CACHE_STORE=github 时两次提交的最短间隔（小时），间隔内只有检查时间、抓取耗时变化时不提交，<= 0 表示每次变化都提交
+	CacheCommitInterval int
+
+	// 启动预检
+	Preflight bool // 是否在抓取前检查 COS、GitHub 与 RSS 列表的连通性
+
+	// 腾讯云 CDN 缓存刷新
+	// 开启后，每次上传 COS 成功都会刷新对应 URL 的 CDN 缓存
+	CDNPurge     bool     // 是否在上传 COS 后刷新 CDN 缓存
+	CDNDomain    string   // CDN 加速域名(如 cos.lhasa.icu)，为空时直接刷新 COS 地址
+	CDNPurgeURLs []string // 额外需要刷新的 URL 列表
+
 	// GitHub 相关
 	GitHubToken string // GitHub Token
 	GitHubName  string // GitHub 用户名
 	GitHubRepo  string // GitHub 仓库名
+
+	// GitHub 提交身份
+	// 若未设置, 作者与提交者均默认使用 GitHubName 及其 noreply 邮箱
+	AuthorName     string   // 提交作者名称
+	AuthorEmail    string   // 提交作者邮箱
+	CommitterName  string   // 提交者名称
+	CommitterEmail string   // 提交者邮箱
+	CoAuthors      []string // 共同作者, 形如 "Name <email>", 以 Co-authored-by 形式追加到提交信息末尾
 }
 
 // envWithDefault 用于获取系统环境变量，若不存在则返回默认值
 func envWithDefault(key, def string) string {
-	v := os.Getenv(key)
+	v, source := lookupConfigEnv(key)
 	if v == "" {
+		recordConfig(key, def, sourceDefault)
 		return def
 	}
+	recordConfig(key, v, source)
 	return v
 }
 
+// envBool 用于获取布尔型环境变量，支持 true/false/1/0/yes/no，无法识别时返回默认值
+func envBool(key string, def bool) bool {
+	raw, source := lookupConfigEnv(key)
+	raw = strings.TrimSpace(raw)
+	switch strings.ToLower(raw) {
+	case "true", "1", "yes", "on":
+		recordConfig(key, "true", source)
+		return true
+	case "false", "0", "no", "off":
+		recordConfig(key, "false", source)
+		return false
+	default:
+		recordConfig(key, strconv.FormatBool(def), defaultSource(raw))
+		return def
+	}
+}
+
+// envInt 用于获取整型环境变量，未设置或无法解析时返回默认值
+func envInt(key string, def int) int {
+	v, source := lookupConfigEnv(key)
+	v = strings.TrimSpace(v)
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		recordConfig(key, strconv.Itoa(def), defaultSource(v))
+		return def
+	}
+	recordConfig(key, v, source)
+	return n
+}
+
+// envFloat 用于获取浮点型环境变量，未设置或无法解析时返回默认值
+func envFloat(key string, def float64) float64 {
+	v, source := lookupConfigEnv(key)
+	v = strings.TrimSpace(v)
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		recordConfig(key, strconv.FormatFloat(def, 'g', -1, 64), defaultSource(v))
+		return def
+	}
+	recordConfig(key, v, source)
+	return f
+}
+
+// splitList 按分隔符拆分字符串，去掉空白项
+func splitList(s, sep string) []string {
+	var list []string
+	for _, item := range strings.Split(s, sep) {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseIndent 解析 OUTPUT_INDENT：tab/tabs 表示制表符，数字表示空格数，0 表示紧凑格式，无法解析时使用两个空格
+func parseIndent(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "tab", "tabs":
+		return "\t"
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 || n > 8 {
+		fmt.Printf("[WARN] OUTPUT_INDENT 无效: %s, 使用默认值 2\n", s)
+		return "  "
+	}
+	return strings.Repeat(" ", n)
+}
+
+// parseKeyValues 解析 "键=值,键=值" 形式的配置，格式错误的项输出警告后忽略
+func parseKeyValues(name, s string) map[string]string {
+	m := make(map[string]string)
+	for _, item := range splitList(s, ",") {
+		key, value, ok := strings.Cut(item, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			fmt.Printf("[WARN] %s 中的配置项无效, 已忽略: %s\n", name, item)
+			continue
+		}
+		m[key] = value
+	}
+	return m
+}
+
+// defaultAvatarFor 返回分组对应的默认头像，分组未单独配置时使用 DEFAULT_AVATAR
+func (cfg *Config) defaultAvatarFor(group string) string {
+	if avatar, ok := cfg.GroupAvatars[group]; ok {
+		return avatar
+	}
+	return cfg.DefaultAvatar
+}
+
+// commitSignature 根据配置生成 GitHub 提交使用的作者、提交者及共同作者信息
+func (cfg *Config) commitSignature() commitSignature {
+	return commitSignature{
+		Author:    gitUser{Name: cfg.AuthorName, Email: cfg.AuthorEmail},
+		Committer: gitUser{Name: cfg.CommitterName, Email: cfg.CommitterEmail},
+		CoAuthors: cfg.CoAuthors,
+	}
+}
+
 // LoadConfig 从系统环境变量中加载配置
 //
 // Description:
@@ -52,6 +395,7 @@ func envWithDefault(key, def string) string {
 //	该函数仅做字符串读取，不做任何校验，后续可调用 cfg.Validate() 做集中校验
 //	新增环境变量 RSS_SOURCE 用于区分 RSS 列表使用 COS 还是本地文件
 func LoadConfig() *Config {
+	resetConfigSources()
 
 	// 先将 RSS_SOURCE、SAVE_TARGET 统一转换为大写，方便后续判断
 	rssSource := strings.ToUpper(envWithDefault("RSS_SOURCE", "GITHUB"))
@@ -64,25 +408,228 @@ func LoadConfig() *Config {
 	}
 
 	dataURL := envWithDefault("DATA", "")
-	if saveTarget == "GITHUB" && dataURL == "" {
+	if (saveTarget == "GITHUB" || saveTarget == "MEMORY") && dataURL == "" {
 		dataURL = "data/data.json"
 	}
+	if saveTarget == "KV" && dataURL == "" {
+		dataURL = "data.json"
+	}
+
+	githubName := envWithDefault("NAME", "")
+	defaultEmail := ""
+	if githubName != "" {
+		defaultEmail = githubName + "@users.noreply.github.com"
+	}
+
+	// 提交者未设置时默认为仓库所有者，作者未设置时默认与提交者相同
+	committerName := envWithDefault("COMMITTER_NAME", githubName)
+	committerEmail := envWithDefault("COMMITTER_EMAIL", defaultEmail)
+	authorName := envWithDefault("AUTHOR_NAME", committerName)
+	authorEmail := envWithDefault("AUTHOR_EMAIL", committerEmail)
 
 	cfg := &Config{
-		TencentSecretID:  os.Getenv("TENCENT_CLOUD_SECRET_ID"),
-		TencentSecretKey: os.Getenv("TENCENT_CLOUD_SECRET_KEY"),
+		TencentSecretID:  envWithDefault("TENCENT_CLOUD_SECRET_ID", ""),
+		TencentSecretKey: envWithDefault("TENCENT_CLOUD_SECRET_KEY", ""),
 
 		RssSource:  rssSource,
 		RssListURL: rssListURL,
+		RssLists:   splitList(rssListURL, ","),
+		GistToken:  envWithDefault("GIST_TOKEN", envWithDefault("TOKEN", "")),
+
+		RssAuth:         strings.ToLower(envWithDefault("RSS_AUTH", "none")),
+		RssAuthUsername: envWithDefault("RSS_AUTH_USERNAME", ""),
+		RssAuthPassword: envWithDefault("RSS_AUTH_PASSWORD", ""),
+		RssAuthToken:    envWithDefault("RSS_AUTH_TOKEN", ""),
 
 		SaveTarget:    saveTarget,
-		DataURL:       dataURL,
+		DataURL:       expandDataPath(dataURL, time.Now()),
 		DefaultAvatar: envWithDefault("DEFAULT_AVATAR", "https://cn.gravatar.com/avatar"),
+		GroupAvatars:  parseKeyValues("GROUP_AVATARS", envWithDefault("GROUP_AVATARS", "")),
 		AvatarMapURL:  envWithDefault("AVATAR_MAP_URL", "https://cos.lhasa.icu/lhasaRSS/avatar.json"),
 
-		GitHubToken: os.Getenv("TOKEN"),
-		GitHubName:  os.Getenv("NAME"),
-		GitHubRepo:  os.Getenv("REPOSITORY"),
+		CFAccountID:     envWithDefault("CF_ACCOUNT_ID", ""),
+		CFKVNamespaceID: envWithDefault("CF_KV_NAMESPACE_ID", ""),
+		CFAPIToken:      envWithDefault("CF_API_TOKEN", ""),
+
+		ForeverBlogURL: envWithDefault("FOREVER_BLOG_URL", ""),
+		NameMappingURL: envWithDefault("NAME_MAPPING_URL", ""),
+
+		ForeverblogMembersURL: envWithDefault("FOREVERBLOG_MEMBERS_URL", ""),
+		ForeverblogAllowlist:  splitList(envWithDefault("FOREVERBLOG_ALLOWLIST", ""), ","),
+
+		CosCacheControl: envWithDefault("COS_CACHE_CONTROL", "max-age=300"),
+		CosGzip:         envBool("COS_GZIP", false),
+
+		CosBackup:              envBool("COS_BACKUP", false),
+		CosBackupRetentionDays: envInt("COS_BACKUP_RETENTION_DAYS", 30),
+
+		BlogLiveness:      envBool("BLOG_LIVENESS", false),
+		LivenessDeadAfter: envInt("LIVENESS_DEAD_AFTER", 3),
+		BlogMetadata:      envBool("BLOG_METADATA", false),
+		BlogsJSON:         envBool("BLOGS_JSON", false),
+
+		LinkRotMode:          strings.ToLower(envWithDefault("LINK_ROT_CHECK", "off")),
+		LinkRotIntervalHours: envInt("LINK_ROT_INTERVAL_HOURS", 24),
+
+		CanonicalLinks:         envBool("CANONICAL_LINKS", false),
+		CanonicalIntervalHours: envInt("CANONICAL_INTERVAL_HOURS", 168),
+
+		LLMBaseURL:     envWithDefault("LLM_BASE_URL", "https://api.openai.com/v1"),
+		LLMAPIKey:      envWithDefault("LLM_API_KEY", ""),
+		LLMModel:       envWithDefault("LLM_MODEL", "gpt-4o-mini"),
+		LLMMaxPerRun:   envInt("LLM_MAX_PER_RUN", 20),
+		SummaryEnabled: envBool("SUMMARY_ENABLED", false),
+
+		TopicTagging:  strings.ToLower(envWithDefault("TOPIC_TAGGING", "off")),
+		TopicRulesURL: envWithDefault("TOPIC_RULES", ""),
+		TopicList:     splitList(envWithDefault("TOPIC_LIST", "技术,生活,摄影,读书,旅行,随笔"), ","),
+
+		TranslateProvider: strings.ToLower(envWithDefault("TRANSLATE_PROVIDER", "off")),
+		TranslateTarget:   envWithDefault("TRANSLATE_TARGET", "zh"),
+		DeepLAPIKey:       envWithDefault("DEEPL_API_KEY", ""),
+		TMTRegion:         envWithDefault("TMT_REGION", "ap-guangzhou"),
+
+		HookPreFetch:  envWithDefault("HOOK_PRE_FETCH", ""),
+		HookPostParse: envWithDefault("HOOK_POST_PARSE", ""),
+		HookPreUpload: envWithDefault("HOOK_PRE_UPLOAD", ""),
+
+		HTTPRecordDir: envWithDefault("HTTP_RECORD", ""),
+		HTTPReplayDir: envWithDefault("HTTP_REPLAY", ""),
+
+		OutputUpdated: envBool("OUTPUT_UPDATED", true),
+		OutputIndent:  parseIndent(envWithDefault("OUTPUT_INDENT", "2")),
+
+		OutputFormats:  splitList(strings.ToLower(envWithDefault("OUTPUT_FORMATS", "json")), ","),
+		OutputJSVar:    envWithDefault("OUTPUT_JS_VAR", "window.__FRIENDS__"),
+		OutputCallback: envWithDefault("OUTPUT_JSONP_CALLBACK", "friendsCallback"),
+
+		DataSizeWarnBytes:   envInt("DATA_SIZE_WARN_BYTES", 1<<20),
+		DataGrowthWarnRatio: envFloat("DATA_GROWTH_WARN_RATIO", 0.5),
+
+		DataShrinkPercent: envFloat("DATA_SHRINK_GUARD_PERCENT", 50),
+		DataShrinkMode:    strings.ToLower(envWithDefault("DATA_SHRINK_GUARD_MODE", "block")),
+
+		HTTPTimeout: envInt("HTTP_TIMEOUT", 10),
+
+		AdaptiveTimeout:    envBool("ADAPTIVE_TIMEOUT", false),
+		AdaptiveTimeoutMax: envInt("ADAPTIVE_TIMEOUT_MAX", 60),
+
+		Retry: retryStrategy{
+			MaxAttempts:  envInt("MAX_RETRIES", 3),
+			BaseInterval: time.Duration(envFloat("RETRY_BACKOFF", 1) * float64(time.Second)),
+			Multiplier:   envFloat("RETRY_MULTIPLIER", 2),
+			Jitter:       envFloat("RETRY_JITTER", 0),
+			MaxElapsed:   time.Duration(envInt("RETRY_MAX_ELAPSED", 0)) * time.Second,
+		},
+
+		Lang: envWithDefault("LHASA_LANG", ""),
+
+		AvatarCheckMethod:   strings.ToLower(envWithDefault("AVATAR_CHECK_METHOD", "head")),
+		AvatarCheckTimeout:  envInt("AVATAR_CHECK_TIMEOUT", 5),
+		AvatarCheckInterval: envInt("AVATAR_CHECK_INTERVAL", 24),
+		AvatarMaxDimension:  envInt("AVATAR_MAX_DIMENSION", 4096),
+
+		RespectRobots: envBool("RESPECT_ROBOTS", false),
+
+		AvatarCacheTTL: envInt("AVATAR_CACHE_TTL", 24),
+
+		AvatarOrder:          splitList(strings.ToLower(envWithDefault("AVATAR_ORDER", "map,feed,page,favicon")), ","),
+		AvatarFaviconService: envWithDefault("AVATAR_FAVICON_SERVICE", "https://www.google.com/s2/favicons?domain={host}&sz=128"),
+
+		MinItems: envInt("MIN_ITEMS", 0),
+
+		ParserStrict:   envBool("PARSER_STRICT", false),
+		FeedExtensions: envBool("FEED_EXTENSIONS", false),
+
+		RawFields: splitList(strings.ToLower(envWithDefault("OUTPUT_RAW_FIELDS", "")), ","),
+
+		ArticleLanguages: splitList(strings.ToLower(envWithDefault("ARTICLE_LANGUAGES", "")), ","),
+
+		InsecureTLSDomains: splitList(envWithDefault("INSECURE_TLS_DOMAINS", "*"), ","),
+
+		FeedAcceptEncoding: strings.TrimSpace(envWithDefault("FEED_ACCEPT_ENCODING", "")),
+
+		ZombieCheck: envBool("ZOMBIE_CHECK", false),
+		ZombieAck:   splitList(envWithDefault("ZOMBIE_ACK", ""), ","),
+
+		QuarantineAfter:      envInt("QUARANTINE_AFTER", 0),
+		QuarantineRetryHours: envInt("QUARANTINE_RETRY_HOURS", 24),
+
+		Badges: envBool("BADGES", false),
+
+		ProblemsJSON: envBool("PROBLEMS_JSON", false),
+
+		Spotlight: envBool("SPOTLIGHT", false),
+		OnThisDay: envBool("ON_THIS_DAY", false),
+
+		HealthFeed: envWithDefault("HEALTH_FEED", ""),
+
+		DataSnapshot: envWithDefault("DATA_SNAPSHOT", ""),
+
+		StatsFile:   envWithDefault("STATS_FILE", ""),
+		StatsRecent: envInt("STATS_RECENT", 5),
+
+		PublishMinSuccessRatio: envFloat("PUBLISH_MIN_SUCCESS_RATIO", 0),
+
+		MergeMode:    strings.ToLower(envWithDefault("MERGE_MODE", "full")),
+		RemovedFeeds: strings.ToLower(envWithDefault("REMOVED_FEEDS", "drop")),
+
+		CertExpiryWarnDays: envInt("CERT_EXPIRY_WARN_DAYS", 14),
+
+		NotifyWebhook:  envWithDefault("NOTIFY_WEBHOOK", ""),
+		NotifySummary:  envBool("NOTIFY_SUMMARY", false),
+		NotifyTemplate: envWithDefault("NOTIFY_TEMPLATE", ""),
+
+		NotifyDedupWindow: envInt("NOTIFY_DEDUP_WINDOW", 24),
+
+		TelegramBotToken: envWithDefault("TELEGRAM_BOT_TOKEN", ""),
+		TelegramChatID:   envWithDefault("TELEGRAM_CHAT_ID", ""),
+
+		SMTPHost:        envWithDefault("SMTP_HOST", ""),
+		SMTPPort:        envInt("SMTP_PORT", 587),
+		SMTPUsername:    envWithDefault("SMTP_USERNAME", ""),
+		SMTPPassword:    envWithDefault("SMTP_PASSWORD", ""),
+		NotifyEmailFrom: envWithDefault("NOTIFY_EMAIL_FROM", ""),
+		NotifyEmailTo:   splitList(envWithDefault("NOTIFY_EMAIL_TO", ""), ","),
+
+		HeartbeatURL: envWithDefault("HEARTBEAT_URL", ""),
+
+		CommitStatus: envBool("COMMIT_STATUS", false),
+
+		NotifyDigestTimes: splitList(envWithDefault("NOTIFY_DIGEST_TIMES", ""), ","),
+
+		WebmentionSource:    envWithDefault("WEBMENTION_SOURCE", ""),
+		WebmentionMaxPerRun: envInt("WEBMENTION_MAX_PER_RUN", 20),
+
+		SummaryTemplate: envWithDefault("SUMMARY_TEMPLATE", ""),
+		BandwidthTop:    envInt("BANDWIDTH_TOP", 5),
+
+		LogMaxBytes: envInt("LOG_MAX_BYTES", 512<<10),
+
+		LogTimezone: envWithDefault("LOG_TIMEZONE", ""),
+
+		LogBufferMaxBytes: envInt("LOG_BUFFER_MAX_BYTES", 4<<20),
+
+		FetchCachePath: envWithDefault("FETCH_CACHE", ".cache/fetch_cache.json"),
+		CacheStore:     strings.ToLower(envWithDefault("CACHE_STORE", "file")),
+
+		CacheCommitInterval: envInt("CACHE_COMMIT_INTERVAL", 24),
+
+		Preflight: envBool("PREFLIGHT", true),
+
+		CDNPurge:     envBool("CDN_PURGE", false),
+		CDNDomain:    envWithDefault("CDN_DOMAIN", ""),
+		CDNPurgeURLs: splitList(envWithDefault("CDN_PURGE_URLS", ""), ","),
+
+		GitHubToken: envWithDefault("TOKEN", ""),
+		GitHubName:  githubName,
+		GitHubRepo:  envWithDefault("REPOSITORY", ""),
+
+		AuthorName:     authorName,
+		AuthorEmail:    authorEmail,
+		CommitterName:  committerName,
+		CommitterEmail: committerEmail,
+		CoAuthors:      splitList(envWithDefault("CO_AUTHORS", ""), ";"),
 	}
 
 	return cfg
@@ -96,8 +643,8 @@ func LoadConfig() *Config {
 func (cfg *Config) Validate() error {
 	var missing []string
 
-	// 当 RSS_SOURCE 或 SAVE_TARGET 需要使用 COS 时，需校验腾讯云配置
-	if cfg.RssSource == "COS" || cfg.SaveTarget == "COS" {
+	// 当 RSS_SOURCE、SAVE_TARGET 或 CACHE_STORE 需要使用 COS，或开启 CDN 刷新、腾讯云翻译时，需校验腾讯云配置
+	if cfg.RssSource == "COS" || cfg.SaveTarget == "COS" || cfg.CacheStore == "cos" || cfg.CDNPurge || cfg.TranslateProvider == "tencent" {
 		if cfg.TencentSecretID == "" {
 			missing = append(missing, "TENCENT_CLOUD_SECRET_ID")
 		}
@@ -116,8 +663,21 @@ func (cfg *Config) Validate() error {
 		missing = append(missing, "DATA")
 	}
 
-	// 如果保存到 GITHUB，必须提供 GitHub 相关配置
-	if cfg.SaveTarget == "GITHUB" {
+	// SAVE_TARGET = KV 时需提供 Cloudflare 账号、命名空间与 API Token
+	if cfg.SaveTarget == "KV" {
+		if cfg.CFAccountID == "" {
+			missing = append(missing, "CF_ACCOUNT_ID")
+		}
+		if cfg.CFKVNamespaceID == "" {
+			missing = append(missing, "CF_KV_NAMESPACE_ID")
+		}
+		if cfg.CFAPIToken == "" {
+			missing = append(missing, "CF_API_TOKEN")
+		}
+	}
+
+	// 如果保存到 GITHUB、缓存保存在仓库中或需要更新仓库内的统计表，必须提供 GitHub 相关配置
+	if cfg.SaveTarget == "GITHUB" || cfg.CacheStore == "github" || cfg.StatsFile != "" {
 		if cfg.GitHubToken == "" {
 			missing = append(missing, "TOKEN")
 		}
@@ -129,8 +689,86 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	// 使用大模型生成摘要、分类或翻译时需提供大模型 API Key
+	if (cfg.SummaryEnabled || cfg.TopicTagging == "llm" || cfg.TranslateProvider == "openai") && cfg.LLMAPIKey == "" {
+		missing = append(missing, "LLM_API_KEY")
+	}
+
+	if cfg.TranslateProvider == "deepl" && cfg.DeepLAPIKey == "" {
+		missing = append(missing, "DEEPL_API_KEY")
+	}
+
+	// 新文章摘要需要至少一个通知渠道；新文章摘要、健康订阅与 Webmention 都依赖持久化的抓取缓存记录跨运行的状态
+	if len(cfg.NotifyDigestTimes) > 0 && len(configNotifiers(cfg)) == 0 {
+		missing = append(missing, "NOTIFY_WEBHOOK")
+	}
+	switch cfg.RssAuth {
+	case "basic":
+		if cfg.RssAuthUsername == "" {
+			missing = append(missing, "RSS_AUTH_USERNAME")
+		}
+	case "bearer":
+		if cfg.RssAuthToken == "" {
+			missing = append(missing, "RSS_AUTH_TOKEN")
+		}
+	case "cos":
+		if cfg.TencentSecretID == "" || cfg.TencentSecretKey == "" {
+			missing = append(missing, "TENCENT_CLOUD_SECRET_ID", "TENCENT_CLOUD_SECRET_KEY")
+		}
+	}
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatID == "" {
+		missing = append(missing, "TELEGRAM_CHAT_ID")
+	}
+	if cfg.SMTPHost != "" && len(cfg.NotifyEmailTo) == 0 {
+		missing = append(missing, "NOTIFY_EMAIL_TO")
+	}
+	if cfg.SMTPHost != "" && cfg.NotifyEmailFrom == "" && cfg.SMTPUsername == "" {
+		missing = append(missing, "NOTIFY_EMAIL_FROM")
+	}
+	if (len(cfg.NotifyDigestTimes) > 0 || cfg.HealthFeed != "" || cfg.WebmentionSource != "") && cfg.FetchCachePath == "" {
+		missing = append(missing, "FETCH_CACHE")
+	}
+
 	if len(missing) > 0 {
 		return fmt.Errorf("环境变量缺失: %v", missing)
 	}
-	return nil
+	if !slices.Contains(rssListAuthModes, cfg.RssAuth) {
+		return fmt.Errorf("RSS_AUTH 值无效: %s (只能是 none、basic、bearer 或 cos)", cfg.RssAuth)
+	}
+	if err := validateRawFields(cfg.RawFields); err != nil {
+		return err
+	}
+	if err := validateArticleLanguages(cfg.ArticleLanguages); err != nil {
+		return err
+	}
+	if err := validateAvatarOrder(cfg.AvatarOrder); err != nil {
+		return err
+	}
+	if err := validateDataPath("DATA", cfg.DataURL); err != nil {
+		return err
+	}
+	if err := validateDataPath("DATA_SNAPSHOT", expandDataPath(cfg.DataSnapshot, time.Now())); err != nil {
+		return err
+	}
+	// 上限为 0 时自适应超时会变成不限时
+	if cfg.AdaptiveTimeout && cfg.AdaptiveTimeoutMax <= 0 {
+		return fmt.Errorf("ADAPTIVE_TIMEOUT_MAX 值无效: %d (必须大于 0)", cfg.AdaptiveTimeoutMax)
+	}
+	if cfg.Retry.Multiplier < 1 {
+		return fmt.Errorf("RETRY_MULTIPLIER 值无效: %g (不能小于 1)", cfg.Retry.Multiplier)
+	}
+	if cfg.Retry.Jitter < 0 || cfg.Retry.Jitter > 1 {
+		return fmt.Errorf("RETRY_JITTER 值无效: %g (只能在 0~1 之间)", cfg.Retry.Jitter)
+	}
+	// 取值为比例而不是百分比，误写成 80 会阻止每一次发布
+	if cfg.PublishMinSuccessRatio < 0 || cfg.PublishMinSuccessRatio > 1 {
+		return fmt.Errorf("PUBLISH_MIN_SUCCESS_RATIO 值无效: %g (只能在 0~1 之间, 如 0.8 表示 80%%)", cfg.PublishMinSuccessRatio)
+	}
+	if cfg.DataShrinkMode != "block" && cfg.DataShrinkMode != "warn" {
+		return fmt.Errorf("DATA_SHRINK_GUARD_MODE 值无效: %s (只能是 'block' 或 'warn')", cfg.DataShrinkMode)
+	}
+	if _, err := parseDigestTimes(cfg.NotifyDigestTimes); err != nil {
+		return err
+	}
+	return validateOutputFormats(cfg)
 }
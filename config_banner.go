@@ -0,0 +1,115 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: config_banner.go
+// Description: 启动时输出生效配置（敏感值打码），并标明每项来自环境变量还是默认值，便于发现配置错误。
+// 所有配置均通过环境变量读取（云函数、Actions 的配置最终也以环境变量传入），来源区分全局环境变量、配置方案与默认值
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 配置值的来源
+const (
+	sourceEnv     = "env"     // 来自环境变量
+	sourceProfile = "profile" // 来自当前配置方案的环境变量（PROFILE_<方案>_<KEY>）
+	sourceDefault = "default" // 未设置，使用默认值
+	sourceInvalid = "invalid" // 已设置但无法解析，使用默认值
+)
+
+// configValue 一项生效的配置
+type configValue struct {
+	Key    string // 环境变量名
+	Value  string // 生效的值
+	Source string // 来源：env、profile、default 或 invalid
+}
+
+// configSources 由 env* 系列函数在读取环境变量时记录，按首次读取的顺序保存
+var configSources struct {
+	mu     sync.Mutex
+	values []configValue
+	index  map[string]int
+}
+
+// resetConfigSources 清空已记录的配置来源，LoadConfig 开始时调用（云函数中同一进程会多次加载配置）
+func resetConfigSources() {
+	configSources.mu.Lock()
+	defer configSources.mu.Unlock()
+	configSources.values = nil
+	configSources.index = make(map[string]int)
+}
+
+// recordConfig 记录一项配置的生效值与来源，同一变量被多次读取时以最后一次为准
+func recordConfig(key, value, source string) {
+	configSources.mu.Lock()
+	defer configSources.mu.Unlock()
+	if configSources.index == nil {
+		configSources.index = make(map[string]int)
+	}
+	v := configValue{Key: key, Value: value, Source: source}
+	if i, ok := configSources.index[key]; ok {
+		configSources.values[i] = v
+		return
+	}
+	configSources.index[key] = len(configSources.values)
+	configSources.values = append(configSources.values, v)
+}
+
+// defaultSource 环境变量未设置时为 default，已设置但无法解析时为 invalid
+func defaultSource(raw string) string {
+	if raw == "" {
+		return sourceDefault
+	}
+	return sourceInvalid
+}
+
+// secretKeyMarkers 名称中包含这些片段的环境变量视为敏感信息
+var secretKeyMarkers = []string{"TOKEN", "SECRET", "API_KEY", "PASSWORD", "WEBHOOK", "HEARTBEAT_URL"}
+
+// maskConfigValue 对敏感配置打码，只保留前 4 个字符，便于确认使用的是哪一个凭证
+func maskConfigValue(key, value string) string {
+	if value == "" {
+		return value
+	}
+	for _, marker := range secretKeyMarkers {
+		if strings.Contains(key, marker) {
+			if len(value) <= 8 {
+				return "****"
+			}
+			return value[:4] + "****"
+		}
+	}
+	return value
+}
+
+// configBanner 生成生效配置的说明
+//
+// Description:
+//
+//	列出所有通过环境变量设置的项（profile 表示来自当前配置方案），以及默认值不为空的项；未设置且默认为空的项省略，
+//	已设置但无法解析的项标记为 invalid（实际使用默认值），这类问题往往只在运行结果异常时才会被注意到
+func configBanner() string {
+	configSources.mu.Lock()
+	values := append([]configValue(nil), configSources.values...)
+	configSources.mu.Unlock()
+
+	width := 0
+	for _, v := range values {
+		width = max(width, len(v.Key))
+	}
+
+	var sb strings.Builder
+	if activeProfile != "" {
+		sb.WriteString(trf("配置方案: %s", activeProfile) + "\n")
+	}
+	sb.WriteString(tr("生效配置（env: 环境变量, profile: 配置方案, default: 默认值, invalid: 无法解析已使用默认值）:") + "\n")
+	for _, v := range values {
+		if v.Source == sourceDefault && v.Value == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, "  %-*s = %q (%s)\n", width, v.Key, maskConfigValue(v.Key, v.Value), v.Source)
+	}
+	return sb.String()
+}
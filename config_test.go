@@ -0,0 +1,44 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: config_test.go
+// Description: 配置校验的测试
+
+package main
+
+import "testing"
+
+func TestValidatePublishMinSuccessRatio(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"0", false},
+		{"0.8", false},
+		{"1", false},
+		{"80", true},
+		{"-0.1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("SAVE_TARGET", "MEMORY")
+			t.Setenv("PUBLISH_MIN_SUCCESS_RATIO", tt.value)
+			err := LoadConfig().Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAdaptiveTimeoutMax(t *testing.T) {
+	for value, wantErr := range map[string]bool{"60": false, "0": true, "-1": true} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("SAVE_TARGET", "MEMORY")
+			t.Setenv("ADAPTIVE_TIMEOUT", "true")
+			t.Setenv("ADAPTIVE_TIMEOUT_MAX", value)
+			err := LoadConfig().Validate()
+			if (err != nil) != wantErr {
+				t.Fatalf("Validate() = %v, wantErr %v", err, wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: cos_backup.go
+// Description: 覆盖上传 data.json 之前，在 COS 端按日期复制一份备份，并按保留天数清理旧备份
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// cosBackupKey 根据对象 key 和日期生成备份 key
+//
+// Description:
+//
+//	folder/data.json => folder/backup/data-2025-06-01.json
+func cosBackupKey(key string, date time.Time) string {
+	dir, file := path.Split(key)
+	ext := path.Ext(file)
+	name := strings.TrimSuffix(file, ext)
+	return path.Join(dir, "backup", fmt.Sprintf("%s-%s%s", name, date.Format("2006-01-02"), ext))
+}
+
+// backupCosObject 在覆盖上传之前，将 COS 中已有的对象复制到按日期命名的备份 key
+//
+// Description:
+//
+//	若原对象不存在（首次运行）则直接跳过；当天的备份已存在时不再复制，
+//	保留的是当天第一次覆盖前的内容（即前一天最终发布的版本），不会被当天后续的运行覆盖
+//	备份完成后会调用 cleanCosBackups 删除超过 retentionDays 天的旧备份
+//
+// Parameters:
+//   - dataURL       : data.json 在 COS 中的完整地址
+//   - retentionDays : 备份保留天数，<= 0 表示不清理
+func backupCosObject(ctx context.Context, secretID, secretKey, dataURL string, retentionDays int) error {
+	client, key, err := newCosClient(secretID, secretKey, dataURL)
+	if err != nil {
+		return err
+	}
+
+	exists, err := client.Object.IsExist(ctx, key)
+	if err != nil {
+		return wrapErrorf(err, "检查COS对象是否存在失败: %s", key)
+	}
+	if !exists {
+		return nil
+	}
+
+	backupKey := cosBackupKey(key, time.Now())
+	backedUp, err := client.Object.IsExist(ctx, backupKey)
+	if err != nil {
+		return wrapErrorf(err, "检查COS对象是否存在失败: %s", backupKey)
+	}
+	if !backedUp {
+		// 复制源地址格式为 <bucket>.cos.<region>.myqcloud.com/<key>
+		sourceURL := client.BaseURL.BucketURL.Host + "/" + key
+		if _, _, err := client.Object.Copy(ctx, backupKey, sourceURL, nil); err != nil {
+			return wrapErrorf(err, "备份COS对象失败: %s => %s", key, backupKey)
+		}
+		fmt.Printf("[INFO] 已备份 %s => %s\n", key, backupKey)
+	}
+
+	if retentionDays <= 0 {
+		return nil
+	}
+	return cleanCosBackups(ctx, client, key, retentionDays)
+}
+
+// cleanCosBackups 删除超过保留天数的备份对象
+//
+// Description:
+//
+//	列出 backup/ 目录下与当前对象同名前缀的备份，从 key 中解析日期，早于保留期限的予以删除
+func cleanCosBackups(ctx context.Context, client *cos.Client, key string, retentionDays int) error {
+	dir, file := path.Split(key)
+	ext := path.Ext(file)
+	prefix := path.Join(dir, "backup", strings.TrimSuffix(file, ext)+"-")
+	deadline := time.Now().AddDate(0, 0, -retentionDays)
+
+	marker := ""
+	for {
+		result, _, err := client.Bucket.Get(ctx, &cos.BucketGetOptions{
+			Prefix: prefix,
+			Marker: marker,
+		})
+		if err != nil {
+			return wrapErrorf(err, "列出COS备份失败: %s", prefix)
+		}
+
+		for _, obj := range result.Contents {
+			dateStr := strings.TrimSuffix(strings.TrimPrefix(obj.Key, prefix), ext)
+			t, err := time.Parse("2006-01-02", dateStr)
+			if err != nil || !t.Before(deadline) {
+				continue
+			}
+			if _, err := client.Object.Delete(ctx, obj.Key); err != nil {
+				fmt.Printf("[WARN] 删除旧备份 %s 失败: %v\n", obj.Key, err)
+			} else {
+				fmt.Printf("[INFO] 已删除旧备份 %s\n", obj.Key)
+			}
+		}
+
+		if !result.IsTruncated {
+			return nil
+		}
+		marker = result.NextMarker
+	}
+}
@@ -0,0 +1,53 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: cos_backup_test.go
+// Description: COS 备份的测试，使用 httptest 服务器模拟 COS 的 HEAD 与复制接口
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackupCosObjectOncePerDay(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string]bool{"/lhasaRSS/data.json": true}
+	copies := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodHead:
+			if !objects[r.URL.Path] {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case http.MethodPut:
+			if r.Header.Get("x-cos-copy-source") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			copies++
+			objects[r.URL.Path] = true
+			w.Write([]byte(`<CopyObjectResult><ETag>"x"</ETag></CopyObjectResult>`))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	for range 3 {
+		if err := backupCosObject(context.Background(), "id", "key", server.URL+"/lhasaRSS/data.json", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if copies != 1 {
+		t.Errorf("同一天运行 3 次复制了 %d 次, want 1（保留当天第一次覆盖前的内容）", copies)
+	}
+	if !objects["/"+cosBackupKey("lhasaRSS/data.json", time.Now())] {
+		t.Error("未生成当天的备份")
+	}
+}
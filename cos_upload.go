@@ -8,7 +8,9 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -18,12 +20,24 @@ import (
 	"github.com/tencentyun/cos-go-sdk-v5"
 )
 
-// uploadToCos 使用cos-go-sdk-v5将data.json覆盖上传到指定Bucket
-func uploadToCos(ctx context.Context, secretID, secretKey, dataURL string, data []byte) error {
+// cosUploadOptions 上传到 COS 时设置的对象元数据
+type cosUploadOptions struct {
+	ContentType  string // 如 "application/json; charset=utf-8"
+	CacheControl string // 如 "max-age=300"，为空则不设置
+	Gzip         bool   // 是否以 gzip 压缩后上传，并设置 Content-Encoding: gzip
+}
+
+// newCosClient 根据对象地址创建 COS 客户端，并返回对象 key
+//
+// Description:
+//
+//	dataURL 形如 https://<bucket>.cos.<region>.myqcloud.com/folder/data.json，
+//	其 Scheme 与 Host 作为 BucketURL，路径去掉开头的斜杠后作为对象 key
+func newCosClient(secretID, secretKey, dataURL string) (*cos.Client, string, error) {
 	u, err := url.Parse(dataURL)
 	if err != nil {
 		// 如果 dataURL 无法被正常解析，这里会返回一个带有文件名和行号的包装错误
-		return wrapErrorf(err, "解析dataURL失败: %s", dataURL)
+		return nil, "", wrapErrorf(err, "解析dataURL失败: %s", dataURL)
 	}
 	// 创建COS的BaseURL，主要作用是设定BucketURL的Scheme与Host
 	baseURL := &cos.BaseURL{
@@ -41,9 +55,39 @@ func uploadToCos(ctx context.Context, secretID, secretKey, dataURL string, data
 	})
 	// 去掉路径开头的斜杠，得到对象名 key，例如 /folder/data.json => folder/data.json
 	key := strings.TrimPrefix(u.Path, "/")
+	return client, key, nil
+}
+
+// uploadToCos 使用cos-go-sdk-v5将data.json覆盖上传到指定Bucket
+//
+// Description:
+//
+//	上传时会设置 Content-Type、Cache-Control 等对象元数据，使浏览器和 CDN 能正确识别文件；
+//	当 opts.Gzip 为 true 时，会先进行 gzip 压缩，并设置 Content-Encoding: gzip
+func uploadToCos(ctx context.Context, secretID, secretKey, dataURL string, data []byte, opts cosUploadOptions) error {
+	client, key, err := newCosClient(secretID, secretKey, dataURL)
+	if err != nil {
+		return err
+	}
+
+	headerOpts := &cos.ObjectPutHeaderOptions{
+		ContentType:  opts.ContentType,
+		CacheControl: opts.CacheControl,
+	}
+	body := data
+	if opts.Gzip {
+		compressed, err := gzipBytes(data)
+		if err != nil {
+			return wrapErrorf(err, "gzip压缩失败")
+		}
+		body = compressed
+		headerOpts.ContentEncoding = "gzip"
+	}
 
 	// 调用 Put 接口将 data 的内容上传到 COS
-	_, err = client.Object.Put(ctx, key, strings.NewReader(string(data)), nil)
+	_, err = client.Object.Put(ctx, key, bytes.NewReader(body), &cos.ObjectPutOptions{
+		ObjectPutHeaderOptions: headerOpts,
+	})
 	if err != nil {
 		return wrapErrorf(err, "上传至COS失败")
 	}
@@ -52,12 +96,16 @@ func uploadToCos(ctx context.Context, secretID, secretKey, dataURL string, data
 
 // getCosFileContent fetches the content of a file from a given HTTP URL (typically a COS URL).
 // Returns nil, nil if the file is not found (HTTP 404).
+// 401/403 wraps ErrStorageForbidden, network errors, 429 and 5xx wrap ErrStorageTransient.
 func getCosFileContent(ctx context.Context, dataURL string) ([]byte, error) {
-	// Simpler version, matching fetchRSSLinksFromHTTP. Context is for consistency.
-	resp, err := http.Get(dataURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", dataURL, nil)
 	if err != nil {
 		return nil, wrapErrorf(err, "无法获取COS文件: %s", dataURL)
 	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, wrapErrorf(fmt.Errorf("%w: %v", ErrStorageTransient, err), "无法获取COS文件: %s", dataURL)
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
@@ -65,10 +113,14 @@ func getCosFileContent(ctx context.Context, dataURL string) ([]byte, error) {
 	}
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body) // Read body for more detailed error
-		return nil, wrapErrorf(
-			fmt.Errorf("HTTP状态码: %d %s, Body: %s", resp.StatusCode, http.StatusText(resp.StatusCode), string(bodyBytes)),
-			"获取COS文件失败: %s", dataURL,
-		)
+		statusErr := fmt.Errorf("HTTP状态码: %d %s, Body: %s", resp.StatusCode, http.StatusText(resp.StatusCode), string(bodyBytes))
+		switch classifyCosError(&cos.Response{Response: resp}, nil) {
+		case http.StatusForbidden:
+			statusErr = fmt.Errorf("%w: %v", ErrStorageForbidden, statusErr)
+		case http.StatusServiceUnavailable:
+			statusErr = fmt.Errorf("%w: %v", ErrStorageTransient, statusErr)
+		}
+		return nil, wrapErrorf(statusErr, "获取COS文件失败: %s", dataURL)
 	}
 
 	data, err := io.ReadAll(resp.Body)
@@ -77,3 +129,82 @@ func getCosFileContent(ctx context.Context, dataURL string) ([]byte, error) {
 	}
 	return data, nil
 }
+
+// readCosObject 使用带签名的 SDK 读取 COS 对象，私有存储桶同样可用
+//
+// Description:
+//
+//	先以 HEAD 确认对象是否存在及是否有权限，再 GET 读取内容，按结果区分:
+//	对象不存在时返回 nil, nil；无权限（401/403）时返回包装了 ErrStorageForbidden 的错误；
+//	网络错误、429 或 5xx 按重试策略重试后仍失败时返回包装了 ErrStorageTransient 的错误
+func readCosObject(ctx context.Context, secretID, secretKey, dataURL string, retry retryStrategy) ([]byte, error) {
+	client, key, err := newCosClient(secretID, secretKey, dataURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	notFound := false
+	err = withRetry(ctx, retry, func(int) error {
+		resp, err := client.Object.Head(ctx, key, nil)
+		if err == nil {
+			resp, err = client.Object.Get(ctx, key, nil)
+		}
+		if err == nil {
+			var readErr error
+			data, readErr = io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if readErr == nil {
+				return nil
+			}
+			err = readErr
+		}
+		switch classifyCosError(resp, err) {
+		case http.StatusNotFound:
+			notFound = true
+			return nil
+		case http.StatusForbidden:
+			return noRetry(wrapErrorf(fmt.Errorf("%w: %v", ErrStorageForbidden, err), "无权读取COS文件: %s", dataURL))
+		case 0:
+			return noRetry(wrapErrorf(err, "获取COS文件失败: %s", dataURL))
+		}
+		return fmt.Errorf("%w: %v", ErrStorageTransient, err)
+	}, func(attempt int, err error) {
+		fmt.Printf("[WARN] 读取COS文件失败 (%d/%d): %s: %v\n", attempt, retry.attempts(), dataURL, err)
+	})
+	switch {
+	case notFound:
+		return nil, nil
+	case errors.Is(err, ErrStorageTransient):
+		return nil, wrapErrorf(err, "获取COS文件失败: %s", dataURL)
+	case err != nil:
+		return nil, err
+	}
+	return data, nil
+}
+
+// classifyCosError 将 COS 请求错误归类
+//
+// Returns:
+//   - int : http.StatusNotFound 表示对象不存在，http.StatusForbidden 表示无权限（含 401），
+//     http.StatusServiceUnavailable 表示可重试的临时错误（网络错误、读取中断、429、5xx），0 表示其他错误
+func classifyCosError(resp *cos.Response, err error) int {
+	if resp == nil || resp.Response == nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return 0
+		}
+		return http.StatusServiceUnavailable
+	}
+	switch code := resp.StatusCode; {
+	case code == http.StatusNotFound:
+		return http.StatusNotFound
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return http.StatusForbidden
+	case code == http.StatusTooManyRequests || code >= 500:
+		return http.StatusServiceUnavailable
+	case code == http.StatusOK:
+		// 响应正常但读取内容时中断
+		return http.StatusServiceUnavailable
+	}
+	return 0
+}
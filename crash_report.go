@@ -0,0 +1,82 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: crash_report.go
+// Description: panic 恢复与崩溃报告，单个订阅或流程阶段的 panic 不会中断整次运行，堆栈写入日志并通过通知发送
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// maxNotifiedStackLines 通知中每份堆栈最多保留的行数，完整堆栈只写入日志
+const maxNotifiedStackLines = 30
+
+// crashReport 一次被恢复的 panic
+type crashReport struct {
+	Scope string // 发生 panic 的位置（如 "抓取 https://example.com/feed"）
+	Value string // panic 的值
+	Stack string // 堆栈
+}
+
+// crashReports 本次运行中被恢复的所有 panic
+var crashReports struct {
+	mu    sync.Mutex
+	items []crashReport
+}
+
+// recoverPanic 恢复当前协程中的 panic，记录堆栈后调用 onPanic
+//
+// Description:
+//
+//	必须以 defer recoverPanic(...) 的形式直接延迟调用，recover 才能生效；
+//	堆栈写入运行日志（随日志提交到仓库），并在运行结束时由 notifyCrashReports 汇总发送通知
+//
+// Parameters:
+//   - scope   : 发生 panic 的位置说明
+//   - onPanic : panic 被恢复后的处理（如将订阅记为失败），可为 nil
+func recoverPanic(scope string, onPanic func(err error)) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	report := crashReport{Scope: scope, Value: fmt.Sprint(r), Stack: string(debug.Stack())}
+
+	crashReports.mu.Lock()
+	crashReports.items = append(crashReports.items, report)
+	crashReports.mu.Unlock()
+
+	msg := trf("%s 发生 panic: %s", scope, report.Value)
+	fmt.Printf("[ERROR] %s\n%s", msg, report.Stack)
+	appendLog("[ERROR] " + msg + "\n" + report.Stack)
+
+	if onPanic != nil {
+		onPanic(fmt.Errorf("panic: %s", report.Value))
+	}
+}
+
+// notifyCrashReports 将本次运行中被恢复的 panic 汇总为一条通知发送，没有 panic 时不发送；
+// 发送后清空记录，同一进程中的下一次运行（下一个配置方案）不会重复发送
+func notifyCrashReports(ctx context.Context, cfg *Config) error {
+	crashReports.mu.Lock()
+	reports := crashReports.items
+	crashReports.items = nil
+	crashReports.mu.Unlock()
+	if len(reports) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	for _, r := range reports {
+		fmt.Fprintf(&sb, "%s: %s\n", r.Scope, r.Value)
+		lines := strings.Split(strings.TrimSpace(r.Stack), "\n")
+		if len(lines) > maxNotifiedStackLines {
+			lines = append(lines[:maxNotifiedStackLines], "...")
+		}
+		sb.WriteString(strings.Join(lines, "\n") + "\n\n")
+	}
+	return sendNotification(ctx, cfg, trf("本次运行发生 %d 次 panic", len(reports)), sb.String())
+}
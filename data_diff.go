@@ -0,0 +1,316 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: data_diff.go
+// Description: diff 子命令，比较两份 data.json（本地文件、COS 地址、仓库内路径或当前配置的 DATA），
+// 列出新增、删除、修改的文章与博客，文章是否修改的判断与 areArticlesIdentical 一致（articleToKey）
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// diffRemoteSource diff 子命令中表示当前配置的 DATA（按 SAVE_TARGET 读取）的参数
+const diffRemoteSource = "remote"
+
+// articleChange 链接相同但内容不同的一篇文章
+type articleChange struct {
+	Link   string   `json:"link"`
+	Old    Article  `json:"old"`
+	New    Article  `json:"new"`
+	Fields []string `json:"fields"` // 发生变化的字段
+}
+
+// blogRename 同一订阅的博客名称变化
+type blogRename struct {
+	Feed string `json:"feed"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// dataDiff 两份 data.json 的差异
+type dataDiff struct {
+	Added   []Article       `json:"added"`
+	Removed []Article       `json:"removed"`
+	Changed []articleChange `json:"changed"`
+
+	BlogsAdded   []string     `json:"blogs_added"`   // 新出现的博客（名称 + RSS 地址）
+	BlogsRemoved []string     `json:"blogs_removed"` // 不再出现的博客
+	BlogsRenamed []blogRename `json:"blogs_renamed"`
+
+	Identical bool `json:"identical"` // 与 areArticlesIdentical 的结果一致
+}
+
+// diffArticles 比较两组文章
+//
+// Description:
+//
+//	以文章链接对应新旧文章，articleToKey 不同的视为修改，只在一侧出现的视为新增或删除；
+//	博客按 RSS 地址（固定数据按博客名称）区分，同一订阅的名称变化记为改名
+func diffArticles(previous, current []Article) dataDiff {
+	d := dataDiff{
+		Added:        []Article{},
+		Removed:      []Article{},
+		Changed:      []articleChange{},
+		BlogsAdded:   []string{},
+		BlogsRemoved: []string{},
+		BlogsRenamed: []blogRename{},
+		Identical:    areArticlesIdentical(previous, current),
+	}
+
+	prevByLink := make(map[string]Article, len(previous))
+	for _, p := range previous {
+		prevByLink[p.Link] = p
+	}
+	currLinks := make(map[string]bool, len(current))
+	for _, a := range current {
+		currLinks[a.Link] = true
+		p, ok := prevByLink[a.Link]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, a)
+		case articleToKey(p) != articleToKey(a):
+			d.Changed = append(d.Changed, articleChange{Link: a.Link, Old: p, New: a, Fields: changedArticleFields(p, a)})
+		}
+	}
+	for _, p := range previous {
+		if !currLinks[p.Link] {
+			d.Removed = append(d.Removed, p)
+		}
+	}
+
+	prevBlogs, prevOrder := diffBlogs(previous)
+	currBlogs, currOrder := diffBlogs(current)
+	for _, key := range currOrder {
+		old, ok := prevBlogs[key]
+		switch {
+		case !ok:
+			d.BlogsAdded = append(d.BlogsAdded, blogLabel(currBlogs[key]))
+		case old.BlogName != currBlogs[key].BlogName:
+			d.BlogsRenamed = append(d.BlogsRenamed, blogRename{Feed: old.Feed, Old: old.BlogName, New: currBlogs[key].BlogName})
+		}
+	}
+	for _, key := range prevOrder {
+		if _, ok := currBlogs[key]; !ok {
+			d.BlogsRemoved = append(d.BlogsRemoved, blogLabel(prevBlogs[key]))
+		}
+	}
+	return d
+}
+
+// changedArticleFields 返回两篇文章中不同的字段，字段与 articleToKey 包含的一致
+func changedArticleFields(prev, curr Article) []string {
+	var fields []string
+	check := func(name string, changed bool) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+	check("blog_name", prev.BlogName != curr.BlogName)
+	check("title", prev.Title != curr.Title)
+	check("group", prev.Group != curr.Group)
+	check("pinned", prev.Pinned != curr.Pinned)
+	check("dead", prev.Dead != curr.Dead)
+	check("is_updated", prev.IsUpdated != curr.IsUpdated)
+	check("retired", prev.Retired != curr.Retired)
+	check("archived", prev.Archived != curr.Archived)
+	check("summary", prev.Summary != curr.Summary)
+	check("topic", prev.Topic != curr.Topic)
+	check("translated_title", prev.TranslatedTitle != curr.TranslatedTitle)
+	return fields
+}
+
+// diffBlogs 按博客汇总文章，每个博客取第一篇文章作为代表，并返回首次出现的顺序
+func diffBlogs(articles []Article) (map[string]Article, []string) {
+	blogs := make(map[string]Article)
+	var order []string
+	for _, a := range articles {
+		key := "name:" + a.BlogName
+		if a.Feed != "" {
+			key = urlnorm.Key(a.Feed)
+		}
+		if _, ok := blogs[key]; !ok {
+			blogs[key] = a
+			order = append(order, key)
+		}
+	}
+	return blogs, order
+}
+
+// blogLabel 博客在差异中的显示文字
+func blogLabel(a Article) string {
+	if a.Feed == "" {
+		return a.BlogName
+	}
+	return a.BlogName + " (" + a.Feed + ")"
+}
+
+// loadDiffSource 读取 diff 子命令的一个参数对应的 data.json
+//
+// Description:
+//
+//	remote 表示当前配置的 DATA（按 SAVE_TARGET 读取，支持 KV 与 .gz）；
+//	其他参数依次尝试本地文件、HTTP(S) 地址（COS 对象）与 GitHub 仓库内路径，.gz 结尾时自动解压
+func loadDiffSource(ctx context.Context, cfg *Config, src string) ([]Article, error) {
+	var data []byte
+	var err error
+	if src == diffRemoteSource {
+		data, err = readStoredFile(ctx, cfg, cfg.DataURL)
+		src = cfg.DataURL
+	} else {
+		data, err = os.ReadFile(src)
+		if os.IsNotExist(err) {
+			data, err = readBackupLocation(ctx, cfg, src)
+		}
+		if err == nil && isGzipTarget(src) && len(data) > 0 {
+			data, err = decodeContent("gzip", data)
+		}
+	}
+	if err != nil {
+		return nil, wrapErrorf(err, "读取 %s 失败", src)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%s 不存在", src)
+	}
+	var all AllData
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, wrapErrorf(err, "解析 %s 失败", src)
+	}
+	return all.Items, nil
+}
+
+// runDiffCommand 比较子命令
+//
+// Description:
+//
+//	diff [-json] <旧 data.json> <新 data.json>
+//	参数可以是本地文件、COS 地址、仓库内路径，或 remote（当前配置的 DATA），
+//	例如 diff remote ./data.json 可在上传前查看本地生成的结果与线上数据的差异；
+//	与 diff(1) 一致，没有差异时退出码为 0，有差异时为 1，出错时为 2
+func runDiffCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "以 JSON 格式输出差异")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "用法: diff [-json] <旧 data.json> <新 data.json>  (参数可为本地文件、COS 地址、仓库内路径或 remote)")
+		return 2
+	}
+
+	cfg := LoadConfig()
+	previous, err := loadDiffSource(ctx, cfg, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 2
+	}
+	current, err := loadDiffSource(ctx, cfg, fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 2
+	}
+
+	d := diffArticles(previous, current)
+	if *asJSON {
+		out, err := marshalJSON(d, "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+			return 2
+		}
+		fmt.Println(string(out))
+	} else {
+		printDataDiff(fs.Arg(0), fs.Arg(1), len(previous), len(current), d)
+	}
+	if d.Identical {
+		return 0
+	}
+	return 1
+}
+
+// printDataDiff 以便于阅读的格式输出差异
+func printDataDiff(oldSrc, newSrc string, oldCount, newCount int, d dataDiff) {
+	fmt.Printf("--- %s (%d 篇文章)\n+++ %s (%d 篇文章)\n", oldSrc, oldCount, newSrc, newCount)
+	if d.Identical {
+		fmt.Println("没有差异")
+		return
+	}
+	if len(d.Added) > 0 {
+		fmt.Printf("\n新增文章 (%d):\n", len(d.Added))
+		for _, a := range d.Added {
+			fmt.Printf("  + [%s] %s %s\n", a.BlogName, a.Title, a.Link)
+		}
+	}
+	if len(d.Removed) > 0 {
+		fmt.Printf("\n删除文章 (%d):\n", len(d.Removed))
+		for _, a := range d.Removed {
+			fmt.Printf("  - [%s] %s %s\n", a.BlogName, a.Title, a.Link)
+		}
+	}
+	if len(d.Changed) > 0 {
+		fmt.Printf("\n修改文章 (%d):\n", len(d.Changed))
+		for _, c := range d.Changed {
+			fmt.Printf("  ~ [%s] %s %s\n", c.New.BlogName, c.New.Title, c.Link)
+			for _, f := range c.Fields {
+				oldVal, newVal := articleFieldValue(c.Old, f), articleFieldValue(c.New, f)
+				fmt.Printf("      %s: %s -> %s\n", f, oldVal, newVal)
+			}
+		}
+	}
+	if len(d.BlogsAdded) > 0 {
+		fmt.Printf("\n新增博客 (%d):\n", len(d.BlogsAdded))
+		for _, b := range d.BlogsAdded {
+			fmt.Printf("  + %s\n", b)
+		}
+	}
+	if len(d.BlogsRemoved) > 0 {
+		fmt.Printf("\n消失的博客 (%d):\n", len(d.BlogsRemoved))
+		for _, b := range d.BlogsRemoved {
+			fmt.Printf("  - %s\n", b)
+		}
+	}
+	if len(d.BlogsRenamed) > 0 {
+		fmt.Printf("\n博客改名 (%d):\n", len(d.BlogsRenamed))
+		for _, r := range d.BlogsRenamed {
+			fmt.Printf("  ~ %s -> %s (%s)\n", r.Old, r.New, r.Feed)
+		}
+	}
+	if len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 {
+		// 链接相同的文章内容也相同，差异来自重复的条目
+		fmt.Println("\n文章相同, 但重复条目的数量不同")
+	}
+}
+
+// articleFieldValue 返回 changedArticleFields 中字段的显示值
+func articleFieldValue(a Article, field string) string {
+	switch field {
+	case "blog_name":
+		return strconv.Quote(a.BlogName)
+	case "title":
+		return strconv.Quote(a.Title)
+	case "group":
+		return strconv.Quote(a.Group)
+	case "pinned":
+		return strconv.FormatBool(a.Pinned)
+	case "dead":
+		return strconv.FormatBool(a.Dead)
+	case "is_updated":
+		return strconv.FormatBool(a.IsUpdated)
+	case "retired":
+		return strconv.FormatBool(a.Retired)
+	case "archived":
+		return strconv.FormatBool(a.Archived)
+	case "summary":
+		return strconv.Quote(a.Summary)
+	case "topic":
+		return strconv.Quote(a.Topic)
+	case "translated_title":
+		return strconv.Quote(a.TranslatedTitle)
+	}
+	return ""
+}
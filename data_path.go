@@ -0,0 +1,59 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: data_path.go
+// Description: data.json 保存位置的模板（如 data/{{date}}.json），以及与固定位置的 data.json 同时写入的
+// 按日期快照（DATA_SNAPSHOT），无需单独的归档功能即可积累历史数据
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// expandDataPath 展开保存位置中的模板变量
+//
+// Description:
+//
+//	{{date}} => 2025-06-01，{{year}} => 2025，{{month}} => 06，{{day}} => 01，{{time}} => 150405，
+//	按进程时区（TZ）计算；不含模板变量时原样返回
+func expandDataPath(tmpl string, now time.Time) string {
+	if !strings.Contains(tmpl, "{{") {
+		return tmpl
+	}
+	return strings.NewReplacer(
+		"{{date}}", now.Format("2006-01-02"),
+		"{{year}}", now.Format("2006"),
+		"{{month}}", now.Format("01"),
+		"{{day}}", now.Format("02"),
+		"{{time}}", now.Format("150405"),
+	).Replace(tmpl)
+}
+
+// validateDataPath 检查展开后的保存位置中是否仍有不支持的模板变量
+func validateDataPath(name, expanded string) error {
+	if i := strings.Index(expanded, "{{"); i >= 0 {
+		return fmt.Errorf("%s 中的模板变量无效: %s (可选: {{date}}, {{year}}, {{month}}, {{day}}, {{time}})", name, expanded[i:])
+	}
+	return nil
+}
+
+// dataSnapshotTarget 返回本次运行的快照保存位置，未配置 DATA_SNAPSHOT 时返回空
+//
+// Description:
+//
+//	只写文件名（不含 /）时保存在 data.json 同目录，如 snapshots-{{date}}.json；
+//	与 data.json 相同时（模板展开后恰好一致）不重复写入
+func dataSnapshotTarget(cfg *Config, now time.Time) string {
+	if cfg.DataSnapshot == "" {
+		return ""
+	}
+	target := expandDataPath(cfg.DataSnapshot, now)
+	if !strings.Contains(target, "/") {
+		target = siblingPath(cfg.DataURL, target)
+	}
+	if target == cfg.DataURL {
+		return ""
+	}
+	return target
+}
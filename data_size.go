@@ -0,0 +1,61 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: data_size.go
+// Description: data.json 体积与增长检查，前端每次访问页面都要加载该文件，体积失控会拖慢访客
+
+package main
+
+import "fmt"
+
+// checkDataSize 检查 data.json 的体积及相对上次的增长，异常时写入 problems["dataSize"]
+//
+// Parameters:
+//   - size        : 本次生成的 data.json 字节数
+//   - prevSize    : 上次保存的 data.json 字节数，0 表示没有上次的数据
+//   - maxBytes    : 体积告警阈值，<= 0 表示不检查
+//   - growthRatio : 单次增长比例告警阈值（0.5 表示增长 50%），<= 0 表示不检查
+//   - problems    : 问题统计
+func checkDataSize(size, prevSize, maxBytes int, growthRatio float64, problems map[string][]string) {
+	if maxBytes > 0 && size > maxBytes {
+		problems["dataSize"] = append(problems["dataSize"],
+			trf("data.json 为 %s, 超过告警阈值 %s", formatBytes(size), formatBytes(maxBytes)))
+	}
+	if growthRatio > 0 && prevSize > 0 {
+		if growth := float64(size-prevSize) / float64(prevSize); growth > growthRatio {
+			problems["dataSize"] = append(problems["dataSize"],
+				trf("data.json 比上次增长 %.0f%% (%s → %s)", growth*100, formatBytes(prevSize), formatBytes(size)))
+		}
+	}
+	fmt.Printf("[INFO] data.json 大小: %s (上次: %s)\n", formatBytes(size), formatBytes(prevSize))
+}
+
+// checkShrinkage 检查文章数相对上次是否骤减，如 RSS 列表文件被截断时 data.json 会丢失大部分文章
+//
+// Parameters:
+//   - prevCount : 上次 data.json 中的文章数，0 表示没有上次的数据（不检查）
+//   - count     : 本次生成的文章数
+//   - percent   : 减少超过该百分比时视为骤减，<= 0 表示不检查
+//
+// Returns:
+//   - string : 骤减时的说明，未骤减时为空
+func checkShrinkage(prevCount, count int, percent float64) string {
+	if percent <= 0 || prevCount <= 0 || count >= prevCount {
+		return ""
+	}
+	drop := float64(prevCount-count) / float64(prevCount) * 100
+	if drop <= percent {
+		return ""
+	}
+	return trf("文章数从 %d 篇减少到 %d 篇 (-%.0f%%), 超过阈值 %.0f%%", prevCount, count, drop, percent)
+}
+
+// formatBytes 以 B/KB/MB 格式化字节数
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
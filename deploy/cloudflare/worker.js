@@ -0,0 +1,54 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: deploy/cloudflare/worker.js
+// Description: lhasaRSS 的 Cloudflare Worker：从 KV 返回 data.json 等输出文件，
+// 并通过 Cron 触发器定时调用 GitHub Actions 工作流执行抓取（SAVE_TARGET=KV）
+
+const CONTENT_TYPES = {
+  ".json": "application/json; charset=utf-8",
+  ".xml": "application/rss+xml; charset=utf-8",
+  ".js": "application/javascript; charset=utf-8",
+  ".jsonp": "application/javascript; charset=utf-8",
+};
+
+function contentType(key) {
+  const dot = key.lastIndexOf(".");
+  return CONTENT_TYPES[dot >= 0 ? key.slice(dot) : ""] || CONTENT_TYPES[".json"];
+}
+
+export default {
+  // 按请求路径读取 KV 中的同名键，如 /data.json => data.json
+  async fetch(request, env) {
+    if (request.method !== "GET" && request.method !== "HEAD") {
+      return new Response("Method Not Allowed", { status: 405 });
+    }
+    const key = decodeURIComponent(new URL(request.url).pathname.replace(/^\/+/, "")) || "data.json";
+    const value = await env.LHASA_KV.get(key, { type: "stream", cacheTtl: 60 });
+    if (value === null) {
+      return new Response("Not Found", { status: 404 });
+    }
+    return new Response(request.method === "HEAD" ? null : value, {
+      headers: {
+        "Content-Type": contentType(key),
+        "Cache-Control": env.CACHE_CONTROL || "max-age=300",
+        "Access-Control-Allow-Origin": env.ALLOW_ORIGIN || "*",
+      },
+    });
+  },
+
+  // Worker 无法直接运行 Go 程序，定时触发 workflow_dispatch，由 GitHub Actions 抓取后写回 KV
+  async scheduled(event, env, ctx) {
+    const api = `https://api.github.com/repos/${env.GITHUB_REPOSITORY}/actions/workflows/${env.GITHUB_WORKFLOW}/dispatches`;
+    const resp = await fetch(api, {
+      method: "POST",
+      headers: {
+        Authorization: `Bearer ${env.GITHUB_TOKEN}`,
+        Accept: "application/vnd.github+json",
+        "User-Agent": "lhasaRSS-worker",
+      },
+      body: JSON.stringify({ ref: env.GITHUB_REF || "main" }),
+    });
+    if (resp.status !== 204) {
+      throw new Error(`触发工作流失败, status: ${resp.status}, body: ${await resp.text()}`);
+    }
+  },
+};
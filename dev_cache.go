@@ -0,0 +1,87 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: dev_cache.go
+// Description: 本地开发用的 HTTP 缓存（--cache-dir），GET 响应缓存到磁盘，反复调试输出格式时无需重新抓取所有订阅
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// devCacheTransport 将 GET 响应缓存到磁盘的 RoundTripper
+//
+// Description:
+//
+//	与 HTTP 录制/回放不同，缓存中没有的请求会正常访问网络并写入缓存，已缓存的请求直接返回缓存内容，
+//	不区分请求次数；带 Authorization 头的请求（GitHub API、COS 签名请求）和非 GET 请求不缓存，
+//	429 与 5xx 响应也不缓存，下次运行时会重新请求
+type devCacheTransport struct {
+	dir  string
+	base http.RoundTripper
+}
+
+// activeDevCache 当前生效的开发缓存，nil 表示未开启
+var activeDevCache *devCacheTransport
+
+// setupDevCache 开启开发缓存并替换 http.DefaultTransport
+func setupDevCache(dir string) error {
+	if dir == "" || activeDevCache != nil {
+		return nil
+	}
+	if activeReplay != nil {
+		return fmt.Errorf("--cache-dir 不能与 HTTP_RECORD、HTTP_REPLAY 同时使用")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return wrapErrorf(err, "创建缓存目录失败: %s", dir)
+	}
+	activeDevCache = &devCacheTransport{dir: dir}
+	http.DefaultTransport = wrapTransport(http.DefaultTransport)
+	fmt.Printf("[INFO] 已开启开发缓存, GET 响应缓存在 %s\n", dir)
+	return nil
+}
+
+// cachePath 返回请求对应的缓存文件路径
+func (t *devCacheTransport) cachePath(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String()))
+	return filepath.Join(t.dir, hex.EncodeToString(sum[:8])+".http")
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *devCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	path := t.cachePath(req)
+	if raw, err := os.ReadFile(path); err == nil {
+		if resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), req); err == nil {
+			return resp, nil
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return resp, nil
+	}
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+	if err := saveRawResponse(path, resp, raw); err != nil {
+		fmt.Printf("[WARN] 保存开发缓存失败: %s: %v\n", req.URL, err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,128 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: doctor.go
+// Description: doctor 子命令，逐项诊断运行环境（环境变量、Token 权限、COS 存储桶、RSS 列表）并给出修复建议
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// doctorCheck doctor 子命令中的一项检查
+type doctorCheck struct {
+	Name string                                       // 检查项名称
+	Fix  string                                       // 检查失败时的修复建议
+	Skip func(cfg *Config) string                     // 返回非空字符串表示跳过该项及原因，可为 nil
+	Run  func(ctx context.Context, cfg *Config) error // 执行检查
+}
+
+// doctorChecks 按顺序执行的所有检查项
+var doctorChecks = []doctorCheck{
+	{
+		Name: "环境变量",
+		Fix:  "按 README 的环境变量表补充缺失项；GitHub Actions 中需在 workflow 的 env 里引用对应的 secrets",
+		Run: func(ctx context.Context, cfg *Config) error {
+			return cfg.Validate()
+		},
+	},
+	{
+		Name: "GitHub Token 权限",
+		Fix: "确认 TOKEN 未过期且 NAME/REPOSITORY 指向正确的仓库；经典 Token 需勾选 repo（公开仓库可为 public_repo），" +
+			"细粒度 Token 需授予该仓库 Contents 读写权限",
+		Skip: func(cfg *Config) string {
+			if cfg.GitHubToken == "" {
+				return "未设置 TOKEN"
+			}
+			return ""
+		},
+		Run: func(ctx context.Context, cfg *Config) error {
+			return checkGitHubAccess(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo)
+		},
+	},
+	{
+		Name: "COS 存储桶权限",
+		Fix: "确认 DATA 地址中的存储桶名称与地域正确（形如 https://<bucket>.cos.<region>.myqcloud.com/...），" +
+			"且 TENCENT_CLOUD_SECRET_ID/TENCENT_CLOUD_SECRET_KEY 对应的账号拥有该存储桶的读写权限",
+		Skip: func(cfg *Config) string {
+			if cfg.SaveTarget != "COS" {
+				return "SAVE_TARGET 不是 COS"
+			}
+			return ""
+		},
+		Run: func(ctx context.Context, cfg *Config) error {
+			return checkCosBucket(ctx, cfg.TencentSecretID, cfg.TencentSecretKey, cfg.DataURL)
+		},
+	},
+	{
+		Name: "Cloudflare KV 权限",
+		Fix: "确认 CF_ACCOUNT_ID 与 CF_KV_NAMESPACE_ID 取自 Cloudflare 控制台 Workers KV 页面，" +
+			"CF_API_TOKEN 需授予该账号的 Workers KV Storage 编辑权限",
+		Skip: func(cfg *Config) string {
+			if cfg.SaveTarget != "KV" {
+				return "SAVE_TARGET 不是 KV"
+			}
+			return ""
+		},
+		Run: checkKVAccess,
+	},
+	{
+		Name: "RSS 列表",
+		Fix: "检查 RSS 与 RSS_SOURCE：GITHUB 模式下为仓库内相对路径（如 data/rss.txt），COS 模式需为可公开访问的 HTTP(S) 地址，" +
+			"私有 Gist 需设置 GIST_TOKEN；列表文件每行一个 RSS 地址（或为 OPML），且不能为空",
+		Run: func(ctx context.Context, cfg *Config) error {
+			if err := checkRSSListReachable(ctx, cfg); err != nil {
+				return err
+			}
+			entries, err := fetchRSSLinks(ctx, cfg, nil)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return fmt.Errorf("RSS 列表中没有任何订阅")
+			}
+			fmt.Printf("       共 %d 条订阅\n", len(entries))
+			return nil
+		},
+	},
+}
+
+// runDoctorCommand 自检子命令
+//
+// Description:
+//
+//	依次执行所有检查项（某项失败不影响后续检查），输出每项的结果，失败项附带修复建议；
+//	存在失败项时返回 1
+func runDoctorCommand(ctx context.Context, args []string) int {
+	cfg := LoadConfig()
+	setLanguage(cfg.Lang)
+
+	failed := 0
+	for _, c := range doctorChecks {
+		if c.Skip != nil {
+			if reason := c.Skip(cfg); reason != "" {
+				fmt.Printf("[SKIP] %s（%s）\n", c.Name, reason)
+				continue
+			}
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err := c.Run(checkCtx, cfg)
+		cancel()
+		if err != nil {
+			failed++
+			fmt.Printf("[FAIL] %s: %v\n", c.Name, err)
+			fmt.Printf("       修复建议: %s\n", c.Fix)
+			continue
+		}
+		fmt.Printf("[ OK ] %s\n", c.Name)
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "共 %d 项检查未通过\n", failed)
+		return 1
+	}
+	fmt.Println("所有检查均已通过")
+	return 0
+}
@@ -0,0 +1,100 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: feed_dump.go
+// Description: --dump-dir 诊断输出，保存每个订阅每次尝试的原始响应、清理后的 XML 与解析结果，便于向上游报告解析器问题
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+	"github.com/mmcdole/gofeed"
+)
+
+// feedDumper 将抓取过程中的中间数据写入目录
+//
+// Description:
+//
+//	每个订阅一个子目录 {域名}_{地址哈希}/，其中 url.txt 为 RSS 地址，每次尝试 N 依次写入：
+//	N-headers.txt（状态行与响应头）、N-raw.xml（原始响应体）、N-clean.xml（清理非法字符后实际交给解析器的内容，
+//	严格模式下不存在）、N-result.json（解析出的 Feed 或错误信息）
+type feedDumper struct {
+	dir string
+}
+
+// activeDump 当前生效的诊断输出，nil 表示未开启；所有方法对 nil 安全
+var activeDump *feedDumper
+
+// setupFeedDump 开启诊断输出
+func setupFeedDump(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return wrapErrorf(err, "创建诊断输出目录失败: %s", dir)
+	}
+	activeDump = &feedDumper{dir: dir}
+	fmt.Printf("[INFO] 已开启抓取诊断输出, 保存到 %s\n", dir)
+	return nil
+}
+
+// feedDir 返回订阅对应的子目录，首次使用时创建并写入 url.txt
+func (d *feedDumper) feedDir(rssLink string) string {
+	sum := sha256.Sum256([]byte(rssLink))
+	host := strings.ReplaceAll(urlnorm.Host(rssLink), ":", "_")
+	dir := filepath.Join(d.dir, fmt.Sprintf("%s_%s", host, hex.EncodeToString(sum[:4])))
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0o755); err == nil {
+			os.WriteFile(filepath.Join(dir, "url.txt"), []byte(rssLink+"\n"), 0o644)
+		}
+	}
+	return dir
+}
+
+// write 写入第 attempt 次尝试的一个诊断文件
+func (d *feedDumper) write(rssLink string, attempt int, name string, data []byte) {
+	if d == nil {
+		return
+	}
+	path := filepath.Join(d.feedDir(rssLink), fmt.Sprintf("%d-%s", attempt, name))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		fmt.Printf("[WARN] 写入诊断文件失败: %s: %v\n", path, err)
+	}
+}
+
+// writeHeaders 写入响应的状态行与响应头
+func (d *feedDumper) writeHeaders(rssLink string, attempt int, resp *http.Response) {
+	if d == nil {
+		return
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s\n", resp.Proto, resp.Status)
+	resp.Header.Write(&buf)
+	d.write(rssLink, attempt, "headers.txt", buf.Bytes())
+}
+
+// writeResult 写入解析结果，成功时为解析出的 Feed，失败时为错误信息
+func (d *feedDumper) writeResult(rssLink string, attempt int, strategy string, feed *gofeed.Feed, err error) {
+	if d == nil {
+		return
+	}
+	result := map[string]interface{}{"strategy": strategy}
+	if err != nil {
+		result["error"] = err.Error()
+	} else {
+		result["feed"] = feed
+	}
+	data, mErr := json.MarshalIndent(result, "", "  ")
+	if mErr != nil {
+		data = []byte(fmt.Sprintf("{\"error\": %q}", mErr.Error()))
+	}
+	d.write(rssLink, attempt, "result.json", data)
+}
@@ -0,0 +1,81 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: feed_encoding.go
+// Description: RSS 响应的压缩协商（Accept-Encoding）与解压，支持 gzip、deflate 与 brotli
+
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// feedAcceptEncoding 抓取 RSS 时发送的 Accept-Encoding（FEED_ACCEPT_ENCODING），
+// 为空时由 Go 自动协商 gzip 并透明解压
+var feedAcceptEncoding string
+
+// setAcceptEncoding 按 FEED_ACCEPT_ENCODING 设置请求头
+func setAcceptEncoding(req *http.Request) {
+	if feedAcceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", feedAcceptEncoding)
+	}
+}
+
+// readFeedBody 读取 RSS 响应体，并按 Content-Encoding 解压
+//
+// Description:
+//
+//	部分 CDN 无论请求头如何都只返回 brotli 压缩的内容，Go 的 http 客户端不会自动解压 br，
+//	因此所有未被自动解压的响应都在这里按 Content-Encoding 从后往前逐层解压；
+//	读取失败视为网络错误，解压失败视为解析失败
+func readFeedBody(resp *http.Response) ([]byte, error) {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, classifyNetError(err)
+	}
+	if resp.Uncompressed {
+		return data, nil
+	}
+	encodings := splitList(resp.Header.Get("Content-Encoding"), ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		enc := strings.ToLower(encodings[i])
+		if data, err = decodeContent(enc, data); err != nil {
+			return nil, fmt.Errorf("%w: 解压响应失败 (%s): %w", ErrParse, enc, err)
+		}
+	}
+	return data, nil
+}
+
+// decodeContent 按单个编码解压数据，identity 及无法识别的编码原样返回
+func decodeContent(enc string, data []byte) ([]byte, error) {
+	var r io.Reader
+	switch enc {
+	case "br":
+		r = brotli.NewReader(bytes.NewReader(data))
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	case "deflate":
+		// 规范要求 zlib 格式，但不少服务器直接返回原始 deflate 数据
+		if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+			defer zr.Close()
+			r = zr
+		} else {
+			r = flate.NewReader(bytes.NewReader(data))
+		}
+	default:
+		return data, nil
+	}
+	return io.ReadAll(r)
+}
@@ -0,0 +1,224 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: feed_entry.go
+// Description: RSS 列表条目的解析，每行一个 RSS 地址，可在地址后追加 key=value 形式的单独配置
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// feedEntry RSS 列表中的一个订阅
+//
+// Description:
+//
+//	RSS 列表文件每行格式为 "RSS地址 [key=value ...]"，以 # 开头的行视为注释，例如:
+//	  https://example.com/feed group=技术 pinned=true weight=10 backfill=5 timeout=30 retries=5 backoff=2
+//	  https://example.com/rss name=XXX的碎碎念 note=高中同学
+//	  https://self-signed.example.com/feed insecure=true
+//	  https://old.example.com/feed archived=true
+//	name、note 中的空格需写作 %20
+//	未填写的配置项为零值，抓取时回退到全局配置
+type feedEntry struct {
+	URL      string  `json:"url"`                // RSS 地址
+	Timeout  int     `json:"timeout,omitempty"`  // 单次请求超时（秒），覆盖 HTTP_TIMEOUT
+	Retries  int     `json:"retries,omitempty"`  // 最大尝试次数，覆盖 MAX_RETRIES
+	Backoff  float64 `json:"backoff,omitempty"`  // 首次重试前的等待时间（秒），覆盖 RETRY_BACKOFF
+	Group    string  `json:"group,omitempty"`    // 分组，如 技术、生活、学校同学
+	Pinned   bool    `json:"pinned,omitempty"`   // 置顶，该博客的最新文章始终排在最前
+	Weight   int     `json:"weight,omitempty"`   // 权重，越大越靠前，仅在置顶状态相同的博客之间比较
+	Backfill int     `json:"backfill,omitempty"` // 首次加入时回填的文章数（含最新一篇）
+	Name     string  `json:"name,omitempty"`     // 博客名称，覆盖 RSS 标题及名称映射
+	Note     string  `json:"note,omitempty"`     // 备注（如 高中同学、已停更），原样写入 blogs.json
+	Archived bool    `json:"archived,omitempty"` // 已归档：不再抓取，保留上次的文章并标记 archived
+
+	Insecure *bool `json:"insecure,omitempty"` // 修复模式重试时是否允许跳过证书校验，覆盖 INSECURE_TLS_DOMAINS，nil 表示未配置
+}
+
+// parseFeedEntries 将 RSS 列表文件内容解析为订阅条目，跳过空行和注释行
+func parseFeedEntries(data []byte) []feedEntry {
+	var entries []feedEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry, err := parseFeedEntry(line)
+		if err != nil {
+			fmt.Printf("[WARN] RSS列表配置无效, 已忽略该项配置: %s (%v)\n", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// parseFeedEntry 解析单行订阅配置
+//
+// Description:
+//
+//	无法识别的配置项会返回错误，但已解析的地址和其余配置仍然有效
+func parseFeedEntry(line string) (feedEntry, error) {
+	fields := strings.Fields(line)
+	entry := feedEntry{URL: fields[0]}
+
+	var errs []string
+	for _, f := range fields[1:] {
+		key, value, ok := strings.Cut(f, "=")
+		if !ok {
+			errs = append(errs, fmt.Sprintf("缺少 '=': %s", f))
+			continue
+		}
+		if err := entry.set(strings.ToLower(key), value); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return entry, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return entry, nil
+}
+
+// set 设置单个配置项
+func (e *feedEntry) set(key, value string) error {
+	switch key {
+	case "timeout":
+		secs, err := parseSeconds(value)
+		if err != nil {
+			return fmt.Errorf("timeout 无效: %s", value)
+		}
+		e.Timeout = int(secs)
+	case "retries":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("retries 无效: %s", value)
+		}
+		e.Retries = n
+	case "backoff":
+		secs, err := parseSeconds(value)
+		if err != nil {
+			return fmt.Errorf("backoff 无效: %s", value)
+		}
+		e.Backoff = secs
+	case "group":
+		e.Group = value
+	case "name":
+		name, err := url.PathUnescape(value)
+		if err != nil || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("name 无效: %s", value)
+		}
+		e.Name = strings.TrimSpace(name)
+	case "note":
+		note, err := url.PathUnescape(value)
+		if err != nil || strings.TrimSpace(note) == "" {
+			return fmt.Errorf("note 无效: %s", value)
+		}
+		e.Note = strings.TrimSpace(note)
+	case "pinned":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("pinned 无效: %s", value)
+		}
+		e.Pinned = b
+	case "backfill":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("backfill 无效: %s", value)
+		}
+		e.Backfill = n
+	case "weight":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("weight 无效: %s", value)
+		}
+		e.Weight = n
+	case "archived":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("archived 无效: %s", value)
+		}
+		e.Archived = b
+	case "insecure":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("insecure 无效: %s", value)
+		}
+		e.Insecure = &b
+	default:
+		return fmt.Errorf("未知配置项: %s", key)
+	}
+	return nil
+}
+
+// parseSeconds 解析秒数，支持纯数字（如 30）或时长格式（如 30s、1m）
+func parseSeconds(s string) (float64, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f >= 0 {
+		return f, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return d.Seconds(), nil
+}
+
+// feedURLs 提取订阅条目中的 RSS 地址
+func feedURLs(entries []feedEntry) []string {
+	urls := make([]string, 0, len(entries))
+	for _, e := range entries {
+		urls = append(urls, e.URL)
+	}
+	return urls
+}
+
+// fetchOptions 单个订阅生效的抓取参数
+type fetchOptions struct {
+	Timeout time.Duration // 单次请求超时
+	Retry   retryStrategy // 重试策略，尝试次数与初始间隔可按订阅覆盖
+	Strict  bool          // 严格解析，不做任何容错修复
+
+	Insecure bool // 修复模式重试时是否跳过证书校验
+}
+
+// fetchOptionsFor 合并订阅自身配置与全局配置
+func fetchOptionsFor(e feedEntry, cfg *Config) fetchOptions {
+	opts := fetchOptions{
+		Timeout: time.Duration(cfg.HTTPTimeout) * time.Second,
+		Retry:   cfg.Retry,
+		Strict:  cfg.ParserStrict,
+
+		Insecure: insecureAllowed(cfg, e),
+	}
+	if e.Timeout > 0 {
+		opts.Timeout = time.Duration(e.Timeout) * time.Second
+	}
+	if e.Retries > 0 {
+		opts.Retry.MaxAttempts = e.Retries
+	}
+	if e.Backoff > 0 {
+		opts.Retry.BaseInterval = time.Duration(e.Backoff * float64(time.Second))
+	}
+	return opts
+}
+
+// feedGroups 按RSS列表中首次出现的顺序返回所有分组，并追加文章中出现但列表中没有的分组（如固定数据）
+func feedGroups(entries []feedEntry, articles []Article) []string {
+	var groups []string
+	seen := make(map[string]bool)
+	add := func(g string) {
+		if g != "" && !seen[g] {
+			seen[g] = true
+			groups = append(groups, g)
+		}
+	}
+	for _, e := range entries {
+		add(e.Group)
+	}
+	for _, a := range articles {
+		add(a.Group)
+	}
+	return groups
+}
@@ -0,0 +1,157 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: feed_extensions.go
+// Description: gofeed 解析器的构建与扩展字段提取（media:thumbnail、itunes:image、dc:creator 等）
+
+package main
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+// 扩展字段提取结果保存在 gofeed.Item.Custom 中的键名
+const (
+	customThumbnail = "lhasa:thumbnail"
+	customAuthor    = "lhasa:author"
+)
+
+// newFeedParser 根据配置创建 RSS 解析器
+//
+// Description:
+//
+//	FEED_EXTENSIONS 开启时，为 RSS/Atom/JSON Feed 分别安装 extensionTranslator，
+//	在默认转换的基础上提取缩略图和作者，写入 item.Custom
+func newFeedParser(cfg *Config) *gofeed.Parser {
+	fp := gofeed.NewParser()
+	if cfg.FeedExtensions {
+		fp.RSSTranslator = &extensionTranslator{base: &gofeed.DefaultRSSTranslator{}}
+		fp.AtomTranslator = &extensionTranslator{base: &gofeed.DefaultAtomTranslator{}}
+		fp.JSONTranslator = &extensionTranslator{base: &gofeed.DefaultJSONTranslator{}}
+	}
+	return fp
+}
+
+// feedParserPool 按同一配置复用 RSS 解析器
+//
+// Description:
+//
+//	gofeed.Parser 在解析时会修改其内部 RSS/Atom 解析器的状态，同一实例被多个协程同时使用会产生数据竞争，
+//	表现为偶发的解析结果错乱；每个抓取协程从池中取出独立的实例，用完后归还，同一时刻一个实例只被一个协程使用
+type feedParserPool struct {
+	pool sync.Pool
+}
+
+// newFeedParserPool 创建解析器池，池中的解析器均由 newFeedParser(cfg) 创建
+func newFeedParserPool(cfg *Config) *feedParserPool {
+	p := &feedParserPool{}
+	p.pool.New = func() interface{} { return newFeedParser(cfg) }
+	return p
+}
+
+// get 取出一个当前协程独占的解析器
+func (p *feedParserPool) get() *gofeed.Parser {
+	return p.pool.Get().(*gofeed.Parser)
+}
+
+// put 归还解析器，归还后调用方不得再使用
+func (p *feedParserPool) put(fp *gofeed.Parser) {
+	p.pool.Put(fp)
+}
+
+// extensionTranslator 包装 gofeed 默认的转换器，转换后从扩展字段中提取缩略图与作者
+type extensionTranslator struct {
+	base gofeed.Translator
+}
+
+// Translate 实现 gofeed.Translator
+func (t *extensionTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	f, err := t.base.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range f.Items {
+		if item.Custom == nil {
+			item.Custom = make(map[string]string)
+		}
+		if thumb := itemThumbnail(item); thumb != "" {
+			item.Custom[customThumbnail] = thumb
+		}
+		if author := itemAuthor(item); author != "" {
+			item.Custom[customAuthor] = author
+		}
+	}
+	return f, nil
+}
+
+// itemThumbnail 按优先级提取文章缩略图
+//
+// Description:
+//
+//	media:thumbnail > media:content(图片) > itunes:image > 条目 image > 图片类型的 enclosure
+func itemThumbnail(item *gofeed.Item) string {
+	if media, ok := item.Extensions["media"]; ok {
+		if u := mediaImage(media); u != "" {
+			return u
+		}
+		// 部分 Feed 将 media 元素包在 media:group 中
+		for _, g := range media["group"] {
+			if u := mediaImage(g.Children); u != "" {
+				return u
+			}
+		}
+	}
+	if item.ITunesExt != nil && item.ITunesExt.Image != "" {
+		return item.ITunesExt.Image
+	}
+	if item.Image != nil && item.Image.URL != "" {
+		return item.Image.URL
+	}
+	for _, enc := range item.Enclosures {
+		if strings.HasPrefix(enc.Type, "image/") && enc.URL != "" {
+			return enc.URL
+		}
+	}
+	return ""
+}
+
+// mediaImage 从 media 命名空间的元素中查找图片地址
+func mediaImage(media map[string][]ext.Extension) string {
+	for _, t := range media["thumbnail"] {
+		if u := t.Attrs["url"]; u != "" {
+			return u
+		}
+	}
+	for _, c := range media["content"] {
+		u := c.Attrs["url"]
+		if u != "" && (c.Attrs["medium"] == "image" || strings.HasPrefix(c.Attrs["type"], "image/")) {
+			return u
+		}
+	}
+	return ""
+}
+
+// itemAuthor 提取文章作者：dc:creator 优先，其次为 RSS author / Atom author
+func itemAuthor(item *gofeed.Item) string {
+	if item.DublinCoreExt != nil {
+		for _, c := range item.DublinCoreExt.Creator {
+			if c = strings.TrimSpace(c); c != "" {
+				return c
+			}
+		}
+	}
+	for _, a := range item.Authors {
+		if a != nil && strings.TrimSpace(a.Name) != "" {
+			return strings.TrimSpace(a.Name)
+		}
+	}
+	return ""
+}
+
+// applyItemExtensions 将扩展字段提取结果写入文章
+func applyItemExtensions(a *Article, item *gofeed.Item) {
+	a.Thumbnail = item.Custom[customThumbnail]
+	a.Author = item.Custom[customAuthor]
+}
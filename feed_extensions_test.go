@@ -0,0 +1,84 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: feed_extensions_test.go
+// Description: 解析器池的并发测试，需配合 go test -race 运行：并发解析时不存在数据竞争、结果互不串扰，
+//   且同一时刻一个解析器只被一个协程持有
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestFeedParserPoolConcurrentParse(t *testing.T) {
+	for _, extensions := range []bool{false, true} {
+		t.Run(fmt.Sprintf("extensions=%t", extensions), func(t *testing.T) {
+			pool := newFeedParserPool(&Config{FeedExtensions: extensions})
+			var wg sync.WaitGroup
+			for g := range 16 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range 20 {
+						title := fmt.Sprintf("feed-%d-%d", g, i)
+						var body string
+						if i%2 == 0 {
+							body = fmt.Sprintf(`<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/"><channel><title>%[1]s</title>`+
+								`<item><title>%[1]s</title><media:thumbnail url="https://example.com/%[1]s.png"/></item></channel></rss>`, title)
+						} else {
+							body = fmt.Sprintf(`<feed xmlns="http://www.w3.org/2005/Atom"><title>%[1]s</title>`+
+								`<entry><title>%[1]s</title><author><name>%[1]s</name></author></entry></feed>`, title)
+						}
+						fp := pool.get()
+						feed, err := fp.ParseString(body)
+						pool.put(fp)
+						if err != nil {
+							t.Errorf("解析 %s 失败: %v", title, err)
+							return
+						}
+						if feed.Title != title || len(feed.Items) != 1 || feed.Items[0].Title != title {
+							t.Errorf("解析结果串扰: want %s, got feed %q", title, feed.Title)
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestFeedParserPoolExclusive(t *testing.T) {
+	pool := newFeedParserPool(&Config{})
+	const n = 16
+	var (
+		mu      sync.Mutex
+		held    = make(map[*gofeed.Parser]bool)
+		ready   sync.WaitGroup
+		release = make(chan struct{})
+		done    sync.WaitGroup
+	)
+	ready.Add(n)
+	done.Add(n)
+	for range n {
+		go func() {
+			defer done.Done()
+			fp := pool.get()
+			mu.Lock()
+			if held[fp] {
+				t.Errorf("同一解析器被两个协程同时取出")
+			}
+			held[fp] = true
+			mu.Unlock()
+			ready.Done()
+			<-release
+			pool.put(fp)
+		}()
+	}
+	ready.Wait()
+	close(release)
+	done.Wait()
+}
@@ -2,40 +2,70 @@
 // File: feed_fetcher.go
 // Description:
 //   并发抓取RSS Feed的核心逻辑，包括：
-//   1. 从COS或本地文件获取RSS文件
+//   1. 从COS、本地文件或GitHub仓库获取RSS文件
 //   2. 并发抓取每个RSS Feed
 //   3. 对解析失败的RSS使用指数退避算法进行重试
 
 package main
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
-	"io"
-	"math"
+	"iter"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/achuanya/lhasaRSS/urlnorm"
 	"github.com/mmcdole/gofeed"
 )
 
-// fetchRSSLinks 根据 cfg.RssSource 选择从COS拉取txt还是读取本地文件
+// fetchRSSLinks 读取并合并 cfg.RssLists 中的所有RSS列表
 //
 // Description:
 //
-//	若 cfg.RssSource = "COS"，则通过 http.Get(cfg.RssListURL) 获取RSS列表txt
-//	若 cfg.RssSource = "GITHUB"，则认为 cfg.RssListURL 指向本地文件路径，直接 os.ReadFile
-//	读到内容后按行分割，去掉空行，返回 RSS 链接列表
-func fetchRSSLinks(cfg *Config) ([]string, error) {
+//	RSS 可配置为逗号分隔的多个来源（如个人列表和共享的班级友链），依次读取后按顺序合并，
+//	同一订阅出现在多个列表中时以先出现的条目（及其配置）为准
+//	任一来源读取失败都会返回错误，避免缺少部分订阅时覆盖已有数据
+func fetchRSSLinks(ctx context.Context, cfg *Config, cache *fetchCache) ([]feedEntry, error) {
+	lists := make([][]feedEntry, 0, len(cfg.RssLists))
+	for _, src := range cfg.RssLists {
+		entries, err := fetchRSSList(ctx, cfg, src, cache)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, entries)
+	}
+	return mergeFeedEntries(lists...), nil
+}
+
+// fetchRSSList 根据 cfg.RssSource 选择从COS拉取列表还是读取本地文件
+//
+// Description:
+//
+//	若 cfg.RssSource = "COS"，则通过 HTTP 获取远程列表
+//	若 cfg.RssSource = "GITHUB"，HTTP(S) 地址同样远程获取，其余视为本地文件路径（本地不存在时从仓库读取）
+//	无论 RSS_SOURCE 为何值，gist:ID/文件名 形式的地址都通过 Gists API 读取
+//	读到内容后解析为订阅列表，支持按行书写的 txt 与 OPML 两种格式
+func fetchRSSList(ctx context.Context, cfg *Config, src string, cache *fetchCache) ([]feedEntry, error) {
+	if strings.HasPrefix(src, "gist:") {
+		return fetchRSSLinksFromGist(ctx, cfg, src)
+	}
 	switch cfg.RssSource {
 	case "COS":
-		return fetchRSSLinksFromHTTP(cfg.RssListURL)
+		return fetchRSSLinksFromHTTP(ctx, cfg, src, cache)
 	case "GITHUB":
-		return fetchRSSLinksFromLocal(cfg.RssListURL)
+		if isRemoteURL(src) {
+			return fetchRSSLinksFromHTTP(ctx, cfg, src, cache)
+		}
+		return fetchRSSLinksFromGitHub(ctx, cfg, src)
 	default:
 		return nil, fmt.Errorf("无效的 RSS_SOURCE 配置: %s", cfg.RssSource)
 	}
@@ -45,53 +75,96 @@ func fetchRSSLinks(cfg *Config) ([]string, error) {
 //
 // Description:
 //
-//	通过 HTTP GET 请求获取存放在 COS (或其他 URL ) 中的一个纯文本文件（每行一个RSS链接）
-//	然后将这些链接按行分割返回
-func fetchRSSLinksFromHTTP(rssTxtURL string) ([]string, error) {
-	resp, err := http.Get(rssTxtURL)
+//	通过 HTTP GET 请求获取存放在 COS (或其他 URL ) 中的一个纯文本文件（每行一个RSS链接）或 OPML 文件
+//	然后将其解析为订阅列表返回；若提供了 cache，则使用条件请求，未变化时复用缓存内容
+//	列表需要认证时按 RSS_AUTH 携带认证信息
+func fetchRSSLinksFromHTTP(ctx context.Context, cfg *Config, rssTxtURL string, cache *fetchCache) ([]feedEntry, error) {
+	client, err := rssListClient(cfg)
 	if err != nil {
-		return nil, wrapErrorf(err, "无法获取RSS列表文件: %s", rssTxtURL)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	data, notModified, err := cache.conditionalGet(ctx, client, rssTxtURL)
+	if err != nil {
+		return nil, wrapErrorf(err, "获取RSS列表失败: %s", rssTxtURL)
+	}
+	if notModified {
+		fmt.Printf("[INFO] RSS列表未变化(304), 使用缓存: %s\n", rssTxtURL)
+	}
+	entries, err := parseFeedList(data)
+	if err != nil {
+		return nil, wrapErrorf(err, "获取RSS列表失败: %s", rssTxtURL)
+	}
+	return entries, nil
+}
 
-	if resp.StatusCode != 200 {
-		return nil, wrapErrorf(
-			fmt.Errorf("HTTP状态码: %d", resp.StatusCode),
-			"获取RSS列表失败: %s", rssTxtURL,
-		)
+// fetchRSSLinksFromGitHub 读取 RSS_SOURCE=GITHUB 时的RSS列表
+//
+// Description:
+//
+//	优先读取本地检出的文件；本地文件不存在且配置了 GitHub 仓库时，
+//	通过 contents API 从仓库中读取同一路径，便于在仓库检出之外运行
+func fetchRSSLinksFromGitHub(ctx context.Context, cfg *Config, filePath string) ([]feedEntry, error) {
+	_, err := os.Stat(filePath)
+	if !os.IsNotExist(err) || cfg.GitHubName == "" || cfg.GitHubRepo == "" {
+		return fetchRSSLinksFromLocal(filePath)
 	}
+	fmt.Printf("[INFO] 本地不存在RSS列表 %s, 从仓库 %s/%s 读取\n", filePath, cfg.GitHubName, cfg.GitHubRepo)
+	return fetchRSSLinksFromRepo(ctx, cfg, filePath)
+}
 
-	data, err := io.ReadAll(resp.Body)
+// fetchRSSLinksFromRepo 通过 GitHub contents API 读取仓库中的RSS列表
+func fetchRSSLinksFromRepo(ctx context.Context, cfg *Config, filePath string) ([]feedEntry, error) {
+	content, sha, err := getGitHubFileContent(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, filePath)
+	if err != nil {
+		return nil, wrapErrorf(err, "从GitHub仓库读取RSS列表失败: %s", filePath)
+	}
+	if sha == "" {
+		return nil, errors.New(trf("GitHub仓库 %s/%s 中不存在RSS列表: %s", cfg.GitHubName, cfg.GitHubRepo, filePath))
+	}
+	entries, err := parseFeedList([]byte(content))
 	if err != nil {
-		return nil, wrapErrorf(err, "读取RSS列表body失败")
+		return nil, wrapErrorf(err, "从GitHub仓库读取RSS列表失败: %s", filePath)
 	}
+	return entries, nil
+}
 
-	return parseLinesToLinks(data), nil
+// fetchRSSLinksFromGist 读取 Gist 中的RSS列表
+//
+// Description:
+//
+//	src 形如 gist:ID/文件名，Gist 只有一个文件时可省略文件名；
+//	私有（secret）Gist 同样可读，需提供有 gist 权限的 GIST_TOKEN（未设置时使用 TOKEN）
+func fetchRSSLinksFromGist(ctx context.Context, cfg *Config, src string) ([]feedEntry, error) {
+	gistID, filename, _ := strings.Cut(strings.TrimPrefix(src, "gist:"), "/")
+	if gistID == "" {
+		return nil, fmt.Errorf("无效的 Gist 地址: %s", src)
+	}
+	content, err := getGistFile(ctx, cfg.GistToken, gistID, filename)
+	if err != nil {
+		return nil, wrapErrorf(err, "从Gist读取RSS列表失败: %s", src)
+	}
+	entries, err := parseFeedList([]byte(content))
+	if err != nil {
+		return nil, wrapErrorf(err, "从Gist读取RSS列表失败: %s", src)
+	}
+	return entries, nil
 }
 
 // fetchRSSLinksFromLocal 从本地文件中逐行读取RSS链接
 //
 // Description:
 //
-//	从 Github 读取文本内容，然后将其按行分割返回
-func fetchRSSLinksFromLocal(filePath string) ([]string, error) {
+//	从 Github 读取文本内容，然后将其解析为订阅列表返回
+func fetchRSSLinksFromLocal(filePath string) ([]feedEntry, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, wrapErrorf(err, "读取Github RSS文件失败: %s", filePath)
 	}
-	return parseLinesToLinks(data), nil
-}
-
-// parseLinesToLinks 将字节切片按行拆分并去掉空白行, 返回非空字符串切片
-func parseLinesToLinks(data []byte) []string {
-	var links []string
-	for _, line := range strings.Split(string(data), "\n") {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			links = append(links, line)
-		}
+	entries, err := parseFeedList(data)
+	if err != nil {
+		return nil, wrapErrorf(err, "读取Github RSS文件失败: %s", filePath)
 	}
-	return links
+	return entries, nil
 }
 
 // fetchAllFeeds 并发抓取所有RSS链接，返回抓取结果及统计信息
@@ -101,237 +174,435 @@ func parseLinesToLinks(data []byte) []string {
 //	该函数读取传入的所有RSS链接，使用10路并发进行抓取
 //	在抓取过程中对解析失败、内容为空等情况进行统计
 //	若抓取的RSS头像缺失或无法访问，将替换为默认头像
-//	支持通过AvatarMapper进行域名匹配和头像替换
+//	支持通过AvatarMapper进行域名匹配和头像替换，通过NameMapper替换博客名称；
+//	抓取本身由 streamFeeds 完成，本函数等待全部结果后统一汇总
 //
 // Parameters:
 //   - ctx           : 上下文，用于控制网络请求的取消或超时
-//   - rssLinks      : RSS链接的字符串切片，每个链接代表一个RSS源
-//   - defaultAvatar : 备用头像地址，在抓取头像失败或不可用时使用
+//   - feeds         : 订阅列表，每项代表一个RSS源及其单独的抓取配置
+//   - cfg           : 全局配置，提供默认超时、重试次数和备用头像等
 //   - avatarMapper  : 头像映射器，用于根据域名替换头像
+//   - nameMapper    : 名称映射器，用于根据RSS地址、域名、标题或正则替换博客名称
+//   - cache         : 抓取缓存，用于跨运行复用头像解析结果，可为 nil
 //
 // Returns:
 //   - []feedResult         : 每个RSS链接抓取的结果（包含成功的Feed及其文章或错误信息）
 //   - map[string][]string  : 各种问题的统计记录（解析失败、内容为空、头像缺失、头像不可用）
-func fetchAllFeeds(ctx context.Context, rssLinks []string, defaultAvatar string, avatarMapper *AvatarMapper) ([]feedResult, map[string][]string) {
-	// 设置最大并发量，以信道（channel）信号量的方式控制
-	maxGoroutines := 10
-	sem := make(chan struct{}, maxGoroutines)
-
-	// 等待组，用来等待所有goroutine执行完毕
-	var wg sync.WaitGroup
-
-	resultChan := make(chan feedResult, len(rssLinks)) // 用于收集抓取结果的通道
-	fp := gofeed.NewParser()                           // RSS解析器实例
-
-	// 遍历所有RSS链接，为每个RSS链接开启一个goroutine进行抓取
-	for _, link := range rssLinks {
-		link = strings.TrimSpace(link)
-		if link == "" {
-			continue
-		}
-		wg.Add(1)         // 每开启一个goroutine，对应Add(1)
-		sem <- struct{}{} // 向sem发送一个空结构体，表示占用了一个并发槽
-
-		// 开启协程
-		go func(rssLink string) {
-			defer wg.Done()          // 协程结束时Done
-			defer func() { <-sem }() // 函数结束时释放一个并发槽
-
-			var fr feedResult
-			fr.FeedLink = rssLink
-
-			// 抓取RSS Feed, 无法解析时，使用指数退避算法进行重试, 有3次重试, 初始1s, 倍数2.0
-			feed, err := fetchFeedWithRetry(rssLink, fp, 3, 1*time.Second, 2.0)
-			if err != nil {
-				// 如果解析失败，记录错误并把结果发送到通道
-				fr.Err = wrapErrorf(err, "解析RSS失败: %s", rssLink)
-				resultChan <- fr
-				return
-			}
-
-			// 如果Feed为空或没有Items，视作无有效内容
-			if feed == nil || len(feed.Items) == 0 {
-				fr.Err = wrapErrorf(fmt.Errorf("该订阅没有内容"), "RSS为空: %s", rssLink)
-				resultChan <- fr
-				return
-			}
-
-			// 获取RSS的头像信息（若RSS自带头像则用RSS的，否则尝试从博客主页解析）
-			avatarURL := getFeedAvatarURL(feed)
-			fr.Article = &Article{
-				BlogName: feed.Title, // 记录博客名称
-			}
-
-			// 检查头像可用性
-			if avatarURL == "" {
-				// 若头像链接为空，则标记为空字符串
-				fr.Article.Avatar = ""
-			} else {
-				ok, _ := checkURLAvailable(avatarURL)
-				if !ok {
-					fr.Article.Avatar = "BROKEN" // 无法访问，暂记为BROKEN
-				} else {
-					fr.Article.Avatar = avatarURL // 正常可访问则记录真实URL
-				}
-			}
-
-			// 只取最新一篇文章作为结果
-			latest := feed.Items[0]
-			fr.Article.Title = latest.Title
-			fr.Article.Link = latest.Link
-
-			// 解析发布时间，如果 RSS 解析器本身给出了 PublishedParsed 直接用，否则尝试解析 Published 字符串
-			pubTime := time.Now()
-			if latest.PublishedParsed != nil {
-				pubTime = *latest.PublishedParsed
-			} else if latest.Published != "" {
-				if t, e := parseTime(latest.Published); e == nil {
-					pubTime = t
-				}
-			}
-			// 把解析出的时间，格式化为 "Jan 02, 2006" 记录下来
-			fr.ParsedTime = pubTime
-			fr.Article.Published = pubTime.Format("Jan 02, 2006")
-
-			resultChan <- fr
-		}(link)
-	}
-
-	// 开启一个goroutine等待所有抓取任务结束后，关闭resultChan
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
+func fetchAllFeeds(ctx context.Context, feeds []feedEntry, cfg *Config, avatarMapper *AvatarMapper, nameMapper *NameMapper, cache *fetchCache) ([]feedResult, map[string][]string) {
 	// 用于统计各种问题
 	problems := map[string][]string{
 		"parseFails":   {}, // 解析 RSS 失败
 		"feedEmpties":  {}, // 内容 RSS 为空
+		"feedShort":    {}, // 文章数少于 MIN_ITEMS，疑似被截断
+		"throttled":    {}, // 被服务器限流（429/503）
+		"timeouts":     {}, // 请求超时
+		"htmlPages":    {}, // RSS 地址返回的是 HTML 页面
+		"authWalled":   {}, // 需要登录或付费订阅（401/403/登录页）
 		"noAvatar":     {}, // 头像地址为空
 		"brokenAvatar": {}, // 头像无法访问
+		"badFields":    {}, // 标题、链接等字段异常，已截断或替换
+		"insecureTLS":  {}, // 修复模式下跳过了证书校验
 	}
 	// 收集抓取结果
 	var results []feedResult
 
-	for r := range resultChan {
+	for r := range streamFeeds(ctx, feeds, cfg, avatarMapper, cache) {
+		if r.Empty {
+			problems["feedEmpties"] = append(problems["feedEmpties"], r.FeedLink)
+			results = append(results, r)
+			continue
+		}
 		if r.Err != nil {
 			// 若存在错误，进一步识别错误类型以便统计
-			errStr := r.Err.Error()
+			var statusErr *httpStatusError
+			var pageErr *htmlPageError
 			switch {
-			case strings.Contains(errStr, "解析RSS失败"):
-				problems["parseFails"] = append(problems["parseFails"], r.FeedLink)
-			case strings.Contains(errStr, "RSS为空"):
+			case errors.As(r.Err, &statusErr) && statusErr.authRequired():
+				problems["authWalled"] = append(problems["authWalled"], fmt.Sprintf("%s (HTTP %d)", r.FeedLink, statusErr.StatusCode))
+			case errors.As(r.Err, &pageErr) && pageErr.LoginWall:
+				problems["authWalled"] = append(problems["authWalled"], fmt.Sprintf("%s (%s)", r.FeedLink, pageErr.Error()))
+			case errors.As(r.Err, &pageErr):
+				problems["htmlPages"] = append(problems["htmlPages"], fmt.Sprintf("%s (%s)", r.FeedLink, pageErr.Error()))
+			case errors.As(r.Err, &statusErr) && statusErr.throttled():
+				problems["throttled"] = append(problems["throttled"], fmt.Sprintf("%s (HTTP %d)", r.FeedLink, statusErr.StatusCode))
+			case errors.Is(r.Err, ErrEmptyFeed):
 				problems["feedEmpties"] = append(problems["feedEmpties"], r.FeedLink)
+			case errors.Is(r.Err, ErrTimeout):
+				problems["timeouts"] = append(problems["timeouts"], r.FeedLink)
+			default:
+				problems["parseFails"] = append(problems["parseFails"], r.FeedLink)
 			}
 			results = append(results, r)
 			continue
 		}
 
-		// 对于成功抓取的Feed，如果头像为空或不可用则使用默认头像
-		// 首先尝试使用AvatarMapper进行域名匹配替换
+		if cfg.MinItems > 0 && r.ItemCount < cfg.MinItems {
+			problems["feedShort"] = append(problems["feedShort"],
+				trf("%s (仅 %d 篇, 少于 %d 篇)", r.FeedLink, r.ItemCount, cfg.MinItems))
+		}
+
+		if r.InsecureTLS {
+			problems["insecureTLS"] = append(problems["insecureTLS"], fmt.Sprintf("%s (%s)", r.FeedLink, r.Strategy))
+		}
+		if len(r.FieldIssues) > 0 {
+			problems["badFields"] = append(problems["badFields"],
+				fmt.Sprintf("%s (%s)", r.FeedLink, strings.Join(r.FieldIssues, "; ")))
+		}
+
+		// 对于成功抓取的Feed，如果头像为空或不可用则使用默认头像（头像映射已在抓取时优先应用）
+		// 首先尝试使用AvatarMapper进行域名匹配替换名称
+		feedTitle := r.Article.BlogName
         if avatarMapper != nil {
-            if mappedAvatar, found := avatarMapper.GetAvatarByURL(r.FeedLink); found {
-                r.Article.Avatar = mappedAvatar
-            }
             if mappedName, found := avatarMapper.GetNameByURL(r.FeedLink); found {
                 r.Article.BlogName = mappedName
             }
         }
+		// NameMapper 的规则更精确，按原始标题匹配并覆盖
+		if mappedName, found := nameMapper.Lookup(r.FeedLink, feedTitle); found {
+			r.Article.BlogName = mappedName
+		}
+		// RSS列表中单独指定的名称优先级最高
+		if r.Name != "" {
+			r.Article.BlogName = r.Name
+		}
 
+		// RSS 与头像映射都没有可用头像时，使用所在分组的默认头像
 		if r.Article.Avatar == "" {
 			problems["noAvatar"] = append(problems["noAvatar"], r.FeedLink)
-			r.Article.Avatar = defaultAvatar
+			r.Article.Avatar = cfg.defaultAvatarFor(r.Article.Group)
 		} else if r.Article.Avatar == "BROKEN" {
 			problems["brokenAvatar"] = append(problems["brokenAvatar"], r.FeedLink)
-			r.Article.Avatar = defaultAvatar
+			r.Article.Avatar = cfg.defaultAvatarFor(r.Article.Group)
 		}
 		results = append(results, r)
 	}
 	return results, problems
 }
 
+// streamFeeds 并发抓取所有订阅，按完成的先后顺序逐个产出抓取结果
+//
+// Description:
+//
+//	fetchAllFeeds 的流式版本，结果在每个订阅抓取完成时即可处理，而不必等待全部订阅抓取完成；
+//	产出的结果已完成头像解析，但尚未应用名称映射与默认头像（由 fetchAllFeeds 在汇总时处理）。
+//	提前结束遍历时不再开启新的抓取，已开始的抓取在后台完成后丢弃
+//	本项目是命令行程序（package main），不提供可导入的库 API，本函数只供程序内部使用：
+//	feedResult 依赖 Config、抓取缓存、头像解析等内部类型，拆分为独立包的改动面过大，暂不对外暴露
+//
+// Parameters:
+//   - ctx          : 上下文，用于控制网络请求的取消或超时
+//   - feeds        : 订阅列表
+//   - cfg          : 全局配置
+//   - avatarMapper : 头像映射器，已映射的博客跳过头像解析，可为 nil
+//   - cache        : 抓取缓存，可为 nil
+//
+// Returns:
+//   - iter.Seq[feedResult] : 抓取结果序列，只能遍历一次
+func streamFeeds(ctx context.Context, feeds []feedEntry, cfg *Config, avatarMapper *AvatarMapper, cache *fetchCache) iter.Seq[feedResult] {
+	// 设置最大并发量，以信道（channel）信号量的方式控制
+	maxGoroutines := 10
+	sem := make(chan struct{}, maxGoroutines)
+
+	// 等待组，用来等待所有goroutine执行完毕
+	var wg sync.WaitGroup
+
+	avatarTTL := time.Duration(cfg.AvatarCacheTTL) * time.Hour
+	robots := newRobotsChecker(cfg.RespectRobots)
+	avatars := newAvatarResolver(cfg, avatarMapper, robots, newAvatarChecker(cfg, cache))
+
+	parsers := newFeedParserPool(cfg) // RSS解析器池，每个协程使用独立的实例
+
+	return func(yield func(feedResult) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		resultChan := make(chan feedResult, len(feeds)) // 用于收集抓取结果的通道，容量足够时提前停止读取也不会阻塞抓取协程
+
+		go func() {
+			// 遍历所有订阅，为每个RSS链接开启一个goroutine进行抓取
+			for _, entry := range feeds {
+				link := strings.TrimSpace(entry.URL)
+				if link == "" {
+					continue
+				}
+				// 调用方已停止读取结果时不再开启新的抓取
+				if ctx.Err() != nil {
+					break
+				}
+				wg.Add(1)         // 每开启一个goroutine，对应Add(1)
+				sem <- struct{}{} // 向sem发送一个空结构体，表示占用了一个并发槽
+
+				// 开启协程
+				go func(rssLink string, entry feedEntry, opts fetchOptions) {
+					defer wg.Done()          // 协程结束时Done
+					defer func() { <-sem }() // 函数结束时释放一个并发槽
+
+					var fr feedResult
+					fr.FeedLink = rssLink
+					fr.Name = entry.Name
+					fr.Note = entry.Note
+
+					// 单个订阅内容异常导致的 panic 只记为该订阅解析失败，不影响其他订阅
+					defer recoverPanic(trf("抓取 %s", rssLink), func(err error) {
+						resultChan <- feedResult{FeedLink: rssLink, Name: entry.Name, Note: entry.Note, Err: wrapErrorf(err, "解析RSS失败: %s", rssLink)}
+					})
+
+					// 抓取RSS Feed, 无法解析时，按重试策略（RETRY_*）进行指数退避重试, 次数和初始等待时间可按订阅单独配置
+					fp := parsers.get()
+					defer parsers.put(fp)
+					feed, info, err := fetchFeedWithRetry(rssLink, fp, opts.Timeout, opts.Retry, opts.Strict, opts.Insecure)
+					fr.CertExpiry = info.CertExpiry
+					fr.FinalURL = info.FinalURL
+					fr.InsecureTLS = info.Insecure
+					fr.Attempts, fr.Strategy = info.Attempts, info.Strategy
+					fr.ETag = info.ETag
+					fr.Bytes = info.Bytes
+					recordFetchLatency(cfg, cache, rssLink, info, err)
+					if err != nil {
+						// 如果解析失败，记录错误并把结果发送到通道
+						fr.Err = wrapErrorf(err, "解析RSS失败: %s", rssLink)
+						resultChan <- fr
+						return
+					}
+
+					// 按 ARTICLE_LANGUAGES 去掉其他语言的文章，全部被过滤时与没有文章的订阅相同
+					if n := filterItemsByLanguage(feed, cfg.ArticleLanguages); n > 0 {
+						fmt.Printf("[INFO] %s 按语言过滤掉 %d 篇文章\n", rssLink, n)
+					}
+
+					// 没有文章的订阅不算失败：保留博客信息，仅标记为空，不产生文章
+					if feed == nil || len(feed.Items) == 0 {
+						fr.Empty = true
+						if feed != nil {
+							fr.Homepage = feed.Link
+							fr.Feed = feed
+						}
+						resultChan <- fr
+						return
+					}
+					fr.ItemCount = len(feed.Items)
+
+					// YouTube、GitHub 等平台订阅使用频道名、仓库名及其头像，而不是平台的标题和图标
+					platform, isPlatform := detectPlatformFeed(rssLink, feed)
+					if isPlatform {
+						platform.apply(feed)
+					}
+
+					fr.Article = &Article{
+						BlogName: feed.Title,  // 记录博客名称
+						Feed:     rssLink,     // 记录文章来源的RSS地址
+						Group:    entry.Group, // 记录RSS列表中配置的分组
+						Pinned:   entry.Pinned,
+						weight:   entry.Weight,
+
+						Source:     feedSource(feed),
+						FetchedURL: info.FinalURL,
+					}
+					fr.Homepage = feed.Link
+					fr.Feed = feed
+
+					// 头像解析结果按订阅缓存（不按域名，cnblogs.com、github.io 等共享域名下的博客各有各的头像），
+					// 未过期时跳过主页抓取和可用性检查
+					avatarKey := urlnorm.Key(rssLink)
+					if isPlatform {
+						avatarKey = platform.CacheKey
+					}
+					// 头像映射位于 AVATAR_ORDER 首位且已指定头像时直接使用，跳过主页抓取和可用性检查
+					if mapped, found := avatars.mapped(rssLink); found {
+						fr.Article.Avatar = mapped
+					} else if avatar, ok := cache.avatar(avatarKey, avatarTTL); ok {
+						fr.Article.Avatar = avatar
+					} else {
+						var platformInfo *platformFeed
+						if isPlatform {
+							platformInfo = &platform
+						}
+						fr.Article.Avatar = avatars.resolve(ctx, rssLink, feed, platformInfo)
+						cache.setAvatar(avatarKey, fr.Article.Avatar)
+					}
+
+					// 只取最新一篇文章作为结果
+					latest := feed.Items[0]
+					fr.Article.Title = latest.Title
+					fr.Article.Link = latest.Link
+					applyItemExtensions(fr.Article, latest)
+					fr.Article.Extra = rawItemFields(latest, cfg.RawFields)
+					fr.FieldIssues = guardArticleFields(fr.Article, feed.Link)
+
+					// 解析发布时间，如果 RSS 解析器本身给出了 PublishedParsed 直接用，否则尝试解析 Published 字符串
+					pubTime := time.Now()
+					if latest.PublishedParsed != nil {
+						pubTime = *latest.PublishedParsed
+					} else if latest.Published != "" {
+						if t, e := parseTime(latest.Published); e == nil {
+							pubTime = t
+						}
+					}
+					// 记录正文摘录，供摘要等内容增强使用
+					body := latest.Description
+					if body == "" {
+						body = latest.Content
+					}
+					fr.Article.content = htmlToText(body, 1500)
+
+					if latest.UpdatedParsed != nil {
+						fr.Article.UpdatedAt = latest.UpdatedParsed.Format(time.RFC3339)
+					}
+					// 把解析出的时间，格式化为 "Jan 02, 2006" 记录下来
+					fr.ParsedTime = pubTime
+					fr.Article.Published = pubTime.Format("Jan 02, 2006")
+
+					resultChan <- fr
+				}(link, entry, adaptiveFetchOptions(entry, cfg, cache))
+			}
+
+			// 所有抓取任务结束后，关闭resultChan
+			wg.Wait()
+			close(resultChan)
+		}()
+
+		for r := range resultChan {
+			if !yield(r) {
+				return
+			}
+		}
+	}
+}
+
 // fetchFeedWithRetry 对单个RSS链接进行抓取，在解析失败时，使用指数退避算法进行多次重试
 //
 // Description:
 //
 //	本函数会在解析RSS失败时，进行多次尝试：第一次直接常规抓取；后续使用自定义User-Agent、忽略SSL问题、清理非法XML字符的方法，
-//	并在每次失败后等待一定时长，等待时长使用指数退避（backoffMultiple）
+//	并在每次失败后按重试策略等待一定时长（指数退避、随机抖动），下一次等待会超过总时长上限时不再重试；
+//	服务器返回 429/503 并带有 Retry-After 时，至少等待其要求的时长，要求超过 maxRetryAfter 时不再重试
 //
 // Parameters:
 //   - rssLink         : RSS链接
-//   - parser          : gofeed.Parser实例，用于解析RSS数据
-//   - maxRetries      : 最大尝试次数（包含首次尝试）
-//   - baseWait        : 初始等待时长（如1秒）
-//   - backoffMultiple : 每次重试等待时间的增长倍数（如2.0，即每次等待时间翻倍）
+//   - parser          : gofeed.Parser实例，用于解析RSS数据，不能同时被其他协程使用
+//   - timeout         : 单次请求的超时时间
+//   - retry           : 重试策略（最大尝试次数、初始间隔、增长倍数、抖动、总时长上限）
+//   - strict          : 严格模式，不清理非法XML字符，重试时也不使用忽略SSL等修复策略
+//   - insecure        : 修复模式重试时是否跳过证书校验（由 INSECURE_TLS_DOMAINS 或订阅的 insecure= 决定）
 //
 // Returns:
 //   - *gofeed.Feed:  成功时返回解析后的Feed对象
+//   - fetchInfo   :  抓取过程中记录的附加信息（证书到期时间等）
 //   - error       :  若所有重试均失败，则返回最后一次的错误
-func fetchFeedWithRetry(rssLink string, parser *gofeed.Parser, maxRetries int, baseWait time.Duration, backoffMultiple float64) (*gofeed.Feed, error) {
+func fetchFeedWithRetry(rssLink string, parser *gofeed.Parser, timeout time.Duration, retry retryStrategy, strict, insecure bool) (*gofeed.Feed, fetchInfo, error) {
+	var info fetchInfo
 	var lastErr error
+	start := time.Now()
+	maxRetries := retry.attempts()
 	for i := 0; i < maxRetries; i++ {
 		var feed *gofeed.Feed
 		var err error
 
-		// 第一次尝试使用常规抓取
-		if i == 0 {
-			feed, err = fetchFeed(rssLink, parser)
+		// 第一次尝试使用常规抓取，严格模式下每次都使用常规抓取
+		info.Attempts = i + 1
+		attemptStart := time.Now()
+		if i == 0 || strict {
+			info.Strategy = "plain"
+			feed, err = fetchFeed(rssLink, parser, timeout, &info, strict)
 		} else {
-			// 后续重试时，使用“忽略SSL、自定义UA、清理数据”的抓取方式
-			feed, err = fetchFeedWithFix(rssLink, parser)
+			// 后续重试时，使用“忽略SSL（仅限白名单）、自定义UA、清理数据”的抓取方式
+			info.Strategy = "fix"
+			feed, err = fetchFeedWithFix(rssLink, parser, timeout, &info, insecure)
 		}
 
+		activeDump.writeResult(rssLink, info.Attempts, info.Strategy, feed, err)
+
 		if err == nil {
 			// 如果本次尝试成功解析，则直接返回
-			return feed, nil
+			info.Latency = time.Since(attemptStart)
+			return feed, info, nil
 		}
 		lastErr = err
 
 		fmt.Printf("[Retry %d/%d] RSS parse fail for %s: %v\n", i+1, maxRetries, rssLink, err)
 
+		// 返回 HTML 页面属于配置错误，重试不会改变结果
+		var pageErr *htmlPageError
+		if errors.As(err, &pageErr) {
+			break
+		}
+
 		// 若还未到最后一次尝试，则等待一段时间后继续重试
 		if i < maxRetries-1 {
-			wait := time.Duration(float64(baseWait) * math.Pow(backoffMultiple, float64(i)))
+			wait := retry.delay(i + 1)
+			var statusErr *httpStatusError
+			if errors.As(err, &statusErr) && statusErr.throttled() && statusErr.RetryAfter > wait {
+				if statusErr.RetryAfter > maxRetryAfter {
+					fmt.Printf("[WARN] %s 要求 %s 后重试, 超过上限 %s, 放弃重试\n", rssLink, statusErr.RetryAfter, maxRetryAfter)
+					break
+				}
+				wait = statusErr.RetryAfter
+			}
+			if !retry.allows(time.Since(start), wait) {
+				fmt.Printf("[WARN] %s 重试将超过总时长上限 %s, 放弃重试\n", rssLink, retry.MaxElapsed)
+				break
+			}
 			time.Sleep(wait)
 		}
 	}
-	return nil, lastErr
+	return nil, info, lastErr
 }
 
-// fetchFeed 使用最简单的 http.Get 抓取RSS，并在需要时去除非法XML字符
+// fetchFeed 使用最简单的 GET 请求抓取RSS，并在需要时去除非法XML字符
 //
 // Description:
 //
-//	常规抓取方式，只做了基础的 http.Get 请求和非法字符清理，通常是第一优先使用的方法，
+//	常规抓取方式，只做了基础的 GET 请求和非法字符清理，通常是第一优先使用的方法，
 //	在失败后才会使用 fetchFeedWithFix
 //
 // Parameters:
 //   - rssLink : RSS链接
 //   - parser  : gofeed.Parser实例
+//   - timeout : 请求超时时间
+//   - info    : 用于记录抓取附加信息，可为 nil
+//   - strict  : 严格模式，不清理非法XML字符
 //
 // Returns:
 //   - *gofeed.Feed : 成功时返回Feed对象
 //   - error        : 若请求或解析失败，则返回错误信息
-func fetchFeed(rssLink string, parser *gofeed.Parser) (*gofeed.Feed, error) {
-	resp, err := http.Get(rssLink)
+func fetchFeed(rssLink string, parser *gofeed.Parser, timeout time.Duration, info *fetchInfo, strict bool) (*gofeed.Feed, error) {
+	client := &http.Client{Timeout: timeout}
+	req, err := http.NewRequest("GET", rssLink, nil)
 	if err != nil {
 		return nil, err
 	}
+	setAcceptEncoding(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, classifyNetError(err)
+	}
 	defer resp.Body.Close()
+	info.recordResponse(resp)
+	activeDump.writeHeaders(rssLink, info.attempt(), resp)
 
 	// 状态码不为200，视为失败
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http error: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		return nil, newHTTPStatusError(resp)
 	}
 
-	rawData, err := io.ReadAll(resp.Body)
+	rawData, err := readFeedBody(resp)
 	if err != nil {
 		return nil, err
 	}
+	activeDump.write(rssLink, info.attempt(), "raw.xml", rawData)
+
+	// 返回的是 HTML 页面时给出诊断信息，而不是笼统的解析失败
+	if err := sniffHTMLPage(rssLink, resp.Header.Get("Content-Type"), rawData); err != nil {
+		return nil, err
+	}
+	if strict {
+		return parseFeedData(parser, rawData)
+	}
 
 	// 去除非法的 XML 控制字符，避免解析错误
 	cleanData := removeInvalidXMLChars(rawData)
-	return parser.ParseString(string(cleanData))
+	activeDump.write(rssLink, info.attempt(), "clean.xml", cleanData)
+	return parseFeedData(parser, cleanData)
 }
 
 // fetchFeedWithFix 采用修复策略抓取RSS
@@ -346,18 +617,26 @@ func fetchFeed(rssLink string, parser *gofeed.Parser) (*gofeed.Feed, error) {
 // Parameters:
 //   - rssLink : RSS链接地址
 //   - parser  : gofeed.Parser 实例，用于解析RSS数据
+//   - timeout : 请求超时时间
+//   - info    : 用于记录抓取附加信息，可为 nil
 //
 // Returns:
 //   - *gofeed.Feed: 解析后的Feed对象
 //   - error       : 若抓取或解析失败，则返回错误
-func fetchFeedWithFix(rssLink string, parser *gofeed.Parser) (*gofeed.Feed, error) {
-	// 自定义HTTP客户端，允许跳过SSL证书验证，超时10秒
+func fetchFeedWithFix(rssLink string, parser *gofeed.Parser, timeout time.Duration, info *fetchInfo, insecure bool) (*gofeed.Feed, error) {
+	// 自定义HTTP客户端，白名单内的订阅允许跳过SSL证书验证
 	client := &http.Client{
-		Transport: &http.Transport{
+		Transport: wrapTransport(&http.Transport{
 			// InsecureSkipVerify: true 表示跳过对证书合法性的检测
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-		Timeout: 10 * time.Second,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
+		}),
+		Timeout: timeout,
+	}
+	if insecure && strings.HasPrefix(strings.ToLower(rssLink), "https://") {
+		logInsecureFetch(rssLink)
+		if info != nil {
+			info.Insecure = true
+		}
 	}
 
 	// 构造请求并设置自定义User-Agent
@@ -367,27 +646,161 @@ func fetchFeedWithFix(rssLink string, parser *gofeed.Parser) (*gofeed.Feed, erro
 	}
 
 	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+	setAcceptEncoding(req)
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, classifyNetError(err)
 	}
 	defer resp.Body.Close()
+	info.recordResponse(resp)
+	activeDump.writeHeaders(rssLink, info.attempt(), resp)
 
 	// 如果状态码不是 200，视为获取失败
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		return nil, newHTTPStatusError(resp)
 	}
 
 	// 读取响应数据
-	rawData, err := io.ReadAll(resp.Body)
+	rawData, err := readFeedBody(resp)
 	if err != nil {
 		return nil, err
 	}
+	activeDump.write(rssLink, info.attempt(), "raw.xml", rawData)
+
+	// 返回的是 HTML 页面时给出诊断信息，而不是笼统的解析失败
+	if err := sniffHTMLPage(rssLink, resp.Header.Get("Content-Type"), rawData); err != nil {
+		return nil, err
+	}
 
 	// 移除响应中的非法XML字符
 	cleanData := removeInvalidXMLChars(rawData)
-	return parser.ParseString(string(cleanData))
+	activeDump.write(rssLink, info.attempt(), "clean.xml", cleanData)
+	return parseFeedData(parser, cleanData)
+}
+
+// feedSource 返回订阅格式对应的文章来源标识：rss、atom 或 jsonfeed
+func feedSource(feed *gofeed.Feed) string {
+	if feed.FeedType == "json" {
+		return "jsonfeed"
+	}
+	return feed.FeedType
+}
+
+// 抓取RSS时的错误类别
+//
+// Description:
+//
+//	抓取函数返回的错误会通过 %w 包装这些哨兵错误，统计与分类时使用 errors.Is 判断，
+//	不依赖错误信息的措辞或语言
+var (
+	ErrParse     = errors.New("rss parse failed")  // 响应内容无法解析为 RSS/Atom
+	ErrEmptyFeed = errors.New("rss body is empty") // 服务器返回 200 但响应内容为空
+	ErrTimeout   = errors.New("rss fetch timeout") // 请求或读取响应超时
+)
+
+// parseFeedData 解析 RSS 内容，内容为空时返回 ErrEmptyFeed，解析失败时返回包装了 ErrParse 的错误
+func parseFeedData(parser *gofeed.Parser, data []byte) (*gofeed.Feed, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, ErrEmptyFeed
+	}
+	feed, err := parser.ParseString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrParse, err)
+	}
+	return feed, nil
+}
+
+// classifyNetError 为超时的网络错误附加 ErrTimeout，其余错误原样返回
+func classifyNetError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+// recordResponse 从响应中记录附加信息，info 为 nil 时忽略
+//
+// Description:
+//
+//	对于 HTTPS 响应，记录服务器证书（证书链第一张）的到期时间；同时记录 ETag，用于判断订阅内容是否变化
+//	响应体被替换为计数的 Reader，读取的字节数累加到 info.Bytes，用于流量统计
+func (info *fetchInfo) recordResponse(resp *http.Response) {
+	if info == nil || resp == nil {
+		return
+	}
+	if resp.Request != nil {
+		info.FinalURL = resp.Request.URL.String()
+	}
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		info.CertExpiry = resp.TLS.PeerCertificates[0].NotAfter
+	}
+	info.ETag = resp.Header.Get("ETag")
+	if resp.Body != nil {
+		resp.Body = countingBody{ReadCloser: resp.Body, n: &info.Bytes}
+	}
+}
+
+// attempt 返回当前的尝试序号，info 为 nil 时返回 0
+func (info *fetchInfo) attempt() int {
+	if info == nil {
+		return 0
+	}
+	return info.Attempts
+}
+
+// maxRetryAfter 愿意遵循的 Retry-After 上限，超过时直接放弃本次抓取
+const maxRetryAfter = time.Minute
+
+// httpStatusError 抓取RSS时服务器返回非 200 状态码
+type httpStatusError struct {
+	StatusCode int           // HTTP 状态码
+	RetryAfter time.Duration // Retry-After 要求的等待时长，未提供时为 0
+}
+
+// newHTTPStatusError 根据响应生成 httpStatusError，并解析 Retry-After
+func newHTTPStatusError(resp *http.Response) *httpStatusError {
+	return &httpStatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+func (e *httpStatusError) Error() string {
+	msg := fmt.Sprintf("http error: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter)
+	}
+	return msg
+}
+
+// authRequired 是否为需要授权的响应（401 Unauthorized / 403 Forbidden）
+func (e *httpStatusError) authRequired() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// throttled 是否为限流响应（429 Too Many Requests / 503 Service Unavailable）
+func (e *httpStatusError) throttled() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable
+}
+
+// parseRetryAfter 解析 Retry-After 头，支持秒数和 HTTP 日期两种格式，无法解析或已过期时返回 0
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
 }
 
 // removeInvalidXMLChars 过滤掉数据中非法的XML控制字符
@@ -0,0 +1,59 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: feed_freshness.go
+// Description: 记录每个订阅内容最近一次变化的时间（blogs.json 的 last_changed_at），
+// 与文章自带的发布时间无关，用于衡量博客的实际更新情况、发现时间戳错误的订阅
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// feedContentHash 计算订阅文章内容的哈希
+//
+// Description:
+//
+//	只使用文章的标识、链接、标题、正文和时间字段，不包含 lastBuildDate 等订阅级字段，
+//	很多博客程序每次构建都会刷新这些字段，文章没有变化时哈希保持不变
+func feedContentHash(feed *gofeed.Feed) string {
+	h := sha256.New()
+	for _, item := range feed.Items {
+		for _, field := range []string{item.GUID, item.Link, item.Title, item.Published, item.Updated, item.Description, item.Content} {
+			h.Write([]byte(field))
+			h.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// setFeedFreshness 更新博客订阅内容的变化记录
+//
+// Description:
+//
+//	抓取失败时沿用上次的记录；服务器返回的 ETag 与上次相同时视为未变化（不再比较内容，
+//	避免订阅中的随机内容被误判为更新）；否则比较内容哈希，不同则将 last_changed_at 记为本次时间。
+//	首次记录的订阅同样记为本次时间
+//
+// Parameters:
+//   - b    : 待更新的博客状态
+//   - r    : 本次抓取结果
+//   - prev : 上次 blogs.json 中的记录
+//   - now  : 本次检查时间
+func setFeedFreshness(b *BlogStatus, r feedResult, prev BlogStatus, now time.Time) {
+	b.ContentHash, b.ETag, b.LastChangedAt = prev.ContentHash, prev.ETag, prev.LastChangedAt
+	if r.Feed == nil || r.Err != nil {
+		return
+	}
+	if r.ETag != "" && r.ETag == prev.ETag && prev.LastChangedAt != "" {
+		return
+	}
+	hash := feedContentHash(r.Feed)
+	if hash != prev.ContentHash || prev.LastChangedAt == "" {
+		b.LastChangedAt = now.Format("2006-01-02 15:04:05")
+	}
+	b.ContentHash, b.ETag = hash, r.ETag
+}
@@ -0,0 +1,104 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: feed_list.go
+// Description: RSS 列表文件的格式识别（txt / OPML）及多个列表的合并去重
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// opmlOutline OPML 中的一个 outline 节点，带 xmlUrl 的为订阅，否则视为分组文件夹
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	HTMLURL  string        `xml:"htmlUrl,attr"`
+	Desc     string        `xml:"description,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// opmlDocument OPML 文件的根节点
+type opmlDocument struct {
+	Body struct {
+		Outlines []opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+// parseFeedList 解析单个 RSS 列表文件
+//
+// Description:
+//
+//	内容以 <opml 开头（可带 XML 声明）时按 OPML 解析，否则按每行一个订阅的 txt 格式解析
+func parseFeedList(data []byte) ([]feedEntry, error) {
+	if isOPML(data) {
+		return parseOPMLEntries(data)
+	}
+	return parseFeedEntries(data), nil
+}
+
+// isOPML 判断列表内容是否为 OPML
+func isOPML(data []byte) bool {
+	head := bytes.TrimSpace(data)
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	return bytes.HasPrefix(head, []byte("<")) && bytes.Contains(bytes.ToLower(head), []byte("<opml"))
+}
+
+// parseOPMLEntries 将 OPML 中的订阅转换为订阅条目
+//
+// Description:
+//
+//	订阅所在文件夹的名称作为分组，嵌套文件夹取最近一层，outline 的 description 属性作为备注；
+//	OPML 无法表达的配置项均为零值
+func parseOPMLEntries(data []byte) ([]feedEntry, error) {
+	var doc opmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, wrapErrorf(err, "解析OPML失败")
+	}
+	var entries []feedEntry
+	var walk func(outlines []opmlOutline, group string)
+	walk = func(outlines []opmlOutline, group string) {
+		for _, o := range outlines {
+			if u := strings.TrimSpace(o.XMLURL); u != "" {
+				entries = append(entries, feedEntry{URL: u, Group: group, Note: strings.TrimSpace(o.Desc)})
+				continue
+			}
+			folder := strings.TrimSpace(o.Title)
+			if folder == "" {
+				folder = strings.TrimSpace(o.Text)
+			}
+			walk(o.Outlines, folder)
+		}
+	}
+	walk(doc.Body.Outlines, "")
+	return entries, nil
+}
+
+// mergeFeedEntries 按顺序合并多个订阅列表
+//
+// Description:
+//
+//	按 urlnorm.Key 去重（忽略协议、www 前缀和末尾斜杠），重复的订阅保留先出现的条目及其配置
+func mergeFeedEntries(lists ...[]feedEntry) []feedEntry {
+	var merged []feedEntry
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, e := range list {
+			key := urlnorm.Key(e.URL)
+			if seen[key] {
+				fmt.Printf("[INFO] RSS列表中存在重复订阅, 已忽略: %s\n", e.URL)
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, e)
+		}
+	}
+	return merged
+}
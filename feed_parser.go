@@ -5,13 +5,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
-	"github.com/mmcdole/gofeed"
 	"golang.org/x/net/html"
 )
 
@@ -44,117 +45,89 @@ func parseTime(timeStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("无法解析时间: %s", timeStr)
 }
 
-// getFeedAvatarURL 尝试从 feed.Image 或博客主页获取头像地址
+// 抓取博客主页解析头像时的限制：单个主页无响应或体积过大时不应长时间占用抓取协程
+const (
+	logoPageTimeout  = 8 * time.Second // 单次主页请求超时
+	logoPageMaxBytes = 256 << 10       // 最多读取的主页字节数，<head> 通常远小于该值
+)
+
+// pageClient 抓取博客主页共用的 HTTP 客户端，复用连接
+var pageClient = &http.Client{Timeout: logoPageTimeout}
+
+// fetchHeadImages 抓取页面并从<head>中解析站点图标与 og:image
 //
 // Description:
 //
-//	该函数首先尝试读取 feed.Image.URL，如果不存在，则尝试从博客主页（feed.Link）中解析常见的 icon 图标
-//
-// Parameters:
-//   - feed: gofeed.Feed 指针, 其中包含 Image, Link等字段
+//	该函数通过 HTTP GET 请求获取页面内容，流式解析其 HTML，
+//	在<head>标签中寻找<link rel="icon">与<meta property="og:image">，读到 </head> 即停止
+//	请求使用共享的 pageClient，超时 logoPageTimeout，最多读取 logoPageMaxBytes 字节；
+//	robots.txt 禁止抓取、请求失败或状态码不是 200 时均返回空字符串
 //
 // Returns:
-//   - string: 若能获取到有效头像，则返回其URL；若无法获取则返回空字符串
-func getFeedAvatarURL(feed *gofeed.Feed) string {
-	// 优先使用 <Image> 标签内的 URL
-	if feed.Image != nil && feed.Image.URL != "" {
-		return feed.Image.URL
+//   - iconHref : <link rel="icon"> 的绝对地址，未找到时为空
+//   - ogImage  : og:image 的绝对地址，未找到时为空
+func fetchHeadImages(ctx context.Context, pageURL string, robots *robotsChecker) (iconHref, ogImage string) {
+	if !robots.allowed(ctx, pageURL) {
+		return "", ""
 	}
-	// 若没有 image 则尝试抓取博客主页获取
-	if feed.Link != "" {
-		return fetchBlogLogo(feed.Link)
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return "", ""
 	}
-	return ""
-}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
 
-// fetchBlogLogo 尝试抓取博客主页, 并从<head>中获取常见的 icon 或 meta og:image
-//
-// Description:
-//
-//	该函数通过 HTTP GET 请求获取博客首页内容，解析其 HTML，
-//	在<head>标签中寻找<link rel="icon">或<meta property="og:image">等信息
-//	如果解析失败或未找到，则回退到 favicon.ico
-func fetchBlogLogo(blogURL string) string {
-	// 如果获取失败，则直接回退到 favicon.ico
-	resp, err := http.Get(blogURL)
+	resp, err := pageClient.Do(req)
 	if err != nil {
-		return fallbackFavicon(blogURL)
+		return "", ""
 	}
 	defer resp.Body.Close()
 
-	// 如果状态码不是 200，视为获取主页失败
+	// 如果状态码不是 200，视为获取页面失败
 	if resp.StatusCode != 200 {
-		return fallbackFavicon(blogURL)
-	}
-
-	// 解析HTML文档
-	doc, err := html.Parse(resp.Body)
-	if err != nil {
-		return fallbackFavicon(blogURL)
-	}
-
-	var iconHref, ogImage string
-
-	// 递归函数，用来遍历 HTML 节点，寻找 <link> 和 <meta> 标签
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		// 如果当前节点是元素节点
-		if n.Type == html.ElementNode {
-			// 获取当前节点的标签名称，并转为小写
-			tagName := strings.ToLower(n.Data)
-
-			// 针对 <link> 标签查找 iconHref
-			if tagName == "link" {
-				var relVal, hrefVal string
-				for _, attr := range n.Attr {
-					key := strings.ToLower(attr.Key)
-					val := attr.Val
-					if key == "rel" {
-						relVal = strings.ToLower(val)
-					}
-					if key == "href" {
-						hrefVal = val
-					}
+		return "", ""
+	}
+
+	// 流式解析HTML，只处理 <head> 部分，遇到 </head> 或 <body> 即停止读取
+	z := html.NewTokenizer(io.LimitReader(resp.Body, logoPageMaxBytes))
+	for done := false; !done; {
+		switch z.Next() {
+		case html.ErrorToken:
+			// 读取结束、超过字节上限或遇到错误
+			done = true
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "head" {
+				done = true
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tagName := string(name)
+			if tagName == "body" {
+				done = true
+				break
+			}
+			if !hasAttr || (tagName != "link" && tagName != "meta") {
+				continue
+			}
+			attrs := make(map[string]string)
+			for {
+				key, val, more := z.TagAttr()
+				attrs[strings.ToLower(string(key))] = string(val)
+				if !more {
+					break
 				}
-				// 如果 rel 包含 icon 字段，并且 href 不为空，则视为站点图标
-				if strings.Contains(relVal, "icon") && hrefVal != "" && iconHref == "" {
-					iconHref = hrefVal
+			}
+			// 针对 <link> 标签查找 iconHref：rel 包含 icon 且 href 不为空，则视为站点图标
+			if tagName == "link" {
+				if strings.Contains(strings.ToLower(attrs["rel"]), "icon") && attrs["href"] != "" && iconHref == "" {
+					iconHref = makeAbsoluteURL(pageURL, attrs["href"])
 				}
-			} else if tagName == "meta" {
+			} else if strings.ToLower(attrs["property"]) == "og:image" && attrs["content"] != "" {
 				// 针对 <meta> 标签查找 og:image
-				var propVal, contentVal string
-				for _, attr := range n.Attr {
-					key := strings.ToLower(attr.Key)
-					val := attr.Val
-					if key == "property" {
-						propVal = strings.ToLower(val)
-					}
-					if key == "content" {
-						contentVal = val
-					}
-				}
-				if propVal == "og:image" && contentVal != "" {
-					ogImage = contentVal
-				}
+				ogImage = makeAbsoluteURL(pageURL, attrs["content"])
 			}
 		}
-		// 递归遍历子节点
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
-		}
-	}
-	f(doc)
-
-	// 如果找到 iconHref，则返回绝对路径
-	if iconHref != "" {
-		return makeAbsoluteURL(blogURL, iconHref)
 	}
-	// 如果没找到 <link rel="icon">，尝试使用 og:image
-	if ogImage != "" {
-		return makeAbsoluteURL(blogURL, ogImage)
-	}
-	// 仍未找到，则回退到 favicon.ico
-	return fallbackFavicon(blogURL)
+	return iconHref, ogImage
 }
 
 // fallbackFavicon 返回 "scheme://host/favicon.ico"
@@ -191,23 +164,38 @@ func makeAbsoluteURL(baseStr, refStr string) string {
 	return baseURL.ResolveReference(refURL).String()
 }
 
-// checkURLAvailable 通过HEAD请求检查URL是否可正常访问(返回200)
+// htmlToText 提取 HTML 片段中的纯文本，并截断到 maxRunes 个字符
 //
 // Description:
 //
-//	仅发送 HEAD 请求以确认资源是否存在且可访问，若返回状态码为200，则视为可用
-func checkURLAvailable(urlStr string) (bool, error) {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-	req, err := http.NewRequest("HEAD", urlStr, nil)
-	if err != nil {
-		return false, err
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return false, err
+//	RSS 的 description/content 通常是 HTML，这里只保留文本节点并合并空白，
+//	跳过 script/style 中的内容
+func htmlToText(s string, maxRunes int) string {
+	z := html.NewTokenizer(strings.NewReader(s))
+	var sb strings.Builder
+	skip := 0
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			text := []rune(strings.Join(strings.Fields(sb.String()), " "))
+			if maxRunes > 0 && len(text) > maxRunes {
+				text = text[:maxRunes]
+			}
+			return string(text)
+		case html.StartTagToken:
+			if name, _ := z.TagName(); string(name) == "script" || string(name) == "style" {
+				skip++
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); (string(name) == "script" || string(name) == "style") && skip > 0 {
+				skip--
+			}
+		case html.TextToken:
+			if skip == 0 {
+				sb.Write(z.Text())
+				sb.WriteByte(' ')
+			}
+		}
 	}
-	defer resp.Body.Close()
-	return resp.StatusCode == http.StatusOK, nil
 }
@@ -0,0 +1,128 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: feed_sniff.go
+// Description: 识别 RSS 地址返回的是 HTML 页面（常见于把博客主页填成 RSS 地址），并通过自动发现给出正确的订阅地址
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlPageError RSS 地址返回了 HTML 页面而不是订阅内容
+type htmlPageError struct {
+	URL        string // 配置的 RSS 地址
+	Discovered string // 通过 <link rel="alternate"> 自动发现的订阅地址，未发现时为空
+	LoginWall  bool   // 页面为登录/付费墙（含密码输入框或常见的登录、订阅提示）
+}
+
+func (e *htmlPageError) Error() string {
+	if e.LoginWall {
+		return tr("返回的是登录或付费订阅页面, 订阅可能需要授权访问")
+	}
+	if e.Discovered != "" {
+		return trf("看起来是 HTML 页面, 是否应为 %s ?", e.Discovered)
+	}
+	return tr("看起来是 HTML 页面, 页面中未发现订阅地址")
+}
+
+// feedMimeTypes 自动发现时认可的订阅类型
+var feedMimeTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/json":      true,
+	"application/xml":       true,
+	"text/xml":              true,
+}
+
+// feedRootPrefixes 订阅内容开头（跳过空白和 BOM）可能出现的 XML 声明、根元素或 JSON Feed 的 {，小写
+var feedRootPrefixes = [][]byte{[]byte("<?xml"), []byte("<rss"), []byte("<feed"), []byte("<rdf:rdf"), []byte("{")}
+
+// sniffHTMLPage 判断响应是否为 HTML 页面，是则返回 htmlPageError，否则返回 nil
+//
+// Description:
+//
+//	只按内容判断：开头（跳过空白和 BOM）为 <!DOCTYPE html / <html 时视为 HTML 页面；
+//	以 XML 声明、<rss、<feed、<rdf:RDF 或 JSON Feed 的 { 开头的内容即使 Content-Type 为 text/html（常见于虚拟主机）
+//	也交给解析器处理；Content-Type 不作为判断依据，声明为其他类型的 HTML 页面只输出警告
+func sniffHTMLPage(feedURL, contentType string, data []byte) error {
+	head := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	lower := bytes.ToLower(head)
+	for _, prefix := range feedRootPrefixes {
+		if bytes.HasPrefix(lower, prefix) {
+			return nil
+		}
+	}
+	if !bytes.HasPrefix(lower, []byte("<!doctype html")) && !bytes.HasPrefix(lower, []byte("<html")) {
+		return nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "" && mediaType != "text/html" {
+		fmt.Printf("[WARN] %s 的 Content-Type 为 %s, 内容却是 HTML 页面\n", feedURL, mediaType)
+	}
+	return &htmlPageError{URL: feedURL, Discovered: discoverFeedLink(feedURL, data), LoginWall: looksLikeLoginWall(data)}
+}
+
+// loginWallMarkers 登录/付费墙页面中常见的文字（小写）
+var loginWallMarkers = []string{
+	`type="password"`, `type='password'`, "type=password",
+	"sign in to continue", "log in to continue", "subscribe to continue", "members only", "this content is for members",
+	"登录后查看", "登录后阅读", "请先登录", "会员专享", "订阅后阅读", "付费阅读",
+}
+
+// looksLikeLoginWall 判断 HTML 页面是否为登录/付费墙
+func looksLikeLoginWall(data []byte) bool {
+	if len(data) > 256<<10 {
+		data = data[:256<<10]
+	}
+	return containsAny(strings.ToLower(string(data)), loginWallMarkers)
+}
+
+// discoverFeedLink 从 HTML 的 <head> 中查找 <link rel="alternate" type="application/rss+xml" href="...">，返回绝对地址
+func discoverFeedLink(pageURL string, data []byte) string {
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "head" {
+				return ""
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) == "body" {
+				return ""
+			}
+			if string(name) != "link" || !hasAttr {
+				continue
+			}
+			attrs := make(map[string]string)
+			for {
+				key, val, more := z.TagAttr()
+				attrs[strings.ToLower(string(key))] = string(val)
+				if !more {
+					break
+				}
+			}
+			rels := strings.Fields(strings.ToLower(attrs["rel"]))
+			isAlternate := false
+			for _, r := range rels {
+				if r == "alternate" {
+					isAlternate = true
+				}
+			}
+			mediaType := strings.ToLower(strings.TrimSpace(attrs["type"]))
+			if isAlternate && feedMimeTypes[mediaType] && attrs["href"] != "" {
+				return makeAbsoluteURL(pageURL, attrs["href"])
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: feed_sniff_test.go
+// Description: sniffHTMLPage 的测试，Content-Type 为 text/html 的订阅内容不能被误判为 HTML 页面
+
+package main
+
+import "testing"
+
+func TestSniffHTMLPage(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantHTML    bool
+	}{
+		{"xml prolog", "text/html", `<?xml version="1.0"?><rss></rss>`, false},
+		{"rss served as html", "text/html; charset=utf-8", "\n  <rss version=\"2.0\"><channel></channel></rss>", false},
+		{"atom served as html", "text/html", `<feed xmlns="http://www.w3.org/2005/Atom"></feed>`, false},
+		{"rdf served as html", "text/html", `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"></rdf:RDF>`, false},
+		{"json feed served as html", "text/html", "\xef\xbb\xbf{\"version\":\"https://jsonfeed.org/version/1.1\"}", false},
+		{"doctype page", "text/html", `<!DOCTYPE html><html><head></head></html>`, true},
+		{"html page as xml", "application/xml", `<html><head></head></html>`, true},
+		{"rss with xml type", "application/rss+xml", `<rss></rss>`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sniffHTMLPage("https://example.com/feed", tt.contentType, []byte(tt.body))
+			if got := err != nil; got != tt.wantHTML {
+				t.Fatalf("sniffHTMLPage() = %v, want HTML page = %v", err, tt.wantHTML)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: fetch_cache.go
+// Description: 跨运行的抓取缓存，记录远程文件的 ETag/Last-Modified，使用条件请求避免重复下载
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// cacheEntry 单个 URL 的缓存记录
+type cacheEntry struct {
+	ETag         string    `json:"etag,omitempty"`          // 上次响应的 ETag
+	LastModified string    `json:"last_modified,omitempty"` // 上次响应的 Last-Modified
+	Body         []byte    `json:"body"`                    // 上次响应的内容
+	FetchedAt    time.Time `json:"fetched_at"`              // 上次实际下载的时间
+}
+
+// fetchCache 抓取缓存，序列化为 JSON 后保存到 cacheStore（本地文件、COS 或 GitHub）
+//
+// Description:
+//
+//	头像映射、RSS 列表等文件一年只变动几次，却每次运行都要下载，
+//	通过缓存 ETag 并发送条件请求，服务器返回 304 时直接复用上次的内容
+//	所有方法对 nil 接收者安全，nil 表示不使用缓存
+type fetchCache struct {
+	store      cacheStore      // 存储后端，nil 表示只在本次运行内使用
+	loaded     []byte          // 加载时的原始内容，内容未变化时跳过保存
+	used       map[string]bool // 本次运行读取过的 Entries 键，用于清理不再使用的远程文件缓存
+	mu         sync.Mutex
+	Entries    map[string]*cacheEntry `json:"entries"`
+	LinkChecks map[string]linkCheck   `json:"link_checks,omitempty"` // 文章链接失效检测记录
+
+	// 内容增强结果（摘要等），键为 "类型:文章链接"
+	Enrichments map[string]string `json:"enrichments,omitempty"`
+
+	// 订阅上次正常时的特征，用于僵尸内容检测
+	FeedProfiles map[string]feedProfile `json:"feed_profiles,omitempty"`
+
+	// 已知订阅及首次出现时间，用于识别新加入的订阅
+	KnownFeeds map[string]time.Time `json:"known_feeds,omitempty"`
+
+	// 头像解析结果，键为 urlnorm.Key(RSS 地址)，平台订阅为平台的缓存键
+	Avatars map[string]avatarRecord `json:"avatars,omitempty"`
+
+	// 按头像地址缓存的可用性检查记录
+	AvatarChecks map[string]linkCheck `json:"avatar_checks,omitempty"`
+
+	// 文章链接的 rel=canonical 规范地址
+	CanonicalLinks map[string]canonicalRecord `json:"canonical_links,omitempty"`
+
+	// 新文章通知摘要的待发送队列与已通知记录
+	Digest *digestState `json:"digest,omitempty"`
+
+	// 健康订阅的当前问题与最近事件
+	Health *healthState `json:"health,omitempty"`
+
+	// 新收录文章的 Webmention/Pingback 发送状态，键为文章链接
+	Webmentions map[string]webmentionRecord `json:"webmentions,omitempty"`
+
+	// 告警通知的最近发送时间，键为去重键，用于 NOTIFY_DEDUP_WINDOW
+	Notified map[string]time.Time `json:"notified,omitempty"`
+
+	// 已归档订阅最后的文章副本，键为 urlnorm.Key(RSS 地址)，上次的 data.json 中没有时使用
+	Archived map[string][]Article `json:"archived,omitempty"`
+
+	// 最近的抓取耗时（毫秒，-1 表示网络错误），键为域名，用于 ADAPTIVE_TIMEOUT
+	Latencies map[string][]int `json:"latencies,omitempty"`
+
+	// 连续抓取失败的订阅及隔离状态，键为 RSS 地址，用于 QUARANTINE_AFTER
+	Quarantine map[string]quarantineRecord `json:"quarantine,omitempty"`
+
+	// 失联域名上次用 HTTP_TIMEOUT 试探的时间，键为域名，用于 ADAPTIVE_TIMEOUT
+	LatencyProbes map[string]time.Time `json:"latency_probes,omitempty"`
+
+	// 各配置方案上次运行时仍在使用的订阅、文章与远程文件，键为方案名称（未使用方案时为空），用于 prune
+	PruneScopes map[string]pruneScope `json:"prune_scopes,omitempty"`
+
+	// 上次写回存储后端的时间，CACHE_STORE=github 时用于限制提交频率
+	SavedAt time.Time `json:"saved_at,omitzero"`
+}
+
+// avatarRecord 单个订阅的头像解析结果
+type avatarRecord struct {
+	Avatar    string    `json:"avatar"`     // 头像地址，空字符串表示没有头像，"BROKEN" 表示无法访问
+	CheckedAt time.Time `json:"checked_at"` // 解析并检查可用性的时间
+}
+
+// loadFetchCache 从存储后端加载抓取缓存，尚未保存过、读取失败或内容损坏时返回空缓存
+func loadFetchCache(ctx context.Context, store cacheStore) *fetchCache {
+	c := &fetchCache{store: store, Entries: make(map[string]*cacheEntry)}
+	if store == nil {
+		return c
+	}
+	data, err := store.Load(ctx)
+	if err != nil {
+		fmt.Printf("[WARN] 读取抓取缓存失败: %s: %v. 将使用空缓存.\n", store, err)
+		return c
+	}
+	if len(data) == 0 {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		fmt.Printf("[WARN] 解析抓取缓存失败: %v. 将使用空缓存.\n", err)
+		c.Entries = make(map[string]*cacheEntry)
+	}
+	if c.Entries == nil {
+		c.Entries = make(map[string]*cacheEntry)
+	}
+	c.loaded = data
+	return c
+}
+
+// cacheVolatileKeys 每次运行都可能变化、晚一些保存也不影响结果的缓存字段：抓取耗时与各类检查时间
+var cacheVolatileKeys = []string{"saved_at", "latencies", "checked_at", "refreshed_at"}
+
+// save 将缓存写回存储后端，内容与加载时相同则跳过
+//
+// Description:
+//
+//	存储后端实现了 saveInterval（CACHE_STORE=github，每次保存都是一次提交）时，
+//	距上次保存不足该间隔且只有 cacheVolatileKeys 中的字段变化时也跳过，这些变化在下一次保存时一并写入
+func (c *fetchCache) save(ctx context.Context) error {
+	if c == nil || c.store == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lastSaved := c.SavedAt
+	c.SavedAt = time.Now()
+	data, err := json.Marshal(c)
+	if err != nil {
+		c.SavedAt = lastSaved
+		return wrapErrorf(err, "序列化抓取缓存失败")
+	}
+	if sameContent(c.loaded, data, []string{"saved_at"}) {
+		c.SavedAt = lastSaved
+		return nil
+	}
+	if s, ok := c.store.(interface{ saveInterval() time.Duration }); ok &&
+		time.Since(lastSaved) < s.saveInterval() && sameContent(c.loaded, data, cacheVolatileKeys) {
+		c.SavedAt = lastSaved
+		return nil
+	}
+	if err := c.store.Save(ctx, data); err != nil {
+		c.SavedAt = lastSaved
+		return wrapErrorf(err, "写入抓取缓存失败: %s", c.store)
+	}
+	c.loaded = data
+	return nil
+}
+
+// get 读取指定 URL 的缓存记录
+func (c *fetchCache) get(url string) *cacheEntry {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.used == nil {
+		c.used = make(map[string]bool)
+	}
+	c.used[url] = true
+	return c.Entries[url]
+}
+
+// put 写入指定 URL 的缓存记录
+func (c *fetchCache) put(url string, entry *cacheEntry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Entries[url] = entry
+}
+
+// cacheLookup 加锁读取缓存中某个记录表（field 返回其地址）里 key 对应的记录，c 为 nil 时返回零值与 false
+func cacheLookup[K comparable, V any](c *fetchCache, field func(*fetchCache) *map[K]V, key K) (V, bool) {
+	if c == nil {
+		var zero V
+		return zero, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := (*field(c))[key]
+	return v, ok
+}
+
+// cacheAssign 加锁写入缓存中某个记录表里 key 对应的记录，记录表为 nil 时先创建，c 为 nil 时不做任何事
+func cacheAssign[K comparable, V any](c *fetchCache, field func(*fetchCache) *map[K]V, key K, value V) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := field(c)
+	if *m == nil {
+		*m = make(map[K]V)
+	}
+	(*m)[key] = value
+}
+
+// enrichment 读取某篇文章已生成的增强内容，kind 如 "summary"
+func (c *fetchCache) enrichment(kind, link string) (string, bool) {
+	return cacheLookup(c, func(c *fetchCache) *map[string]string { return &c.Enrichments }, kind+":"+link)
+}
+
+// setEnrichment 保存某篇文章的增强内容
+func (c *fetchCache) setEnrichment(kind, link, value string) {
+	cacheAssign(c, func(c *fetchCache) *map[string]string { return &c.Enrichments }, kind+":"+link, value)
+}
+
+// avatarNegativeTTL 没有找到头像或头像无法访问（"BROKEN"）的结果最多缓存的时长，
+// 避免一次临时的网络错误让博客在整个 AVATAR_CACHE_TTL 内都使用默认头像
+const avatarNegativeTTL = time.Hour
+
+// avatar 读取订阅的头像解析结果，ttl <= 0 或已过期时返回 false，没有可用头像的结果最多缓存 avatarNegativeTTL
+func (c *fetchCache) avatar(key string, ttl time.Duration) (string, bool) {
+	if key == "" || ttl <= 0 {
+		return "", false
+	}
+	rec, ok := cacheLookup(c, func(c *fetchCache) *map[string]avatarRecord { return &c.Avatars }, key)
+	if ok && (rec.Avatar == "" || rec.Avatar == "BROKEN") {
+		ttl = min(ttl, avatarNegativeTTL)
+	}
+	if !ok || time.Since(rec.CheckedAt) > ttl {
+		return "", false
+	}
+	return rec.Avatar, true
+}
+
+// setAvatar 保存订阅的头像解析结果
+func (c *fetchCache) setAvatar(key, avatar string) {
+	if key == "" {
+		return
+	}
+	cacheAssign(c, func(c *fetchCache) *map[string]avatarRecord { return &c.Avatars }, key, avatarRecord{Avatar: avatar, CheckedAt: time.Now()})
+}
+
+// errRemoteNotFound conditionalGet 请求的地址返回 404，调用方可据此把文件不存在与请求失败区分开
+var errRemoteNotFound = errors.New("HTTP状态码: 404")
+
+// conditionalGet 使用条件请求下载 URL 内容
+//
+// Description:
+//
+//	若缓存中存在该 URL 的 ETag/Last-Modified，则携带 If-None-Match/If-Modified-Since 请求，
+//	服务器返回 304 时直接返回缓存内容；返回 200 时更新缓存
+//
+// Returns:
+//   - []byte : 响应内容（或缓存内容）
+//   - bool   : 是否命中缓存（304）
+//   - error  : 请求失败或状态码异常时返回，404 时为 errRemoteNotFound
+func (c *fetchCache) conditionalGet(ctx context.Context, client *http.Client, url string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	cached := c.get(url)
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.Body, true, nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, errRemoteNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("HTTP状态码: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	if resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "" {
+		c.put(url, &cacheEntry{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Body:         body,
+			FetchedAt:    time.Now(),
+		})
+	}
+	return body, false, nil
+}
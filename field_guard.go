@@ -0,0 +1,90 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: field_guard.go
+// Description: 输出前校验文章字段，截断超长标题、替换危险或畸形链接，避免异常订阅破坏下游前端
+
+package main
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxTitleRunes    = 300  // 标题最大字符数
+	maxBlogNameRunes = 100  // 博客名称最大字符数
+	maxLinkLength    = 2048 // 文章链接最大字节数
+)
+
+// guardArticleFields 校验并修正文章的标题、博客名称和链接
+//
+// Description:
+//
+//	标题与名称去掉控制字符后按最大字符数截断；链接为相对地址时按博客主页补全，
+//	协议不是 http/https（如 javascript:、data:）或超长的链接替换为博客主页的根地址
+//
+// Parameters:
+//   - a        : 待修正的文章
+//   - homepage : 博客主页（RSS 中的 link），为空时使用 RSS 地址所在站点
+//
+// Returns:
+//   - []string : 每项修正的说明，为空表示字段正常
+func guardArticleFields(a *Article, homepage string) []string {
+	var issues []string
+	var fixed bool
+	if a.Title, fixed = clampText(a.Title, maxTitleRunes); fixed {
+		issues = append(issues, trf("标题过长或含控制字符, 已截断为 %d 字", maxTitleRunes))
+	}
+	if a.BlogName, fixed = clampText(a.BlogName, maxBlogNameRunes); fixed {
+		issues = append(issues, trf("博客名称过长或含控制字符, 已截断为 %d 字", maxBlogNameRunes))
+	}
+
+	base, err := url.Parse(homepage)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") {
+		if base, err = url.Parse(a.Feed); err != nil {
+			base = &url.URL{}
+		}
+	}
+	root := siteRoot(base.String())
+	link := strings.TrimSpace(a.Link)
+	u, err := url.Parse(link)
+	switch {
+	case link == "":
+	case err != nil:
+		issues = append(issues, tr("链接无法解析, 已替换为博客主页"))
+		a.Link = root
+	case len(link) > maxLinkLength:
+		issues = append(issues, trf("链接过长 (%d 字节), 已替换为博客主页", len(link)))
+		a.Link = root
+	case u.Scheme == "":
+		a.Link = base.ResolveReference(u).String()
+	case u.Scheme != "http" && u.Scheme != "https":
+		issues = append(issues, trf("链接协议不安全 (%s:), 已替换为博客主页", u.Scheme))
+		a.Link = root
+	default:
+		a.Link = link
+	}
+	return issues
+}
+
+// clampText 去掉控制字符并按最大字符数截断（截断时以 … 结尾），返回处理后的文本及是否有修改
+func clampText(s string, maxRunes int) (string, bool) {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			if r == '\n' || r == '\t' || r == '\r' {
+				return ' '
+			}
+			return -1
+		}
+		return r
+	}, s)
+	changed := strings.ContainsFunc(s, func(r rune) bool {
+		return unicode.IsControl(r) && r != '\n' && r != '\t' && r != '\r'
+	})
+	if utf8.RuneCountInString(cleaned) > maxRunes {
+		cleaned = string([]rune(cleaned)[:maxRunes-1]) + "…"
+		changed = true
+	}
+	return cleaned, changed
+}
@@ -0,0 +1,189 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: forever_blog.go
+// Description: 固定数据（foreverblog.json）的读写、校验，以及合并到抓取结果中的逻辑
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// foreverBlogData foreverblog.json 的数据结构
+//
+// Description:
+//
+//	固定数据用于保存已停更、纪念性质等无法再通过 RSS 抓取的博客文章，
+//	每次运行都会原样合并到 data.json 中
+type foreverBlogData struct {
+	Items []Article `json:"items"`
+}
+
+// isRemoteURL 判断地址是否为 HTTP(S) 远程地址
+func isRemoteURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// loadForeverBlog 读取固定数据
+//
+// Description:
+//
+//	ForeverBlogURL 为 HTTP(S) 地址时视为 COS 对象，通过 cache 发送条件请求，未变化时复用缓存内容；
+//	否则视为 GitHub 仓库中的文件路径
+//	文件不存在时返回空数据，不视为错误
+func loadForeverBlog(ctx context.Context, cfg *Config, cache *fetchCache) (*foreverBlogData, error) {
+	var raw []byte
+	if isRemoteURL(cfg.ForeverBlogURL) {
+		client := &http.Client{Timeout: 30 * time.Second}
+		data, notModified, err := cache.conditionalGet(ctx, client, cfg.ForeverBlogURL)
+		if err != nil && !errors.Is(err, errRemoteNotFound) {
+			return nil, wrapErrorf(err, "无法获取COS文件: %s", cfg.ForeverBlogURL)
+		}
+		if notModified {
+			fmt.Printf("[INFO] 固定数据未变化(304), 使用缓存\n")
+		}
+		raw = data
+	} else {
+		content, _, err := getGitHubFileContent(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, cfg.ForeverBlogURL)
+		if err != nil {
+			return nil, wrapErrorf(err, "从 GitHub 获取固定数据失败")
+		}
+		raw = []byte(content)
+	}
+
+	data := &foreverBlogData{}
+	if len(raw) == 0 {
+		return data, nil
+	}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, wrapErrorf(err, "解析固定数据失败")
+	}
+	return data, nil
+}
+
+// saveForeverBlog 保存固定数据到 COS 或 GitHub
+func saveForeverBlog(ctx context.Context, cfg *Config, data *foreverBlogData) error {
+	jsonBytes, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return wrapErrorf(err, "序列化固定数据失败")
+	}
+	if isRemoteURL(cfg.ForeverBlogURL) {
+		return uploadToCos(ctx, cfg.TencentSecretID, cfg.TencentSecretKey, cfg.ForeverBlogURL, jsonBytes, cosUploadOptions{
+			ContentType:  "application/json; charset=utf-8",
+			CacheControl: cfg.CosCacheControl,
+		})
+	}
+	sha, err := getGitHubFileSHA(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, cfg.ForeverBlogURL)
+	if err != nil {
+		return wrapErrorf(err, "获取固定数据文件SHA失败")
+	}
+	return putGitHubFile(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, cfg.ForeverBlogURL, sha,
+		string(jsonBytes), "Update foreverblog.json", cfg.commitSignature())
+}
+
+// normalizeDate 将多种常见日期写法统一为 "Jan 02, 2006" 格式
+//
+// Description:
+//
+//	除了 parseTime 支持的 RSS 时间格式外，还支持 2006-01-02、2006/01/02、Jan 02, 2006 等手写格式
+func normalizeDate(s string) (string, time.Time, error) {
+	s = strings.TrimSpace(s)
+	layouts := []string{
+		"Jan 02, 2006",
+		"2006-01-02",
+		"2006/01/02",
+		"2006.01.02",
+		"2006年01月02日",
+		"2006-01-02 15:04:05",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("Jan 02, 2006"), t, nil
+		}
+	}
+	t, err := parseTime(s)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return t.Format("Jan 02, 2006"), t, nil
+}
+
+// validateForeverEntry 校验并规范化一条固定数据
+//
+// Description:
+//
+//	博客名称、标题、链接、发布时间为必填；链接与头像必须是 http(s) 地址；
+//	发布时间会被规范化为 "Jan 02, 2006"
+func validateForeverEntry(a *Article) error {
+	a.BlogName = strings.TrimSpace(a.BlogName)
+	a.Title = strings.TrimSpace(a.Title)
+	a.Link = strings.TrimSpace(a.Link)
+	a.Avatar = strings.TrimSpace(a.Avatar)
+
+	var missing []string
+	if a.BlogName == "" {
+		missing = append(missing, "name")
+	}
+	if a.Title == "" {
+		missing = append(missing, "title")
+	}
+	if a.Link == "" {
+		missing = append(missing, "link")
+	}
+	if a.Published == "" {
+		missing = append(missing, "published")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("缺少必填字段: %v", missing)
+	}
+
+	if u, err := url.Parse(a.Link); err != nil || !isRemoteURL(a.Link) || u.Host == "" {
+		return fmt.Errorf("链接不是有效的 http(s) 地址: %s", a.Link)
+	}
+	if a.Avatar != "" && !isRemoteURL(a.Avatar) {
+		return fmt.Errorf("头像不是有效的 http(s) 地址: %s", a.Avatar)
+	}
+
+	published, _, err := normalizeDate(a.Published)
+	if err != nil {
+		return fmt.Errorf("无法识别的发布时间: %s", a.Published)
+	}
+	a.Published = published
+	return nil
+}
+
+// mergeForeverItems 将固定数据合并到抓取结果中
+//
+// Description:
+//
+//	与抓取结果按文章链接去重（以抓取结果为准），合并后的条目携带解析出的发布时间，便于统一排序；
+//	没有头像的条目使用 defaultAvatar(分组) 返回的默认头像
+func mergeForeverItems(items []timedArticle, forever []Article, defaultAvatar func(group string) string) []timedArticle {
+	seen := make(map[string]bool, len(items))
+	for _, it := range items {
+		seen[it.article.Link] = true
+	}
+	for _, a := range forever {
+		if seen[a.Link] {
+			continue
+		}
+		_, t, err := normalizeDate(a.Published)
+		if err != nil {
+			fmt.Printf("[WARN] 固定数据发布时间无法解析, 已忽略: %s\n", a.Link)
+			continue
+		}
+		if a.Avatar == "" {
+			a.Avatar = defaultAvatar(a.Group)
+		}
+		a.Source = "manual"
+		seen[a.Link] = true
+		items = append(items, timedArticle{article: a, t: t})
+	}
+	return items
+}
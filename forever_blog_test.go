@@ -0,0 +1,51 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: forever_blog_test.go
+// Description: 固定数据读取的测试
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoadForeverBlogConditionalGet(t *testing.T) {
+	var full, notModified int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/foreverblog.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"items":[{"title":"旧文","link":"https://a/post"}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cache := &fetchCache{Entries: make(map[string]*cacheEntry)}
+	cfg := &Config{ForeverBlogURL: server.URL + "/foreverblog.json"}
+	for range 2 {
+		data, err := loadForeverBlog(context.Background(), cfg, cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data.Items) != 1 || data.Items[0].Link != "https://a/post" {
+			t.Fatalf("Items = %+v, want 1 条 https://a/post", data.Items)
+		}
+	}
+	if full != 1 || notModified != 1 {
+		t.Errorf("完整下载 %d 次、304 %d 次, want 各 1 次", full, notModified)
+	}
+
+	// 文件不存在时返回空数据
+	cfg.ForeverBlogURL = server.URL + "/missing.json"
+	data, err := loadForeverBlog(context.Background(), cfg, cache)
+	if err != nil || len(data.Items) != 0 {
+		t.Errorf("文件不存在时 = %+v, %v, want 空数据", data, err)
+	}
+}
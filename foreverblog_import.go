@@ -0,0 +1,128 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: foreverblog_import.go
+// Description: 从十年之约（foreverblog.cn）导入成员RSS列表，按白名单筛选后与自有列表合并去重
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// foreverblogMember 十年之约成员信息
+type foreverblogMember struct {
+	Name string // 博客名称
+	Link string // 博客主页
+	Feed string // RSS 地址
+}
+
+// UnmarshalJSON 兼容成员接口中不同的字段命名
+//
+// Description:
+//
+//	不同接口版本对字段的命名不一致（name/title、link/url/blog、feed/rss/feed_url），
+//	这里逐一尝试，取第一个非空值
+func (m *foreverblogMember) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	pick := func(keys ...string) string {
+		for _, k := range keys {
+			if v, ok := raw[k].(string); ok && strings.TrimSpace(v) != "" {
+				return strings.TrimSpace(v)
+			}
+		}
+		return ""
+	}
+	m.Name = pick("name", "title", "blog_name")
+	m.Link = pick("link", "url", "blog", "homepage")
+	m.Feed = pick("feed", "rss", "feed_url", "feedurl")
+	return nil
+}
+
+// fetchForeverblogMembers 从十年之约的公开接口获取成员列表
+//
+// Description:
+//
+//	接口返回值可以是成员数组，也可以是 {"data": [...]} 或 {"data": {"data": [...]}} 形式的分页结构
+func fetchForeverblogMembers(ctx context.Context, apiURL string, cache *fetchCache) ([]foreverblogMember, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	body, _, err := cache.conditionalGet(ctx, client, apiURL)
+	if err != nil {
+		return nil, wrapErrorf(err, "获取十年之约成员列表失败: %s", apiURL)
+	}
+
+	var members []foreverblogMember
+	if err := json.Unmarshal(body, &members); err == nil {
+		return members, nil
+	}
+	var wrapped struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &wrapped); err != nil {
+		return nil, wrapErrorf(err, "解析十年之约成员列表失败")
+	}
+	if err := json.Unmarshal(wrapped.Data, &members); err == nil {
+		return members, nil
+	}
+	var paged struct {
+		Data []foreverblogMember `json:"data"`
+	}
+	if err := json.Unmarshal(wrapped.Data, &paged); err != nil {
+		return nil, wrapErrorf(err, "解析十年之约成员列表失败")
+	}
+	return paged.Data, nil
+}
+
+// mergeForeverblogMembers 将白名单内的十年之约成员RSS合并到自有列表
+//
+// Description:
+//
+//	采用 opt-in 方式：只有博客主页或RSS域名出现在 allowlist 中的成员才会被合并，
+//	allowlist 为空时不合并任何成员；与自有列表重复的RSS会被忽略
+//
+// Returns:
+//   - []feedEntry: 合并后的订阅列表
+//   - int        : 实际新增的成员数量
+func mergeForeverblogMembers(own []feedEntry, members []foreverblogMember, allowlist []string) ([]feedEntry, int) {
+	if len(allowlist) == 0 {
+		return own, 0
+	}
+	allowed := make(map[string]bool, len(allowlist))
+	for _, d := range allowlist {
+		allowed[urlnorm.SiteHost(d)] = true
+	}
+	hostAllowed := func(link string) bool {
+		host := urlnorm.SiteHost(link)
+		return host != "" && allowed[host]
+	}
+
+	seen := make(map[string]bool, len(own))
+	for _, e := range own {
+		seen[urlnorm.Key(e.URL)] = true
+	}
+
+	merged := own
+	added := 0
+	for _, m := range members {
+		if m.Feed == "" || !(hostAllowed(m.Feed) || hostAllowed(m.Link)) {
+			continue
+		}
+		key := urlnorm.Key(m.Feed)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, feedEntry{URL: m.Feed})
+		added++
+		fmt.Printf("[INFO] 合并十年之约成员: %s (%s)\n", m.Name, m.Feed)
+	}
+	return merged, added
+}
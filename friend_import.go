@@ -0,0 +1,366 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: friend_import.go
+// Description: import 子命令，从 hexo-circle-of-friends 的结果 JSON 或 FreshRSS/Miniflux 导出的 OPML 生成 RSS 列表与头像映射，便于迁移
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// importedFriend 从其他工具导入的一位博主
+type importedFriend struct {
+	Name     string // 博客名称
+	Homepage string // 博客主页
+	Avatar   string // 头像地址
+	Feed     string // 订阅地址，hexo-circle-of-friends 不提供，需通过主页发现
+	Group    string // 分组（OPML 文件夹）
+}
+
+// runImportCommand 导入子命令
+//
+// Description:
+//
+//	import -format fcircle|opml -in <文件或URL> [-rss data/rss.txt] [-avatars data/avatar.json] [-discover=true]
+//	订阅以 "地址 name=... group=..." 的形式追加到 -rss 指定的列表（已存在的订阅跳过），
+//	头像按主页合并进 -avatars 指定的 avatar.json（已有映射不覆盖）；未指定输出文件时打印到标准输出。
+//	hexo-circle-of-friends 的数据中没有订阅地址，默认请求各主页从 <link rel="alternate"> 中发现，
+//	未发现的博主以注释行输出，需手动补全
+func runImportCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "输入格式: fcircle (hexo-circle-of-friends 结果 JSON) 或 opml (FreshRSS/Miniflux 导出)")
+	in := fs.String("in", "", "输入文件路径或 HTTP(S) 地址 (必填)")
+	rssOut := fs.String("rss", "", "追加订阅的 RSS 列表文件，为空时打印到标准输出")
+	avatarOut := fs.String("avatars", "", "合并头像映射的 avatar.json 文件，为空时打印到标准输出")
+	discover := fs.Bool("discover", true, "没有订阅地址时请求主页发现订阅")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "用法: import -format fcircle|opml -in <文件或URL> [-rss 文件] [-avatars 文件]")
+		return 2
+	}
+
+	data, err := readImportSource(ctx, *in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 1
+	}
+	if *format == "" {
+		*format = "fcircle"
+		if isOPML(data) {
+			*format = "opml"
+		}
+	}
+
+	var friends []importedFriend
+	switch *format {
+	case "fcircle":
+		friends, err = parseFcircleFriends(data)
+	case "opml":
+		friends, err = parseOPMLFriends(data)
+	default:
+		fmt.Fprintf(os.Stderr, "未知格式: %s (只能是 fcircle 或 opml)\n", *format)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 1
+	}
+	if *discover {
+		discoverFriendFeeds(ctx, friends)
+	}
+
+	added, err := writeImportedFeeds(*rssOut, friends)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] 写入RSS列表失败: %v\n", err)
+		return 1
+	}
+	avatars, err := writeImportedAvatars(*avatarOut, friends)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] 写入头像映射失败: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "共导入 %d 位博主: 新增订阅 %d 条, 新增头像映射 %d 条\n", len(friends), added, avatars)
+	return 0
+}
+
+// readImportSource 读取本地文件或 HTTP(S) 地址的内容
+func readImportSource(ctx context.Context, src string) ([]byte, error) {
+	if !isRemoteURL(src) {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return nil, wrapErrorf(err, "读取导入文件失败: %s", src)
+		}
+		return data, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", src, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, wrapErrorf(err, "读取导入文件失败: %s", src)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("读取导入文件失败: %s (HTTP %d)", src, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// parseFcircleFriends 解析 hexo-circle-of-friends 的数据
+//
+// Description:
+//
+//	支持友链列表（/friend 接口，[{name, link, avatar}]）和全量结果（/all 接口，
+//	{article_data: [{author, avatar, link}]}），后者按作者名去重，主页取文章链接的站点根地址
+func parseFcircleFriends(data []byte) ([]importedFriend, error) {
+	type fcircleFriend struct {
+		Name   string `json:"name"`
+		Link   string `json:"link"`
+		Avatar string `json:"avatar"`
+	}
+	var list []fcircleFriend
+	if err := json.Unmarshal(data, &list); err == nil {
+		friends := make([]importedFriend, 0, len(list))
+		for _, f := range list {
+			if f.Link == "" {
+				continue
+			}
+			friends = append(friends, importedFriend{Name: f.Name, Homepage: f.Link, Avatar: f.Avatar})
+		}
+		return friends, nil
+	}
+
+	var result struct {
+		FriendList  []fcircleFriend `json:"friend_list"`
+		ArticleData []struct {
+			Author string `json:"author"`
+			Avatar string `json:"avatar"`
+			Link   string `json:"link"`
+		} `json:"article_data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, wrapErrorf(err, "解析hexo-circle-of-friends数据失败")
+	}
+	var friends []importedFriend
+	seen := make(map[string]bool)
+	for _, f := range result.FriendList {
+		if f.Link == "" || seen[f.Name] {
+			continue
+		}
+		seen[f.Name] = true
+		friends = append(friends, importedFriend{Name: f.Name, Homepage: f.Link, Avatar: f.Avatar})
+	}
+	for _, a := range result.ArticleData {
+		if a.Author == "" || seen[a.Author] {
+			continue
+		}
+		if urlnorm.Host(a.Link) == "" {
+			continue
+		}
+		homepage := siteRoot(a.Link)
+		seen[a.Author] = true
+		friends = append(friends, importedFriend{Name: a.Author, Homepage: homepage, Avatar: a.Avatar})
+	}
+	return friends, nil
+}
+
+// parseOPMLFriends 解析 FreshRSS/Miniflux 导出的 OPML
+//
+// Description:
+//
+//	与 parseOPMLEntries 相同地以最近一层文件夹作为分组，并保留订阅的名称（title 优先于 text）和 htmlUrl
+func parseOPMLFriends(data []byte) ([]importedFriend, error) {
+	var doc opmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, wrapErrorf(err, "解析OPML失败")
+	}
+	var friends []importedFriend
+	var walk func(outlines []opmlOutline, group string)
+	walk = func(outlines []opmlOutline, group string) {
+		for _, o := range outlines {
+			name := strings.TrimSpace(o.Title)
+			if name == "" {
+				name = strings.TrimSpace(o.Text)
+			}
+			if u := strings.TrimSpace(o.XMLURL); u != "" {
+				friends = append(friends, importedFriend{Name: name, Homepage: strings.TrimSpace(o.HTMLURL), Feed: u, Group: group})
+				continue
+			}
+			walk(o.Outlines, name)
+		}
+	}
+	walk(doc.Body.Outlines, "")
+	return friends, nil
+}
+
+// discoverFriendFeeds 并发请求没有订阅地址的博主主页，从 <link rel="alternate"> 中发现订阅
+func discoverFriendFeeds(ctx context.Context, friends []importedFriend) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	sem := make(chan struct{}, 10)
+	var wg sync.WaitGroup
+	for i := range friends {
+		if friends[i].Feed != "" || friends[i].Homepage == "" {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(f *importedFriend) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			req, err := http.NewRequestWithContext(ctx, "GET", f.Homepage, nil)
+			if err != nil {
+				return
+			}
+			req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+			resp, err := client.Do(req)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[WARN] 访问主页失败: %s (%v)\n", f.Homepage, err)
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+			f.Feed = discoverFeedLink(resp.Request.URL.String(), body)
+		}(&friends[i])
+	}
+	wg.Wait()
+}
+
+// formatImportedFeed 将导入的博主格式化为 RSS 列表中的一行
+//
+// Description:
+//
+//	名称中的空格写作 %20（与 name= 的解析规则一致），分组中的空格替换为 "-"，未找到订阅地址时输出以 # 开头的注释行
+func formatImportedFeed(f importedFriend) string {
+	if f.Feed == "" {
+		return fmt.Sprintf("# 未找到订阅地址: %s %s", f.Name, f.Homepage)
+	}
+	escape := strings.NewReplacer("%", "%25", " ", "%20").Replace
+	line := f.Feed
+	if f.Name != "" {
+		line += " name=" + escape(f.Name)
+	}
+	if f.Group != "" {
+		line += " group=" + strings.Join(strings.Fields(f.Group), "-")
+	}
+	return line
+}
+
+// writeImportedFeeds 将导入的订阅追加到 RSS 列表，返回新增的订阅数
+//
+// Description:
+//
+//	path 为空时打印到标准输出；列表中已存在（按 urlnorm.Key 比较）的订阅会被跳过
+func writeImportedFeeds(path string, friends []importedFriend) (int, error) {
+	var existing []byte
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+		existing = data
+	}
+	seen := make(map[string]bool)
+	for _, e := range parseFeedEntries(existing) {
+		seen[urlnorm.Key(e.URL)] = true
+	}
+
+	var buf bytes.Buffer
+	added := 0
+	for _, f := range friends {
+		if f.Feed != "" {
+			key := urlnorm.Key(f.Feed)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			added++
+		}
+		buf.WriteString(formatImportedFeed(f))
+		buf.WriteByte('\n')
+	}
+
+	if path == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return added, err
+	}
+	if buf.Len() == 0 {
+		return 0, nil
+	}
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		existing = append(existing, '\n')
+	}
+	return added, os.WriteFile(path, append(existing, buf.Bytes()...), 0o644)
+}
+
+// writeImportedAvatars 将导入的头像合并到 avatar.json，返回新增的映射数
+//
+// Description:
+//
+//	path 为空时打印到标准输出；按主页的站点域名判断是否已有映射，已有映射不覆盖
+func writeImportedAvatars(path string, friends []importedFriend) (int, error) {
+	var data AvatarMapData
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &data); err != nil {
+				return 0, wrapErrorf(err, "解析头像映射文件失败: %s", path)
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	for _, m := range data.Items {
+		seen[urlnorm.SiteHost(m.Link)] = true
+	}
+
+	added := 0
+	for _, f := range friends {
+		if f.Avatar == "" || f.Homepage == "" {
+			continue
+		}
+		host := urlnorm.SiteHost(f.Homepage)
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		data.Items = append(data.Items, AvatarMapping{Link: f.Homepage, Avatar: f.Avatar, Name: f.Name})
+		added++
+	}
+
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	out = append(out, '\n')
+	if path == "" {
+		if added == 0 {
+			return 0, nil
+		}
+		_, err := os.Stdout.Write(out)
+		return added, err
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	return added, os.WriteFile(path, out, 0o644)
+}
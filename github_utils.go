@@ -13,9 +13,114 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"strings"
 )
 
+// repoPath 将若干路径片段拼接为 GitHub 仓库内的相对路径
+//
+// Description:
+//
+//	GitHub API 的仓库路径始终使用正斜杠分隔，不能使用 filepath.Join（Windows 下会生成反斜杠），
+//	此函数统一把反斜杠转换为正斜杠，使用 path.Join 拼接，并去掉开头的斜杠，
+//	所有 GitHub 文件操作都应通过它（或 contentsAPIURL）生成路径
+func repoPath(elem ...string) string {
+	parts := make([]string, 0, len(elem))
+	for _, e := range elem {
+		parts = append(parts, strings.ReplaceAll(e, "\\", "/"))
+	}
+	return strings.TrimPrefix(path.Join(parts...), "/")
+}
+
+// contentsAPIURL 构造 GitHub contents API 的完整地址
+func contentsAPIURL(owner, repo, filePath string) string {
+	return fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", owner, repo, repoPath(filePath))
+}
+
+// GitHub 对单个文件的大小限制
+const (
+	githubMaxFileBytes  = 100 << 20 // 超过 100MB 的文件无法提交，contents API 也无法读取
+	githubWarnFileBytes = 50 << 20  // 超过 50MB 时 GitHub 会警告，仓库克隆与 Pages 构建都会明显变慢
+)
+
+// checkGitHubFileSize 在提交前检查文件大小，超过 GitHub 的限制时直接拒绝，而不是等 API 返回难以理解的错误
+func checkGitHubFileSize(path string, size int) error {
+	if size > githubMaxFileBytes {
+		return fmt.Errorf("file %s is %s, exceeds GitHub's %s per-file limit; save it with a .gz suffix to compress it, or use SAVE_TARGET=COS",
+			path, formatBytes(size), formatBytes(githubMaxFileBytes))
+	}
+	if size > githubWarnFileBytes {
+		fmt.Printf("[WARN] %s 为 %s, 超过 GitHub 建议的 %s, 可改用 .gz 后缀压缩保存\n", path, formatBytes(size), formatBytes(githubWarnFileBytes))
+	}
+	return nil
+}
+
+// getGitHubRawFile 以 raw 格式读取仓库内文件
+//
+// Description:
+//
+//	contents API 的 JSON 响应只内联 1MB 以内文件的内容，1MB~100MB 的文件需使用 raw 媒体类型读取
+func getGitHubRawFile(ctx context.Context, token, owner, repo, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", contentsAPIURL(owner, repo, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github.raw+json")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get raw file %s, status: %d, body: %s",
+			path, resp.StatusCode, string(bodyBytes))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// gitUser 表示一次提交中的作者或提交者身份
+type gitUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// commitSignature 描述一次 GitHub 提交的身份信息
+//
+// Description:
+//
+//	Author 与 Committer 可以分别设置，CoAuthors 会以 "Co-authored-by" 尾注的形式追加到提交信息中
+type commitSignature struct {
+	Author    gitUser
+	Committer gitUser
+	CoAuthors []string // 形如 "Name <email>"
+}
+
+// message 在提交信息末尾追加 Run-Id 及 Co-authored-by 尾注
+func (sig commitSignature) message(msg string) string {
+	var sb strings.Builder
+	sb.WriteString(msg)
+	sb.WriteString("\n\nRun-Id: " + runID)
+	for _, co := range sig.CoAuthors {
+		sb.WriteString("\nCo-authored-by: " + co)
+	}
+	return sb.String()
+}
+
+// apply 将作者与提交者写入 GitHub API 的请求体，未设置的身份将交由 GitHub 使用默认值
+func (sig commitSignature) apply(payload map[string]interface{}) {
+	if sig.Committer.Name != "" && sig.Committer.Email != "" {
+		payload["committer"] = sig.Committer
+	}
+	if sig.Author.Name != "" && sig.Author.Email != "" {
+		payload["author"] = sig.Author
+	}
+}
+
 // getGitHubFileSHA 获取指定仓库内某个路径文件的SHA，若文件不存在则返回空
 //
 // Description:
@@ -23,7 +128,7 @@ import (
 //	通过 GitHub API 获取指定仓库中文件的 sha 值，用于后续更新或删除操作
 //	如果文件不存在，返回空字符串
 func getGitHubFileSHA(ctx context.Context, token, owner, repo, path string) (string, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", owner, repo, path)
+	apiURL := contentsAPIURL(owner, repo, path)
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return "", err
@@ -63,19 +168,20 @@ func getGitHubFileSHA(ctx context.Context, token, owner, repo, path string) (str
 //
 //	该函数通过 GitHub API 调用来在指定仓库和分支里创建或更新文件
 //	当 sha 不为空时会执行更新逻辑，sha 为空时会执行创建逻辑
-func putGitHubFile(ctx context.Context, token, owner, repo, path, sha, content, commitMsg, committerName, committerEmail string) error {
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", owner, repo, path)
+//	超过 githubMaxFileBytes 的文件在发送请求前直接拒绝
+func putGitHubFile(ctx context.Context, token, owner, repo, path, sha, content, commitMsg string, sig commitSignature) error {
+	if err := checkGitHubFileSize(path, len(content)); err != nil {
+		return err
+	}
+	apiURL := contentsAPIURL(owner, repo, path)
 	encoded := base64.StdEncoding.EncodeToString([]byte(content))
 
 	payload := map[string]interface{}{
-		"message": commitMsg,
+		"message": sig.message(commitMsg),
 		"content": encoded,
 		"branch":  "main",
-		"committer": map[string]string{
-			"name":  committerName,
-			"email": committerEmail,
-		},
 	}
+	sig.apply(payload)
 	// 如果已有文件, 则必须包含旧的SHA
 	if sha != "" {
 		payload["sha"] = sha
@@ -115,15 +221,15 @@ func putGitHubFile(ctx context.Context, token, owner, repo, path, sha, content,
 //
 //	调用 GitHub API 删除指定的文件，需要提供文件SHA
 //	该操作会在 main 分支上进行提交（删除操作算一次提交）
-func deleteGitHubFile(ctx context.Context, token, owner, repo, path, sha, committerName, committerEmail string) error {
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", owner, repo, path)
+func deleteGitHubFile(ctx context.Context, token, owner, repo, path, sha string, sig commitSignature) error {
+	apiURL := contentsAPIURL(owner, repo, path)
 
 	payload := map[string]interface{}{
-		"message":   "Delete old log file",
-		"sha":       sha,
-		"branch":    "main",
-		"committer": map[string]string{"name": committerName, "email": committerEmail},
+		"message": sig.message("Delete old log file"),
+		"sha":     sha,
+		"branch":  "main",
 	}
+	sig.apply(payload)
 	jsonBytes, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -151,18 +257,22 @@ func deleteGitHubFile(ctx context.Context, token, owner, repo, path, sha, commit
 	return nil
 }
 
+// githubDirEntry GitHub 仓库目录中的一项
+type githubDirEntry struct {
+	Name string `json:"name"`
+	SHA  string `json:"sha"`
+	Type string `json:"type"` // file 或 dir
+	Size int    `json:"size"` // 文件大小（字节）
+}
+
 // listGitHubDir 列出GitHub仓库某目录下的文件与信息
 //
 // Description:
 //
-//	调用 GitHub API 获取指定目录下的所有文件/子目录，返回它们的名字、SHA、类型等信息
+//	调用 GitHub API 获取指定目录下的所有文件/子目录，返回它们的名字、SHA、类型与大小等信息
 //	如果该目录不存在或为空，返回nil
-func listGitHubDir(ctx context.Context, token, owner, repo, dir string) ([]struct {
-	Name string `json:"name"`
-	SHA  string `json:"sha"`
-	Type string `json:"type"`
-}, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", owner, repo, dir)
+func listGitHubDir(ctx context.Context, token, owner, repo, dir string) ([]githubDirEntry, error) {
+	apiURL := contentsAPIURL(owner, repo, dir)
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, err
@@ -187,17 +297,89 @@ func listGitHubDir(ctx context.Context, token, owner, repo, dir string) ([]struc
 			dir, resp.StatusCode, string(bodyBytes))
 	}
 
-	var files []struct {
-		Name string `json:"name"`
-		SHA  string `json:"sha"`
-		Type string `json:"type"`
-	}
+	var files []githubDirEntry
 	if err := json.NewDecoder(resp.Body).Decode(&files); err != nil {
 		return nil, err
 	}
 	return files, nil
 }
 
+// getGistFile 获取 Gist 中指定文件的内容
+//
+// Description:
+//
+//	通过 GitHub Gists API 读取 Gist，token 为空时匿名访问（仅限公开 Gist）
+//	filename 为空且 Gist 只有一个文件时返回该文件；内容过大被 API 截断时改为下载 raw_url
+func getGistFile(ctx context.Context, token, gistID, filename string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.github.com/gists/"+gistID, nil)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to get gist %s, status: %d, body: %s",
+			gistID, resp.StatusCode, string(bodyBytes))
+	}
+
+	var gist struct {
+		Files map[string]struct {
+			Content   string `json:"content"`
+			Truncated bool   `json:"truncated"`
+			RawURL    string `json:"raw_url"`
+		} `json:"files"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&gist); err != nil {
+		return "", err
+	}
+
+	if filename == "" {
+		if len(gist.Files) != 1 {
+			return "", fmt.Errorf("gist %s has %d files, filename required", gistID, len(gist.Files))
+		}
+		for name := range gist.Files {
+			filename = name
+		}
+	}
+	file, ok := gist.Files[filename]
+	if !ok {
+		return "", fmt.Errorf("file %s not found in gist %s", filename, gistID)
+	}
+	if !file.Truncated {
+		return file.Content, nil
+	}
+
+	rawReq, err := http.NewRequestWithContext(ctx, "GET", file.RawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		rawReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	rawResp, err := http.DefaultClient.Do(rawReq)
+	if err != nil {
+		return "", err
+	}
+	defer rawResp.Body.Close()
+	if rawResp.StatusCode != 200 {
+		return "", fmt.Errorf("failed to get gist file %s, status: %d", file.RawURL, rawResp.StatusCode)
+	}
+	raw, err := io.ReadAll(rawResp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
 // decodeBase64 对Base64字符串进行解码,并返回解码后的文本
 func decodeBase64(b64str string) (string, error) {
 	decoded, err := base64.StdEncoding.DecodeString(b64str)
@@ -206,41 +388,3 @@ func decodeBase64(b64str string) (string, error) {
 	}
 	return string(decoded), nil
 }
-
-// uploadToGitHub 使用 GitHub API 将 data.json 覆盖上传到指定仓库路径
-func uploadToGitHub(
-	ctx context.Context,
-	token string,
-	owner string,
-	repo string,
-	dataFilePath string,
-	data []byte,
-) error {
-
-	committerName := owner
-	committerEmail := owner + "@users.noreply.github.com"
-
-	// 先查文件是否存在
-	sha, err := getGitHubFileSHA(ctx, token, owner, repo, dataFilePath)
-	if err != nil {
-		return wrapErrorf(err, "获取 %s 文件SHA失败", dataFilePath)
-	}
-
-	// 通过 putGitHubFile 创建或更新
-	err = putGitHubFile(
-		ctx,
-		token,
-		owner,
-		repo,
-		dataFilePath,
-		sha,
-		string(data),
-		"Update data.json",
-		committerName,
-		committerEmail,
-	)
-	if err != nil {
-		return wrapErrorf(err, "上传 data.json 失败")
-	}
-	return nil
-}
@@ -3,8 +3,10 @@ module github.com/achuanya/lhasaRSS
 go 1.24.0
 
 require (
+	github.com/andybalholm/brotli v1.2.0
 	github.com/mmcdole/gofeed v1.3.0
 	github.com/tencentyun/cos-go-sdk-v5 v0.7.62
+	github.com/tetratelabs/wazero v1.9.0
 	golang.org/x/net v0.37.0
 )
 
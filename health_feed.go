@@ -0,0 +1,214 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: health_feed.go
+// Description: 友链健康状况的 RSS 订阅，问题出现（订阅失效、头像不可用、证书即将到期等）或恢复时各生成一条事件
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"sort"
+	"strings"
+	"time"
+)
+
+// healthFeedMaxItems 健康订阅中保留的最大事件数
+const healthFeedMaxItems = 100
+
+// healthEventLabels 纳入健康订阅的问题类型及其事件标题
+var healthEventLabels = map[string]string{
+	"parseFails":   "订阅解析失败",
+	"authWalled":   "订阅需要登录或付费",
+	"htmlPages":    "RSS地址返回网页",
+	"feedEmpties":  "订阅为空",
+	"feedShort":    "订阅文章数过少",
+	"throttled":    "订阅被限流",
+	"timeouts":     "订阅请求超时",
+	"noAvatar":     "头像为空",
+	"brokenAvatar": "头像无法访问",
+	"certExpiring": "HTTPS 证书即将到期",
+	"deadLinks":    "文章链接失效",
+	"lostBlogs":    "博客失联",
+	"zombieFeeds":  "疑似域名停放或被替换",
+	"badFields":    "文章字段异常",
+	"dataShrink":   "文章数骤减",
+	"insecureTLS":  "跳过证书校验",
+	"quarantined":  "订阅已隔离",
+}
+
+// healthEvent 健康订阅中的一条事件
+type healthEvent struct {
+	Kind      string    `json:"kind"`             // 问题类型，同 problems 的键
+	Target    string    `json:"target"`           // 出问题的订阅地址、博客主页或文章链接
+	Detail    string    `json:"detail,omitempty"` // 问题详情
+	Recovered bool      `json:"recovered,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// healthState 健康订阅的跨运行状态，保存在抓取缓存中
+type healthState struct {
+	Active map[string]time.Time `json:"active"`           // 当前存在的问题（类型 + 目标）及首次出现时间
+	Events []healthEvent        `json:"events,omitempty"` // 最近的事件，新事件在前
+}
+
+// recordHealthEvents 对比本次与上次的问题，记录新出现和已恢复的问题
+//
+// Description:
+//
+//	缓存中还没有状态时（首次运行或缓存丢失）只记录当前问题，不生成事件，避免一次性推送所有既有问题
+func (c *fetchCache) recordHealthEvents(problems map[string][]string, now time.Time) []healthEvent {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	firstRun := c.Health == nil
+	if firstRun {
+		c.Health = &healthState{}
+	}
+	state := c.Health
+	current := make(map[string]ProblemItem)
+	for kind, lines := range problems {
+		if _, ok := healthEventLabels[kind]; !ok {
+			continue
+		}
+		for _, l := range lines {
+			it := splitProblem(l)
+			current[kind+"\x00"+it.Target] = it
+		}
+	}
+
+	var events []healthEvent
+	keys := make([]string, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := state.Active[key]; ok {
+			continue
+		}
+		kind, _, _ := strings.Cut(key, "\x00")
+		it := current[key]
+		events = append(events, healthEvent{Kind: kind, Target: it.Target, Detail: it.Detail, Time: now})
+	}
+	keys = keys[:0]
+	for key := range state.Active {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		kind, target, _ := strings.Cut(key, "\x00")
+		events = append(events, healthEvent{Kind: kind, Target: target, Recovered: true, Time: now})
+	}
+
+	active := make(map[string]time.Time, len(current))
+	for key := range current {
+		if t, ok := state.Active[key]; ok {
+			active[key] = t
+		} else {
+			active[key] = now
+		}
+	}
+	state.Active = active
+	if firstRun {
+		return nil
+	}
+	state.Events = append(events, state.Events...)
+	if len(state.Events) > healthFeedMaxItems {
+		state.Events = state.Events[:healthFeedMaxItems]
+	}
+	return events
+}
+
+// healthRSS RSS 2.0 文档结构
+type healthRSS struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Channel struct {
+		Title       string          `xml:"title"`
+		Link        string          `xml:"link,omitempty"`
+		Description string          `xml:"description"`
+		Items       []healthRSSItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+// healthRSSItem RSS 中的一条事件
+type healthRSSItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link,omitempty"`
+	Description string `xml:"description"`
+	GUID        struct {
+		Value       string `xml:",chardata"`
+		IsPermaLink bool   `xml:"isPermaLink,attr"`
+	} `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+}
+
+// healthFeedOutput 将缓存中的事件渲染为健康订阅，保存到 HEALTH_FEED 指定的位置
+//
+// Description:
+//
+//	HEALTH_FEED 只是文件名（不含 /）时保存在 data.json 同目录；
+//	内容只由事件决定，没有新事件时内容不变、跳过上传；事件的链接为出问题的地址（http/https 时）
+func healthFeedOutput(cfg *Config, cache *fetchCache) (outputFile, error) {
+	target := cfg.HealthFeed
+	if !strings.Contains(target, "/") {
+		target = siblingPath(cfg.DataURL, target)
+	}
+
+	var doc healthRSS
+	doc.Version = "2.0"
+	doc.Channel.Title = tr("lhasaRSS 友链健康状况")
+	if isRemoteURL(target) {
+		doc.Channel.Link = target
+	}
+	doc.Channel.Description = tr("订阅失效、头像不可用、证书即将到期等问题的出现与恢复")
+
+	var events []healthEvent
+	if cache != nil {
+		cache.mu.Lock()
+		if cache.Health != nil {
+			events = append(events, cache.Health.Events...)
+		}
+		cache.mu.Unlock()
+	}
+	for _, e := range events {
+		var item healthRSSItem
+		label := tr(healthEventLabels[e.Kind])
+		if e.Recovered {
+			item.Title = trf("[已恢复] %s: %s", label, e.Target)
+			item.Description = trf("%s 的问题已不再出现", e.Target)
+		} else {
+			item.Title = "[" + label + "] " + e.Target
+			item.Description = e.Target
+			if e.Detail != "" {
+				item.Description += " (" + e.Detail + ")"
+			}
+		}
+		if isRemoteURL(e.Target) {
+			item.Link = e.Target
+		}
+		item.GUID.Value = e.Kind + "|" + e.Target + "|" + e.Time.UTC().Format(time.RFC3339)
+		item.PubDate = e.Time.Format(time.RFC1123Z)
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return outputFile{}, wrapErrorf(err, "健康订阅序列化失败")
+	}
+	buf.WriteByte('\n')
+	return outputFile{
+		Target:        target,
+		Data:          buf.Bytes(),
+		CommitMsg:     "Update health feed",
+		SkipUnchanged: true,
+	}, nil
+}
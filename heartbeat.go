@@ -0,0 +1,102 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: heartbeat.go
+// Description: 运行心跳，每次运行结束后向 healthchecks.io 或 Uptime Kuma 的 Push 地址上报成功/失败与耗时，
+// 定时任务停止运行（而不只是运行失败）时也能由监控服务发出告警
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// isUptimeKumaPush 判断心跳地址是否为 Uptime Kuma 的 Push 地址（/api/push/<token>）
+func isUptimeKumaPush(pingURL string) bool {
+	return strings.Contains(pingURL, "/api/push/")
+}
+
+// heartbeatStart 通知监控服务本次运行开始
+//
+// Description:
+//
+//	healthchecks.io 收到 /start 后以下一次成功/失败上报的时间计算运行耗时；
+//	Uptime Kuma 没有开始事件，不发送
+func heartbeatStart(ctx context.Context, cfg *Config) error {
+	if cfg.HeartbeatURL == "" || isUptimeKumaPush(cfg.HeartbeatURL) {
+		return nil
+	}
+	return pingHeartbeat(ctx, "POST", strings.TrimSuffix(cfg.HeartbeatURL, "/")+"/start", "")
+}
+
+// heartbeatFinish 上报本次运行的结果与耗时
+//
+// Description:
+//
+//	healthchecks.io：成功时请求心跳地址，失败时请求 <地址>/fail，请求体为运行信息；
+//	Uptime Kuma：请求 Push 地址并附带 status=up/down、msg 与 ping（耗时，毫秒）参数
+//
+// Parameters:
+//   - exitCode : 本次运行的退出码，非 0 视为失败
+//   - elapsed  : 本次运行的耗时
+func heartbeatFinish(ctx context.Context, cfg *Config, exitCode int, elapsed time.Duration) error {
+	if cfg.HeartbeatURL == "" {
+		return nil
+	}
+	msg := fmt.Sprintf("run_id=%s exit=%d elapsed=%s", runID, exitCode, elapsed.Round(time.Millisecond))
+	if activeProfile != "" {
+		msg = "profile=" + activeProfile + " " + msg
+	}
+
+	if isUptimeKumaPush(cfg.HeartbeatURL) {
+		u, err := url.Parse(cfg.HeartbeatURL)
+		if err != nil {
+			return wrapErrorf(err, "心跳地址无效")
+		}
+		status := "up"
+		if exitCode != 0 {
+			status = "down"
+		}
+		q := u.Query()
+		q.Set("status", status)
+		q.Set("msg", msg)
+		q.Set("ping", strconv.FormatInt(elapsed.Milliseconds(), 10))
+		u.RawQuery = q.Encode()
+		return pingHeartbeat(ctx, "GET", u.String(), "")
+	}
+
+	pingURL := cfg.HeartbeatURL
+	if exitCode != 0 {
+		pingURL = strings.TrimSuffix(pingURL, "/") + "/fail"
+	}
+	return pingHeartbeat(ctx, "POST", pingURL, msg)
+}
+
+// pingHeartbeat 发送一次心跳请求，非 2xx 响应视为失败
+func pingHeartbeat(ctx context.Context, method, pingURL, body string) error {
+	req, err := http.NewRequestWithContext(ctx, method, pingURL, strings.NewReader(body))
+	if err != nil {
+		return wrapErrorf(err, "发送心跳失败")
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return wrapErrorf(err, "发送心跳失败")
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return wrapErrorf(fmt.Errorf("HTTP状态码: %d", resp.StatusCode), "发送心跳失败")
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: hooks.go
+// Description: 流水线钩子，在抓取前、解析后、上传前执行外部命令或 WASM 模块，通过标准输入输出交换 JSON，实现自定义过滤或增强
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// hookTimeout 单个钩子命令的最长执行时间
+const hookTimeout = 60 * time.Second
+
+// prefetchPayload pre-fetch 钩子的输入输出格式
+type prefetchPayload struct {
+	Feeds []feedEntry `json:"feeds"` // 待抓取的订阅列表（含单独的抓取配置）
+}
+
+// postParsePayload post-parse 钩子的输入输出格式
+type postParsePayload struct {
+	Items []Article `json:"items"` // 解析并排序后的文章
+}
+
+// runHook 执行钩子命令，将 input 写入其标准输入，返回标准输出
+//
+// Description:
+//
+//	命令通过 sh -c 执行，可以是任意脚本或可执行文件；环境变量 LHASA_HOOK 为钩子名称，
+//	命令的标准错误直接输出到控制台，退出码非 0 视为失败
+//	以 wasm: 开头时改为在进程内运行 WASM 模块（runWASMHook）；Go 脚本无需内置解释器，
+//	可直接配置为 go run hook.go 作为命令钩子执行
+func runHook(ctx context.Context, name, command string, input []byte) ([]byte, error) {
+	if modulePath, ok := wasmHookPath(command); ok {
+		return runWASMHook(ctx, name, modulePath, input)
+	}
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), "LHASA_HOOK="+name)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	start := time.Now()
+	if err := cmd.Run(); err != nil {
+		return nil, wrapErrorf(err, "执行 %s 钩子失败: %s", name, command)
+	}
+	fmt.Printf("[INFO] %s 钩子执行完成, 耗时 %v\n", name, time.Since(start).Round(time.Millisecond))
+	return stdout.Bytes(), nil
+}
+
+// runJSONHook 将 v 序列化后交给钩子命令，若命令输出非空，则用输出的 JSON 覆盖 v
+//
+// Description:
+//
+//	command 为空时直接返回；钩子可只读取数据而不输出任何内容，此时数据保持不变
+func runJSONHook(ctx context.Context, name, command string, v interface{}) error {
+	if command == "" {
+		return nil
+	}
+	input, err := json.Marshal(v)
+	if err != nil {
+		return wrapErrorf(err, "序列化 %s 钩子输入失败", name)
+	}
+	output, err := runHook(ctx, name, command, input)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(output, v); err != nil {
+		return wrapErrorf(err, "解析 %s 钩子输出失败", name)
+	}
+	return nil
+}
+
+// runPostParseHook 执行 post-parse 钩子，并为钩子返回的文章恢复不参与序列化的正文摘录
+func runPostParseHook(ctx context.Context, command string, articles []Article) ([]Article, error) {
+	if command == "" {
+		return articles, nil
+	}
+	contents := make(map[string]string, len(articles))
+	for _, a := range articles {
+		contents[a.Link] = a.content
+	}
+
+	payload := postParsePayload{Items: articles}
+	if err := runJSONHook(ctx, "post-parse", command, &payload); err != nil {
+		return articles, err
+	}
+	for i := range payload.Items {
+		payload.Items[i].content = contents[payload.Items[i].Link]
+	}
+	return payload.Items, nil
+}
+
+// runPreUploadHook 执行 pre-upload 钩子，钩子可直接改写最终上传的 data.json 内容
+func runPreUploadHook(ctx context.Context, command string, data []byte) ([]byte, error) {
+	if command == "" {
+		return data, nil
+	}
+	output, err := runHook(ctx, "pre-upload", command, data)
+	if err != nil {
+		return data, err
+	}
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return data, nil
+	}
+	if !json.Valid(output) {
+		return data, fmt.Errorf("pre-upload 钩子输出不是合法的 JSON")
+	}
+	return output, nil
+}
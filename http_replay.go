@@ -0,0 +1,161 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: http_replay.go
+// Description: HTTP 录制与回放，录制一次完整运行的所有响应，之后可离线、确定性地回放，便于本地调试配置与输出
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// replayTransport 录制或回放 HTTP 响应的 RoundTripper
+//
+// Description:
+//
+//	每个响应以原始 HTTP 报文格式保存为 {dir}/{sha256(方法+URL)}_{序号}.http，
+//	同一请求多次出现时按序号区分，回放时按相同顺序返回；序号超出录制次数时返回最后一次的响应
+type replayTransport struct {
+	dir    string
+	record bool // true: 录制模式; false: 回放模式
+	base   http.RoundTripper
+
+	mu    sync.Mutex
+	count map[string]int
+}
+
+// activeReplay 当前生效的录制/回放设置，nil 表示未开启
+var activeReplay *replayTransport
+
+// setupHTTPReplay 根据 HTTP_RECORD / HTTP_REPLAY 开启录制或回放
+//
+// Description:
+//
+//	开启后替换 http.DefaultTransport，未显式指定 Transport 的客户端都会经过录制/回放；
+//	自定义 Transport 的客户端需通过 wrapTransport 包装
+func setupHTTPReplay(recordDir, replayDir string) error {
+	// 同一进程中多次运行抓取流程（多配置方案、云函数多次调用）时只包装一次
+	if activeReplay != nil {
+		return nil
+	}
+	switch {
+	case recordDir != "" && replayDir != "":
+		return fmt.Errorf("HTTP_RECORD 与 HTTP_REPLAY 不能同时设置")
+	case recordDir != "":
+		if err := os.MkdirAll(recordDir, 0o755); err != nil {
+			return wrapErrorf(err, "创建录制目录失败: %s", recordDir)
+		}
+		activeReplay = &replayTransport{dir: recordDir, record: true, count: make(map[string]int)}
+		fmt.Printf("[INFO] 已开启 HTTP 录制, 响应将保存到 %s\n", recordDir)
+	case replayDir != "":
+		activeReplay = &replayTransport{dir: replayDir, count: make(map[string]int)}
+		fmt.Printf("[INFO] 已开启 HTTP 回放, 响应来自 %s\n", replayDir)
+	default:
+		return nil
+	}
+	http.DefaultTransport = wrapTransport(http.DefaultTransport)
+	return nil
+}
+
+// wrapTransport 在开启录制/回放或开发缓存时包装 base，否则原样返回
+func wrapTransport(base http.RoundTripper) http.RoundTripper {
+	if activeDevCache != nil {
+		return &devCacheTransport{dir: activeDevCache.dir, base: base}
+	}
+	if activeReplay == nil {
+		return base
+	}
+	return &replayTransport{dir: activeReplay.dir, record: activeReplay.record, base: base, count: activeReplay.count}
+}
+
+// fixturePath 返回请求第 n 次出现时对应的录制文件路径
+func (t *replayTransport) fixturePath(req *http.Request, n int) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.String()))
+	return filepath.Join(t.dir, fmt.Sprintf("%s_%d.http", hex.EncodeToString(sum[:8]), n))
+}
+
+// next 返回该请求本次出现的序号（从 0 开始）
+func (t *replayTransport) next(req *http.Request) int {
+	key := req.Method + " " + req.URL.String()
+	activeReplay.mu.Lock()
+	defer activeReplay.mu.Unlock()
+	n := t.count[key]
+	t.count[key] = n + 1
+	return n
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	n := t.next(req)
+	if !t.record {
+		return t.replay(req, n)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+
+	if err := saveRawResponse(t.fixturePath(req, n), resp, raw); err != nil {
+		fmt.Printf("[WARN] 保存录制响应失败: %s: %v\n", req.URL, err)
+	}
+	return resp, nil
+}
+
+// saveRawResponse 以原始 HTTP 报文格式保存响应，raw 为已读取的响应体
+//
+// Description:
+//
+//	Go 已自动解压的响应以解压后的内容保存并去掉 Content-Encoding；未自动解压（如 brotli）的保留原始内容与该头，
+//	由读取方按原样解压。读取时可直接交给 http.ReadResponse
+func saveRawResponse(path string, resp *http.Response, raw []byte) error {
+	saved := *resp
+	saved.Body = io.NopCloser(bytes.NewReader(raw))
+	saved.ContentLength = int64(len(raw))
+	saved.Header = resp.Header.Clone()
+	if resp.Uncompressed {
+		saved.Header.Del("Content-Encoding")
+	}
+	saved.TransferEncoding = nil
+	saved.TLS = nil
+	var buf bytes.Buffer
+	if err := saved.Write(&buf); err != nil {
+		return wrapErrorf(err, "序列化录制响应失败: %s", path)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// replay 读取录制文件并构造响应
+func (t *replayTransport) replay(req *http.Request, n int) (*http.Response, error) {
+	path := t.fixturePath(req, n)
+	for ; n > 0; n-- {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		path = t.fixturePath(req, n-1)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("没有录制的响应: %s %s", req.Method, req.URL)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), req)
+	if err != nil {
+		return nil, wrapErrorf(err, "解析录制响应失败: %s", strings.TrimPrefix(path, t.dir))
+	}
+	return resp, nil
+}
@@ -0,0 +1,251 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: i18n.go
+// Description: 运行日志的多语言支持，按 LHASA_LANG 配置将统计摘要、问题标题和错误信息输出为中文或英文
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// currentLang 当前输出语言：zh（默认）或 en
+var currentLang = "zh"
+
+// setLanguage 设置输出语言
+//
+// Description:
+//
+//	只有 "en" 时使用英文，其余情况（包括未设置）使用中文；
+//	配置来自专用的 LHASA_LANG 而不是系统的 LANG，en_US.UTF-8 等区域设置不会改变日志语言
+func setLanguage(lang string) {
+	if strings.EqualFold(strings.TrimSpace(lang), "en") {
+		currentLang = "en"
+	} else {
+		currentLang = "zh"
+	}
+}
+
+// tr 返回文案在当前语言下的版本
+//
+// Description:
+//
+//	源码中的文案即中文原文，并作为查找英文译文的键；没有译文时原样返回
+func tr(msg string) string {
+	if currentLang == "en" {
+		if en, ok := enMessages[msg]; ok {
+			return en
+		}
+	}
+	return msg
+}
+
+// trf 翻译格式化字符串后再格式化，用法同 fmt.Sprintf
+func trf(format string, args ...interface{}) string {
+	return fmt.Sprintf(tr(format), args...)
+}
+
+// enMessages 中文文案到英文译文的对照表
+var enMessages = map[string]string{
+	// 错误包装
+	"原因": "cause",
+
+	// 统计摘要
+	"本次订阅抓取结果统计:\n":                    "Feed fetch summary:\n",
+	"以下订阅已从RSS列表中删除, 其文章已清理:":          "These feeds were removed from the RSS list and their articles were dropped:",
+	"以下订阅已从RSS列表中删除, 其文章已标记为 retired:": "These feeds were removed from the RSS list and their articles were marked as retired:",
+	"%s: %d 篇":   "%s: %d articles",
+	"运行ID: %s\n": "Run ID: %s\n",
+	"版本: %s\n":   "Version: %s\n",
+	"生效配置（env: 环境变量, profile: 配置方案, default: 默认值, invalid: 无法解析已使用默认值）:": "Effective configuration (env: environment, profile: profile override, default: default value, invalid: unparsable, default used):",
+	"发送 Webmention %s":                             "sending Webmention %s",
+	"发送 Webmention 失败: %s: %v":                     "failed to send Webmention: %s: %v",
+	"已发送 %d 个 Webmention/Pingback, %d 个失败":         "sent %d Webmention/Pingback notifications, %d failed",
+	"配置方案: %s":                                     "profile: %s",
+	"日志索引序列化失败":                                    "failed to serialize the log index",
+	"日志缓冲已满 (LOG_BUFFER_MAX_BYTES=%d), 丢弃了 %d 行日志": "Log buffer full (LOG_BUFFER_MAX_BYTES=%d), dropped %d log lines",
+	"发送心跳失败":                                       "failed to send heartbeat",
+	"心跳地址无效":                                       "invalid heartbeat URL",
+	"快照不是 tar.gz 文件":                               "backup is not a tar.gz file",
+	"读取快照失败":                                       "failed to read backup",
+	"解析快照清单失败":                                     "failed to parse backup manifest",
+	"抓取成功 %d/%d (%.0f%%), 新文章 %d 篇, 运行ID %s":       "Fetched %d/%d (%.0f%%), %d new posts, run ID %s",
+	"设置提交状态失败":                                     "failed to set commit status",
+	"发送通知失败 (%s)":                                  "failed to send notification (%s)",
+	"本次抓取共下载 %s, 流量最多的域名:\n":                       "Downloaded %s in total, top domains by traffic:\n",
+	"(%d 条订阅)":                                     "(%d feeds)",
+	"读取 %s 失败":                                     "failed to read %s",
+	"解析 %s 失败":                                     "failed to parse %s",
+	"读取 %s 钩子模块失败: %s":                             "failed to read the %s hook module: %s",
+	"上传 %s 的 gzip 版本失败":                            "failed to upload the gzip variant of %s",
+	"✘ 有 %d 条订阅连续抓取失败, 已隔离 (QUARANTINE_AFTER):\n": "✘ %d feeds failed repeatedly and are quarantined (QUARANTINE_AFTER):\n",
+	"%s (连续 %d 次抓取失败, 隔离自 %s)":                    "%s (%d consecutive failures, quarantined since %s)",
+	"订阅已隔离":    "Feed quarantined",
+	"版本: %s":   "Version: %s",
+	"解压 %s 失败": "failed to decompress %s",
+	"文章数从 %d 篇减少到 %d 篇 (-%.0f%%), 超过阈值 %.0f%%":                                          "article count dropped from %d to %d (-%.0f%%), above the %.0f%% threshold",
+	"文章数骤减, 已阻止上传 data.json: %s. 确认无误后使用 --force 重新运行, 或设置 DATA_SHRINK_GUARD_MODE=warn": "article count dropped sharply, data.json upload blocked: %s. Re-run with --force once confirmed, or set DATA_SHRINK_GUARD_MODE=warn",
+	"data.json 上传已阻止":                             "data.json upload blocked",
+	"✘ 文章数比上次骤减:\n":                               "✘ Article count dropped sharply since the last run:\n",
+	"无权读取COS文件: %s":                               "no permission to read COS file: %s",
+	"无权读取旧 data.json, 跳过与旧数据的比较: %v":              "no permission to read the previous data.json, skipping comparison: %v",
+	"旧 data.json 暂时无法读取, 跳过与旧数据的比较: %v":           "previous data.json is temporarily unreadable, skipping comparison: %v",
+	"已跳过证书校验抓取 %s (INSECURE_TLS_DOMAINS)":         "fetched %s with certificate verification disabled (INSECURE_TLS_DOMAINS)",
+	"✘ 有 %d 条订阅跳过了证书校验 (INSECURE_TLS_DOMAINS):\n": "✘ %d feed(s) were fetched with certificate verification disabled (INSECURE_TLS_DOMAINS):\n",
+	"%s 发生 panic: %s":                             "panic in %s: %s",
+	"本次运行发生 %d 次 panic":                           "%d panic(s) recovered in this run",
+	"主流程":                                         "main pipeline",
+	"抓取 %s":                                       "fetching %s",
+	"上传 %s":                                       "uploading %s",
+	"加载 %s":                                       "loading %s",
+	"检查博客主页 %s":                                   "checking homepage %s",
+	"解析规范地址 %s":                                   "resolving canonical URL of %s",
+	"检查文章链接 %s":                                   "checking article link %s",
+	"生成摘要 %s":                                     "summarizing %s",
+	"文章分类 %s":                                     "tagging %s",
+	"翻译标题 %s":                                     "translating title of %s",
+	"共 %d 条RSS, 成功抓取 %d 条.\n":                     "%d feeds in total, %d fetched successfully.\n",
+	"✘ 有 %d 条订阅解析失败:\n":                           "✘ %d feeds failed to parse:\n",
+	"✘ 有 %d 条RSS地址返回的是网页而不是订阅:\n":                 "✘ %d feed URLs return a web page instead of a feed:\n",
+	"✘ 有 %d 条订阅需要登录或付费才能访问:\n":                    "✘ %d feeds require login or a paid subscription:\n",
+	"返回的是登录或付费订阅页面, 订阅可能需要授权访问":        "returned a login or paywall page, the feed may require authorization",
+	"看起来是 HTML 页面, 是否应为 %s ?":          "looks like an HTML page, did you mean %s ?",
+	"看起来是 HTML 页面, 页面中未发现订阅地址":         "looks like an HTML page, no feed link was found on it",
+	"✘ 有 %d 条订阅为空:\n":                  "✘ %d feeds are empty:\n",
+	"✘ 有 %d 条订阅文章数过少, 疑似被截断:\n":        "✘ %d feeds have too few items and may be truncated:\n",
+	"✘ 有 %d 条 data.json 体积告警:\n":       "✘ %d data.json size warnings:\n",
+	"✘ 有 %d 条订阅的文章标题或链接异常, 已截断或替换:\n":  "✘ %d feeds had malformed titles or links (truncated or replaced):\n",
+	"标题过长或含控制字符, 已截断为 %d 字":            "title too long or contains control characters, truncated to %d characters",
+	"博客名称过长或含控制字符, 已截断为 %d 字":          "blog name too long or contains control characters, truncated to %d characters",
+	"链接无法解析, 已替换为博客主页":                 "unparsable link, replaced with blog homepage",
+	"链接过长 (%d 字节), 已替换为博客主页":           "link too long (%d bytes), replaced with blog homepage",
+	"链接协议不安全 (%s:), 已替换为博客主页":          "unsafe link scheme (%s:), replaced with blog homepage",
+	"data.json 为 %s, 超过告警阈值 %s":        "data.json is %s, above the warning threshold of %s",
+	"data.json 比上次增长 %.0f%% (%s → %s)": "data.json grew %.0f%% since the last run (%s → %s)",
+	"✘ 有 %d 条订阅请求超时:\n":                "✘ %d feeds timed out:\n",
+	"✘ 有 %d 条订阅被服务器限流:\n":              "✘ %d feeds were throttled by the server:\n",
+	"✘ 有 %d 条订阅头像字段为空, 已使用默认头像:\n":     "✘ %d feeds have no avatar, using the default avatar:\n",
+	"✘ 有 %d 条订阅头像无法访问, 已使用默认头像:\n":     "✘ %d feeds have unreachable avatars, using the default avatar:\n",
+	"✘ 有 %d 条订阅的 HTTPS 证书即将到期:\n":      "✘ %d feeds have HTTPS certificates about to expire:\n",
+	"✘ 有 %d 篇文章链接已失效:\n":               "✘ %d article links are dead:\n",
+	"✘ 有 %d 个失联博客:\n":                  "✘ %d blogs are unreachable:\n",
+	"✘ 有 %d 条订阅疑似域名停放或被替换, 已暂停发布:\n":   "✘ %d feeds look parked or taken over and were not published:\n",
+	"运行摘要":    "run summary",
+	"抓取全部失败":  "all feeds failed",
+	"抓取成功率过低": "success ratio too low",
+	"成功抓取 %d/%d 条订阅 (%.0f%%), 低于发布阈值 %.0f%%, 保留现有 data.json 不做更新": "%d/%d feeds fetched (%.0f%%), below the publish threshold of %.0f%%, keeping the existing data.json unchanged",
+	"所有 %d 条订阅均抓取失败, 保留现有 data.json 不做更新":                         "all %d feeds failed to fetch, keeping the existing data.json unchanged",
+	"其中 %d 条经过重试才成功, %d 条使用了修复模式(忽略证书校验等):\n":                     "%d of them succeeded only after retrying, %d using fix mode (certificate checks skipped, etc.):\n",
+	"%s (尝试 %d 次)":                "%s (%d attempts)",
+	"没有任何警告或错误, 一切正常\n":           "No warnings or errors, everything is fine\n",
+	"%s (仅 %d 篇, 少于 %d 篇)":        "%s (only %d items, fewer than %d)",
+	"%s (证书已于 %s 过期)":             "%s (certificate expired on %s)",
+	"%s (证书将于 %s 到期, 剩余 %d 天)":    "%s (certificate expires on %s, %d days left)",
+	"%s (连续 %d 次无法访问, 最近可访问: %s)": "%s (unreachable %d runs in a row, last reachable: %s)",
+	"跳转到域名停放服务: ":                 "redirected to a domain parking service: ",
+	"包含域名停放文字: ":                  "contains domain parking text: ",
+	"%d/%d 篇文章包含垃圾关键词":            "%d/%d items contain spam keywords",
+	"标题由 %q 变为 %q, 且语言发生变化":       "title changed from %q to %q along with the language",
+	"标题由 %q 变为 %q, 且主页由 %s 变为 %s": "title changed from %q to %q and homepage from %s to %s",
+	"预检警告: ":                      "preflight warning: ",
+	"预检失败: ":                      "preflight failed: ",
+	"拉取RSS链接失败: %v":               "failed to fetch the RSS list: %v",
+	"RSS列表为空, 无需抓取":               "the RSS list is empty, nothing to fetch",
+	"生成 blogs.json 失败: %v":        "failed to generate blogs.json: %v",
+	"获取旧数据用于比较时失败: %v":            "failed to fetch previous data for comparison: %v",
+	"生成徽章失败: %v":                  "failed to generate badges: %v",
+	"更新统计表失败: %v":                 "failed to update the stats table: %v",
+	"抓取到的文章与现有数据相同，无需更新。":         "fetched articles are identical to the existing data, no update needed.",
+	"JSON序列化失败: %v":               "JSON serialization failed: %v",
+	"备份 COS 中的 data.json 失败: %v":  "failed to back up data.json in COS: %v",
+	"上传 data.json 失败: %v":         "failed to upload data.json: %v",
+	"刷新CDN缓存失败: %v":               "failed to purge the CDN cache: %v",
+
+	// wrapErrorf 描述
+	"COS预检失败: %s": "COS preflight failed: %s",
+	"COS预检失败: 存储桶不存在, 请检查 DATA 地址: %s":                                           "COS preflight failed: bucket does not exist, check the DATA URL: %s",
+	"COS预检失败: 无权访问存储桶, 请检查 TENCENT_CLOUD_SECRET_ID/TENCENT_CLOUD_SECRET_KEY 的权限": "COS preflight failed: access to the bucket denied, check the permissions of TENCENT_CLOUD_SECRET_ID/TENCENT_CLOUD_SECRET_KEY",
+	"GitHub预检失败: 无法访问 %s":                                                        "GitHub preflight failed: cannot access %s",
+	"GitHub预检失败: 解析仓库信息失败":                                                       "GitHub preflight failed: cannot parse repository info",
+	"HTTP状态码: %d, Body: %s":                                                      "HTTP status: %d, body: %s",
+	"RSS列表预检失败: %s":                                                              "RSS list preflight failed: %s",
+	"RSS列表预检失败: 无法访问 %s":                                                         "RSS list preflight failed: cannot access %s",
+	"RSS列表预检失败: 本地文件不可读, 请检查 RSS: %s":                                            "RSS list preflight failed: local file is not readable, check RSS: %s",
+	"problems.json 序列化失败":                                                        "failed to serialize problems.json",
+	"blogs.json 序列化失败":                                                           "failed to serialize blogs.json",
+	"spotlight.json 序列化失败":                                                       "failed to serialize spotlight.json",
+	"onthisday.json 序列化失败":                                                       "failed to serialize onthisday.json",
+	"健康订阅序列化失败":                                                                  "failed to serialize health feed",
+	"lhasaRSS 友链健康状况":                                                            "lhasaRSS blogroll health",
+	"订阅失效、头像不可用、证书即将到期等问题的出现与恢复":                                                 "Feeds going dead, broken avatars, expiring certificates and their recovery",
+	"[已恢复] %s: %s":                                                               "[Recovered] %s: %s",
+	"%s 的问题已不再出现":                                                                "the problem with %s no longer occurs",
+	"订阅解析失败":                                                                     "Feed parse failed",
+	"订阅需要登录或付费":                                                                  "Feed requires login or payment",
+	"RSS地址返回网页":                                                                  "RSS URL returns a web page",
+	"订阅为空":                                                                       "Feed is empty",
+	"订阅文章数过少":                                                                    "Feed has too few items",
+	"订阅被限流":                                                                      "Feed throttled",
+	"订阅请求超时":                                                                     "Feed request timed out",
+	"头像为空":                                                                       "Avatar missing",
+	"头像无法访问":                                                                     "Avatar unreachable",
+	"HTTPS 证书即将到期":                                                               "HTTPS certificate expiring",
+	"文章链接失效":                                                                     "Article link dead",
+	"博客失联":                                                                       "Blog unreachable",
+	"疑似域名停放或被替换":                                                                 "Domain parked or replaced",
+	"文章字段异常":                                                                     "Malformed article fields",
+	"gzip压缩失败":                                                                   "gzip compression failed",
+	"上传 %s 到 GitHub 失败":                                                          "failed to upload %s to GitHub",
+	"上传至COS失败":                                                                   "failed to upload to COS",
+	"从 COS 获取 %s 失败":                                                             "failed to get %s from COS",
+	"从 GitHub 获取 %s 失败":                                                          "failed to get %s from GitHub",
+	"从 Cloudflare KV 获取 %s 失败":                                                   "failed to get %s from Cloudflare KV",
+	"上传 %s 到 Cloudflare KV 失败":                                                   "failed to upload %s to Cloudflare KV",
+	"Cloudflare KV预检失败: 无法访问 Cloudflare API":                                     "Cloudflare KV preflight failed: cannot access the Cloudflare API",
+	"从 GitHub 获取固定数据失败":                                                          "failed to get the fixed data from GitHub",
+	"写入抓取缓存失败: %s":                                                               "failed to write the fetch cache: %s",
+	"列出COS备份失败: %s":                                                              "failed to list COS backups: %s",
+	"创建录制目录失败: %s":                                                               "failed to create the recording directory: %s",
+	"创建缓存目录失败: %s":                                                               "failed to create the cache directory: %s",
+	"创建诊断输出目录失败: %s":                                                             "failed to create dump directory: %s",
+	"发送新文章摘要失败: %v":                                                              "failed to send new article digest: %v",
+	"共 %d 篇新文章:\n":                                                               "%d new articles:\n",
+	"新文章摘要":                                                                      "New articles digest",
+	"刷新CDN缓存失败":                                                                  "failed to purge the CDN cache",
+	"发送通知失败":                                                                     "failed to send notification",
+	"备份COS对象失败: %s => %s":                                                        "failed to back up COS object: %s => %s",
+	"序列化 %s 钩子输入失败":                                                              "failed to serialize %s hook input",
+	"序列化固定数据失败":                                                                  "failed to serialize the fixed data",
+	"序列化录制响应失败: %s":                                                              "failed to serialize recorded response: %s",
+	"序列化抓取缓存失败":                                                                  "failed to serialize the fetch cache",
+	"徽章序列化失败: %s":                                                                "failed to serialize badge: %s",
+	"执行 %s 钩子失败: %s":                                                             "failed to run %s hook: %s",
+	"无法获取COS文件: %s":                                                              "cannot get COS file: %s",
+	"获取COS文件失败: %s":                                                              "failed to get COS file: %s",
+	"更新 %s 失败":                                                                   "failed to update %s",
+	"检查COS对象是否存在失败: %s":                                                          "failed to check whether COS object exists: %s",
+	"获取 %s 失败":                                                                   "failed to get %s",
+	"获取 %s 文件SHA失败":                                                              "failed to get SHA of %s",
+	"获取RSS列表失败: %s":                                                              "failed to get the RSS list: %s",
+	"获取十年之约成员列表失败: %s":                                                           "failed to get the Foreverblog member list: %s",
+	"获取固定数据文件SHA失败":                                                              "failed to get SHA of the fixed data file",
+	"获取旧 data.json 失败":                                                           "failed to get the previous data.json",
+	"解析 %s 钩子输出失败":                                                               "failed to parse %s hook output",
+	"解析CDN刷新结果失败":                                                                "failed to parse the CDN purge result",
+	"解析RSS失败: %s":                                                                "failed to parse RSS: %s",
+	"解析dataURL失败: %s":                                                            "failed to parse dataURL: %s",
+	"解析十年之约成员列表失败":                                                               "failed to parse the Foreverblog member list",
+	"解析固定数据失败":                                                                   "failed to parse the fixed data",
+	"解析录制响应失败: %s":                                                               "failed to parse recorded response: %s",
+	"解析翻译结果失败":                                                                   "failed to parse the translation result",
+	"读取COS文件body失败":                                                              "failed to read COS file body",
+	"解析OPML失败":                                                                   "failed to parse OPML",
+	"读取导入文件失败: %s":                                                               "failed to read import source: %s",
+	"解析hexo-circle-of-friends数据失败":                                               "failed to parse hexo-circle-of-friends data",
+	"解析头像映射文件失败: %s":                                                             "failed to parse avatar mapping file: %s",
+	"从Gist读取RSS列表失败: %s":                                                         "failed to read the RSS list from Gist: %s",
+	"从GitHub仓库读取RSS列表失败: %s":                                                     "failed to read the RSS list from the GitHub repository: %s",
+	"GitHub仓库 %s/%s 中不存在RSS列表: %s":                                               "RSS list not found in GitHub repository %s/%s: %s",
+	"RSS列表预检失败: 无法从GitHub仓库读取 %s":                                                "RSS list preflight failed: cannot read %s from the GitHub repository",
+	"读取Github RSS文件失败: %s":                                                       "failed to read the RSS file from GitHub: %s",
+}
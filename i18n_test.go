@@ -0,0 +1,79 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: i18n_test.go
+// Description: 日志语言设置与英文译文完整性的测试
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSetLanguage(t *testing.T) {
+	defer setLanguage("")
+	tests := []struct{ in, want string }{
+		{"en", "en"},
+		{" EN ", "en"},
+		{"zh", "zh"},
+		{"", "zh"},
+		// 系统区域设置不应改变日志语言
+		{"en_US.UTF-8", "zh"},
+		{"C.UTF-8", "zh"},
+	}
+	for _, tt := range tests {
+		setLanguage(tt.in)
+		if currentLang != tt.want {
+			t.Errorf("setLanguage(%q): currentLang = %q, want %q", tt.in, currentLang, tt.want)
+		}
+	}
+}
+
+// TestMessagesTranslated 检查 tr、trf、wrapErrorf 中使用的文案都有英文译文
+func TestMessagesTranslated(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			fn, ok := call.Fun.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			arg := map[string]int{"tr": 0, "trf": 0, "wrapErrorf": 1}
+			i, ok := arg[fn.Name]
+			if !ok || len(call.Args) <= i {
+				return true
+			}
+			lit, ok := call.Args[i].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			msg, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := enMessages[msg]; !ok {
+				t.Errorf("%s: %q 没有英文译文（enMessages）", fset.Position(lit.Pos()), msg)
+			}
+			return true
+		})
+	}
+}
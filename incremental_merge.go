@@ -0,0 +1,158 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: incremental_merge.go
+// Description: 增量合并，基于上次的 data.json 应用本次运行的新增/更新/移除差异，而不是整体覆盖
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// dataDelta 本次运行相对上次 data.json 的差异
+type dataDelta struct {
+	Added   []Article // 新增的文章
+	Updated []Article // 链接相同但内容变化的文章
+	Removed []Article // 被移除的文章
+	Retired []Article // 所属订阅已从RSS列表中删除的文章（REMOVED_FEEDS=drop 时同时计入 Removed）
+	Kept    int       // 因订阅本次抓取失败而保留的旧文章数
+}
+
+// empty 判断是否没有任何差异
+func (d dataDelta) empty() bool {
+	return len(d.Added) == 0 && len(d.Updated) == 0 && len(d.Removed) == 0 && len(d.Retired) == 0
+}
+
+// String 生成差异摘要，用于日志
+func (d dataDelta) String() string {
+	return fmt.Sprintf("新增 %d 篇, 更新 %d 篇, 移除 %d 篇, 已删除订阅的文章 %d 篇, 保留抓取失败订阅的旧文章 %d 篇",
+		len(d.Added), len(d.Updated), len(d.Removed), len(d.Retired), d.Kept)
+}
+
+// retiredBlogs 按博客汇总已删除订阅的文章数，用于写入日志
+func (d dataDelta) retiredBlogs() []string {
+	counts := make(map[string]int)
+	var order []string
+	for _, a := range d.Retired {
+		key := fmt.Sprintf("%s (%s)", a.BlogName, a.Feed)
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+	lines := make([]string, 0, len(order))
+	for _, key := range order {
+		lines = append(lines, trf("%s: %d 篇", key, counts[key]))
+	}
+	return lines
+}
+
+// mergeIncremental 将本次结果与上次的 data.json 增量合并
+//
+// Description:
+//
+//	以文章链接对比新旧数据：本次新出现的为新增，内容变化的为更新；
+//	旧文章不在本次结果中时，若其所属订阅本次抓取失败（或本次未抓取），则保留旧文章，
+//	否则（订阅已有更新的文章、固定数据被删除等）视为移除
+//	这样单个订阅临时失败或只抓取部分订阅时，不会把其他博客的文章从 data.json 中抹掉
+//	所属订阅已不在RSS列表中的旧文章按 removedMode 处理：drop 移除，retire 保留并标记 retired
+//
+// Parameters:
+//   - previous    : 上次的 data.json 文章
+//   - current     : 本次生成的文章
+//   - succeeded   : 本次抓取成功的订阅地址
+//   - listed      : 本次RSS列表中的所有订阅地址
+//   - removedMode : 已删除订阅的文章的处理方式，drop 或 retire
+//
+// Returns:
+//   - []Article: 合并后的文章，已按置顶、权重和发布时间排序
+//   - dataDelta: 差异明细
+func mergeIncremental(previous, current []Article, succeeded, listed map[string]bool, removedMode string) ([]Article, dataDelta) {
+	var delta dataDelta
+	prevByLink := make(map[string]Article, len(previous))
+	for _, p := range previous {
+		prevByLink[p.Link] = p
+	}
+	currentLinks := make(map[string]bool, len(current))
+
+	merged := make([]timedArticle, 0, len(current)+len(previous))
+	for _, a := range current {
+		currentLinks[a.Link] = true
+		if p, ok := prevByLink[a.Link]; !ok {
+			delta.Added = append(delta.Added, a)
+		} else if articleToKey(p) != articleToKey(a) {
+			delta.Updated = append(delta.Updated, a)
+		}
+		merged = append(merged, timedArticle{a, publishedTime(a)})
+	}
+
+	listedKeys := make(map[string]bool, len(listed))
+	for feed := range listed {
+		listedKeys[urlnorm.Key(feed)] = true
+	}
+
+	for _, p := range previous {
+		if currentLinks[p.Link] {
+			continue
+		}
+		if p.Feed != "" && !listedKeys[urlnorm.Key(p.Feed)] {
+			switch {
+			case removedMode == "retire" && p.Retired:
+				// 之前的运行已标记过，原样保留，不再计入差异
+			case removedMode == "retire":
+				delta.Retired = append(delta.Retired, p)
+				p.Retired = true
+			default:
+				delta.Retired = append(delta.Retired, p)
+				delta.Removed = append(delta.Removed, p)
+				continue
+			}
+			merged = append(merged, timedArticle{p, publishedTime(p)})
+			continue
+		}
+		if p.Feed != "" && !succeeded[p.Feed] {
+			delta.Kept++
+			merged = append(merged, timedArticle{p, publishedTime(p)})
+			continue
+		}
+		delta.Removed = append(delta.Removed, p)
+	}
+
+	sortTimedArticles(merged)
+	articles := make([]Article, 0, len(merged))
+	for _, m := range merged {
+		articles = append(articles, m.article)
+	}
+	return articles, delta
+}
+
+// listedFeeds 返回本次RSS列表中的所有订阅地址
+func listedFeeds(entries []feedEntry) map[string]bool {
+	listed := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		listed[e.URL] = true
+	}
+	return listed
+}
+
+// succeededFeeds 返回本次抓取成功的订阅地址
+func succeededFeeds(results []feedResult) map[string]bool {
+	ok := make(map[string]bool, len(results))
+	for _, r := range results {
+		if r.Err == nil && r.Article != nil {
+			ok[r.FeedLink] = true
+		}
+	}
+	return ok
+}
+
+// publishedTime 解析文章的发布时间（"Jan 02, 2006" 格式），解析失败时返回零值
+func publishedTime(a Article) time.Time {
+	t, err := time.Parse("Jan 02, 2006", a.Published)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
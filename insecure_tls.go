@@ -0,0 +1,45 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: insecure_tls.go
+// Description: 修复模式重试时跳过证书校验的白名单（INSECURE_TLS_DOMAINS 与订阅的 insecure= 配置），
+// 每次跳过校验的抓取都会记录到日志
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// insecureAllowed 判断订阅在修复模式下是否允许跳过证书校验
+//
+// Description:
+//
+//	订阅单独配置了 insecure=true/false 时以订阅配置为准；否则按 INSECURE_TLS_DOMAINS 匹配 RSS 地址的域名，
+//	列表中的域名同时匹配其子域名，"*" 表示所有订阅（默认，与旧版本行为一致），"none" 表示全部禁止
+func insecureAllowed(cfg *Config, e feedEntry) bool {
+	if e.Insecure != nil {
+		return *e.Insecure
+	}
+	host := urlnorm.Host(e.URL)
+	for _, d := range cfg.InsecureTLSDomains {
+		d = strings.TrimPrefix(strings.ToLower(d), ".")
+		switch {
+		case d == "*":
+			return true
+		case d == "none":
+			return false
+		case host != "" && (host == d || strings.HasSuffix(host, "."+d)):
+			return true
+		}
+	}
+	return false
+}
+
+// logInsecureFetch 醒目地记录一次跳过证书校验的抓取，同时写入运行日志
+func logInsecureFetch(rssLink string) {
+	msg := trf("已跳过证书校验抓取 %s (INSECURE_TLS_DOMAINS)", rssLink)
+	fmt.Printf("[WARN] !!! %s\n", msg)
+	appendLog("[WARN] " + msg)
+}
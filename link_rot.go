@@ -0,0 +1,90 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: link_rot.go
+// Description: 文章链接失效检测，定期检查 data.json 中的文章链接，对已经 404 的条目进行标记或剔除
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// linkCheck 单个文章链接的检测记录，保存在抓取缓存中
+type linkCheck struct {
+	Status    int       `json:"status"`     // 最近一次检测的 HTTP 状态码
+	CheckedAt time.Time `json:"checked_at"` // 最近一次检测时间
+}
+
+// linkCheckResult 读取文章链接的检测记录
+func (c *fetchCache) linkCheckResult(link string) (linkCheck, bool) {
+	return cacheLookup(c, func(c *fetchCache) *map[string]linkCheck { return &c.LinkChecks }, link)
+}
+
+// setLinkCheckResult 写入文章链接的检测记录
+func (c *fetchCache) setLinkCheckResult(link string, lc linkCheck) {
+	cacheAssign(c, func(c *fetchCache) *map[string]linkCheck { return &c.LinkChecks }, link, lc)
+}
+
+// isDeadStatus 404 与 410 视为链接失效，其余状态（含超时等临时错误）不做判断
+func isDeadStatus(status int) bool {
+	return status == http.StatusNotFound || status == http.StatusGone
+}
+
+// checkArticleLinks 检测文章链接是否失效，按 mode 标记或剔除失效条目
+//
+// Description:
+//
+//	每个链接在 interval 时间内只检测一次，检测结果保存在抓取缓存中，
+//	避免每次运行都请求所有文章；mode 为 "flag" 时设置 Article.Dead，为 "drop" 时从结果中移除
+//
+// Returns:
+//   - []Article: 处理后的文章列表
+//   - []string : 失效的文章链接
+func checkArticleLinks(ctx context.Context, articles []Article, mode string, interval time.Duration, cache *fetchCache) ([]Article, []string) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	sem := make(chan struct{}, 10)
+	var wg sync.WaitGroup
+
+	for _, a := range articles {
+		if lc, ok := cache.linkCheckResult(a.Link); ok && time.Since(lc.CheckedAt) < interval {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(link string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer recoverPanic(trf("检查文章链接 %s", link), nil)
+
+			status, err := probeURL(ctx, client, link, "HEAD")
+			if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+				status, err = probeURL(ctx, client, link, "GET")
+			}
+			if err != nil {
+				// 网络错误不代表链接失效，下次运行再检测
+				return
+			}
+			cache.setLinkCheckResult(link, linkCheck{Status: status, CheckedAt: time.Now()})
+		}(a.Link)
+	}
+	wg.Wait()
+
+	var kept []Article
+	var dead []string
+	for _, a := range articles {
+		lc, ok := cache.linkCheckResult(a.Link)
+		if !ok || !isDeadStatus(lc.Status) {
+			kept = append(kept, a)
+			continue
+		}
+		dead = append(dead, fmt.Sprintf("%s (HTTP %d)", a.Link, lc.Status))
+		if mode == "flag" {
+			a.Dead = true
+			kept = append(kept, a)
+		}
+	}
+	return kept, dead
+}
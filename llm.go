@@ -0,0 +1,104 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: llm.go
+// Description: 调用 OpenAI 兼容的 Chat Completions 接口，用于文章摘要等可选的内容增强
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// enrichConcurrency 内容增强（摘要、主题分类、标题翻译）同时发出的请求数，避免触发大模型与翻译接口的限流
+const enrichConcurrency = 3
+
+// forEachLimited 以最多 limit 个协程并发处理 items，全部处理完成后返回
+//
+// Description:
+//
+//	fn 中的 panic 需由调用方自行 recover（通常 defer recoverPanic），以便在日志中标明是哪一项出错
+func forEachLimited[T any](items []T, limit int, fn func(T)) {
+	sem := make(chan struct{}, max(limit, 1))
+	var wg sync.WaitGroup
+	for _, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(item)
+		}()
+	}
+	wg.Wait()
+}
+
+// chatCompletion 调用 OpenAI 兼容接口，返回模型回复的文本
+//
+// Description:
+//
+//	请求地址为 {LLM_BASE_URL}/chat/completions，使用 Bearer LLM_API_KEY 认证，
+//	兼容 OpenAI、DeepSeek、通义千问等提供 OpenAI 兼容协议的服务
+//
+// Parameters:
+//   - system : 系统提示词
+//   - user   : 用户输入
+//
+// Returns:
+//   - string: 去掉首尾空白后的回复内容
+//   - error : 请求失败、状态码异常或回复为空时返回
+func chatCompletion(ctx context.Context, cfg *Config, system, user string) (string, error) {
+	payload := map[string]interface{}{
+		"model": cfg.LLMModel,
+		"messages": []map[string]string{
+			{"role": "system", "content": system},
+			{"role": "user", "content": user},
+		},
+		"temperature": 0.3,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	apiURL := strings.TrimSuffix(cfg.LLMBaseURL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.LLMAPIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBytes, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("chat completion failed, status: %d, body: %s", resp.StatusCode, string(respBytes))
+	}
+
+	var result struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if len(result.Choices) == 0 || strings.TrimSpace(result.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("chat completion returned empty content")
+	}
+	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,41 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: llm_test.go
+// Description: 内容增强并发池（forEachLimited）的测试
+
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEachLimited(t *testing.T) {
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	items := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+	forEachLimited(items, 3, func(i int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		mu.Lock()
+		seen[i] = true
+		mu.Unlock()
+	})
+
+	if len(seen) != len(items) {
+		t.Fatalf("处理了 %d 项, want %d", len(seen), len(items))
+	}
+	if p := peak.Load(); p > 3 || p < 1 {
+		t.Fatalf("最大并发数 = %d, want 1..3", p)
+	}
+}
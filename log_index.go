@@ -0,0 +1,136 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: log_index.go
+// Description: 日志文件的分卷与索引，当天日志超过 LOG_MAX_BYTES 时写入新的分卷（2025-06-01.1.log），
+// logs/index.json 按日期列出所有日志文件，便于查看与下载
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// logIndexName 日志索引文件名，与日志文件位于同一目录
+const logIndexName = "index.json"
+
+// logFilePattern 日志文件名：日期，及可选的分卷序号
+var logFilePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?:\.(\d+))?\.log$`)
+
+// logFile 仓库中的一个日志文件
+type logFile struct {
+	Date string // 日期，如 2025-06-01
+	Part int    // 分卷序号，当天第一个文件为 0
+	Name string // 文件名
+	SHA  string // 文件 SHA，本次新建的文件为空
+	Size int    // 文件大小（字节）
+}
+
+// logFileName 返回日志文件名，第一个分卷为 2025-06-01.log，之后为 2025-06-01.1.log、2025-06-01.2.log ...
+func logFileName(date string, part int) string {
+	if part == 0 {
+		return date + ".log"
+	}
+	return fmt.Sprintf("%s.%d.log", date, part)
+}
+
+// parseLogFiles 从目录列表中提取日志文件，按日期与分卷序号排序
+func parseLogFiles(entries []githubDirEntry) []logFile {
+	var files []logFile
+	for _, e := range entries {
+		m := logFilePattern.FindStringSubmatch(e.Name)
+		if e.Type != "file" || m == nil {
+			continue
+		}
+		part, _ := strconv.Atoi(m[2])
+		files = append(files, logFile{Date: m[1], Part: part, Name: e.Name, SHA: e.SHA, Size: e.Size})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Date != files[j].Date {
+			return files[i].Date < files[j].Date
+		}
+		return files[i].Part < files[j].Part
+	})
+	return files
+}
+
+// currentLogFile 选择本次日志写入的文件
+//
+// Description:
+//
+//	追加到当天最后一个分卷；追加后会超过 maxBytes 时改为新建下一个分卷（maxBytes <= 0 表示不分卷），
+//	单次写入本身超过上限时同样独占一个新分卷，不会被截断
+//
+// Returns:
+//   - logFile : 写入的文件
+//   - bool    : 是否为新建的文件（无需读取旧内容）
+func currentLogFile(files []logFile, date string, segmentSize, maxBytes int) (logFile, bool) {
+	var last *logFile
+	for i := range files {
+		if files[i].Date == date {
+			last = &files[i]
+		}
+	}
+	switch {
+	case last == nil:
+		return logFile{Date: date, Name: logFileName(date, 0)}, true
+	case maxBytes > 0 && last.Size > 0 && last.Size+segmentSize > maxBytes:
+		return logFile{Date: date, Part: last.Part + 1, Name: logFileName(date, last.Part+1)}, true
+	default:
+		return *last, false
+	}
+}
+
+// logIndex logs/index.json 的内容
+type logIndex struct {
+	Updated string        `json:"updated"` // 索引更新时间
+	Days    []logIndexDay `json:"days"`    // 按日期倒序排列
+}
+
+// logIndexDay 某一天的日志文件
+type logIndexDay struct {
+	Date  string   `json:"date"`
+	Files []string `json:"files"` // 按分卷顺序排列的文件名
+}
+
+// buildLogIndex 由日志文件列表生成索引
+func buildLogIndex(files []logFile, now time.Time) logIndex {
+	index := logIndex{Updated: now.Format("2006-01-02 15:04:05"), Days: []logIndexDay{}}
+	for _, f := range files {
+		if n := len(index.Days); n > 0 && index.Days[n-1].Date == f.Date {
+			index.Days[n-1].Files = append(index.Days[n-1].Files, f.Name)
+			continue
+		}
+		index.Days = append(index.Days, logIndexDay{Date: f.Date, Files: []string{f.Name}})
+	}
+	slices.Reverse(index.Days)
+	return index
+}
+
+// writeLogIndex 文件列表有变化（新建分卷或删除旧日志）时更新 logs/index.json
+//
+// Description:
+//
+//	只记录文件名，不记录大小，日志追加本身不会改动索引，避免每次运行多一次提交
+func writeLogIndex(ctx context.Context, cfg *Config, before, after []logFile, indexSHA string) error {
+	names := func(files []logFile) []string {
+		var list []string
+		for _, f := range files {
+			list = append(list, f.Name)
+		}
+		return list
+	}
+	if indexSHA != "" && slices.Equal(names(before), names(after)) {
+		return nil
+	}
+	data, err := marshalJSON(buildLogIndex(after, logNow()), cfg.OutputIndent)
+	if err != nil {
+		return wrapErrorf(err, "日志索引序列化失败")
+	}
+	return putGitHubFile(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo,
+		repoPath("logs", logIndexName), indexSHA, string(data), "Update log index", cfg.commitSignature())
+}
@@ -0,0 +1,44 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: log_timezone.go
+// Description: 日志使用的时区（LOG_TIMEZONE），决定日志时间戳与按天切分日志文件的日期，
+// 使 UTC 的运行环境也能按北京时间零点切换到新一天的日志文件
+
+package main
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // 内置时区数据库，云函数等精简镜像中没有 /usr/share/zoneinfo 时也能加载时区
+)
+
+// logLocation 日志使用的时区，默认为进程时区（TZ 环境变量）
+var logLocation = time.Local
+
+// setLogTimezone 设置日志使用的时区，name 为空时使用进程时区
+func setLogTimezone(name string) error {
+	if name == "" {
+		logLocation = time.Local
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("LOG_TIMEZONE 时区无效: %s (%v)", name, err)
+	}
+	logLocation = loc
+	return nil
+}
+
+// logNow 返回日志时区的当前时间
+func logNow() time.Time {
+	return time.Now().In(logLocation)
+}
+
+// logDaysAgo 返回日志时区中 n 天前的日期零点
+//
+// Description:
+//
+//	按日历日计算而不是减去 n*24 小时，夏令时切换当天（23 或 25 小时）也不会错位
+func logDaysAgo(n int) time.Time {
+	now := logNow()
+	return time.Date(now.Year(), now.Month(), now.Day()-n, 0, 0, 0, 0, logLocation)
+}
@@ -10,47 +10,110 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"path/filepath"
-	"regexp"
+	"os"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
 )
 
-// appendLog 将日志内容追加到 GitHub 仓库中的某一天的日志文件里
+// logBuffer 本次运行尚未写入 GitHub 的日志
+var logBuffer struct {
+	mu sync.Mutex
+	sb strings.Builder
+
+	limit   int // 缓冲的大小上限（字节），<= 0 表示不限制
+	dropped int // 缓冲已满后丢弃的行数
+}
+
+// setLogBufferLimit 设置日志缓冲的大小上限（LOG_BUFFER_MAX_BYTES）
+func setLogBufferLimit(limit int) {
+	logBuffer.mu.Lock()
+	defer logBuffer.mu.Unlock()
+	logBuffer.limit = limit
+}
+
+// appendLog 将日志内容追加到本次运行的日志缓冲中
 //
 // Description:
 //
-//	每次调用本函数，会将传入的 rawLogContent（原始日志）按行加上时间戳后，
-//	追加写入到当日日期命名的日志文件： logs/2025-03-10.log
-//	若日志文件不存在，会自动创建
-//	同时会调用 cleanOldLogs 清理 7 天之前的日志文件
-func appendLog(ctx context.Context, rawLogContent string) error {
-	cfg := LoadConfig()
+//	传入的 rawLogContent（原始日志）按行加上当前时间戳和本次运行的 runID 后写入内存缓冲，
+//	由 flushLog 在运行结束时一次性写入 GitHub，避免多次读写同一日志文件产生冲突
+//	大量订阅源同时失败时缓冲可能快速增长，超过 LOG_BUFFER_MAX_BYTES 后不再写入新的行，
+//	只记录丢弃的行数（首次丢弃时在控制台提示），由 flushLog 在日志末尾写明，调用方不会因此阻塞
+func appendLog(rawLogContent string) {
+	timestamp := logNow().Format("2006-01-02 15:04:05")
 
-	committerName := cfg.GitHubName
-	committerEmail := cfg.GitHubName + "@users.noreply.github.com"
+	logBuffer.mu.Lock()
+	defer logBuffer.mu.Unlock()
+	for _, line := range strings.Split(rawLogContent, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		entry := fmt.Sprintf("[%s] [%s] %s\n", timestamp, runID, line)
+		if logBuffer.limit > 0 && logBuffer.sb.Len()+len(entry) > logBuffer.limit {
+			if logBuffer.dropped == 0 {
+				fmt.Printf("[WARN] 日志缓冲已满 (%d 字节), 之后的日志只输出到控制台\n", logBuffer.limit)
+			}
+			logBuffer.dropped++
+			continue
+		}
+		logBuffer.sb.WriteString(entry)
+	}
+}
 
-	dateStr := time.Now().Format("2006-01-02")
-	logPath := filepath.Join("logs", dateStr+".log")
+// flushLog 将缓冲中的日志追加到 GitHub 仓库中当天的日志文件里
+//
+// Description:
+//
+//	追加写入到当日日期（按 LOG_TIMEZONE 时区）命名的日志文件： logs/2025-03-10.log，若日志文件不存在，会自动创建；
+//	追加后超过 LOG_MAX_BYTES 时改为写入新的分卷 logs/2025-03-10.1.log，单个文件不会无限增长
+//	整个运行只读写一次日志文件；缓冲为空时不发起请求
+//	缓冲已满时丢弃过的行数会作为最后一行写入，不受缓冲上限限制
+//	写入成功后清空缓冲，调用 cleanOldLogs 清理 7 天之前的日志文件，并在文件列表变化时更新 logs/index.json
+func flushLog(ctx context.Context) error {
+	logBuffer.mu.Lock()
+	defer logBuffer.mu.Unlock()
+	newLogSegment := logBuffer.sb.String()
+	if logBuffer.dropped > 0 {
+		newLogSegment += fmt.Sprintf("[%s] [%s] [WARN] %s\n", logNow().Format("2006-01-02 15:04:05"), runID,
+			trf("日志缓冲已满 (LOG_BUFFER_MAX_BYTES=%d), 丢弃了 %d 行日志", logBuffer.limit, logBuffer.dropped))
+	}
+	if newLogSegment == "" {
+		return nil
+	}
 
-	// 先获取旧日志内容和旧日志文件的SHA
-	oldContent, oldSHA, err := getGitHubFileContent(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, logPath)
+	cfg := LoadConfig()
+
+	// 列出已有的日志文件，确定写入当天的哪个分卷
+	entries, err := listGitHubDir(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, repoPath("logs"))
 	if err != nil {
 		return err
 	}
+	files := parseLogFiles(entries)
+	var indexSHA string
+	for _, e := range entries {
+		if e.Name == logIndexName {
+			indexSHA = e.SHA
+		}
+	}
 
-	// 构造新的日志段落，将 rawLogContent 每一行都加上当前时间戳
-	var sb strings.Builder
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	lines := strings.Split(rawLogContent, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+	dateStr := logNow().Format("2006-01-02")
+	target, fresh := currentLogFile(files, dateStr, len(newLogSegment), cfg.LogMaxBytes)
+	logPath := repoPath("logs", target.Name)
+
+	// 追加到已有分卷时先获取旧日志内容和旧日志文件的SHA
+	var oldContent, oldSHA string
+	if !fresh {
+		if oldContent, oldSHA, err = getGitHubFileContent(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, logPath); err != nil {
+			return err
 		}
-		sb.WriteString(fmt.Sprintf("[%s] %s\n", timestamp, line))
 	}
-	newLogSegment := sb.String()
 
 	// 拼接到旧日志内容上
 	newContent := oldContent + newLogSegment
@@ -64,129 +127,230 @@ func appendLog(ctx context.Context, rawLogContent string) error {
 		logPath,
 		oldSHA,
 		newContent,
-		"Update log: "+dateStr,
-		committerName,
-		committerEmail,
+		"Update log: "+target.Name,
+		cfg.commitSignature(),
 	)
 	if err != nil {
 		return err
 	}
+	logBuffer.sb.Reset()
+	logBuffer.dropped = 0
+
+	before := files
+	if fresh {
+		target.Size = len(newContent)
+		files = append(slices.Clone(files), target)
+	}
 
-	// 清理7天前的日志
-	return cleanOldLogs(ctx)
+	// 清理7天前的日志，并更新索引
+	files = cleanOldLogs(ctx, cfg, files)
+	return writeLogIndex(ctx, cfg, before, files, indexSHA)
 }
 
 // cleanOldLogs 删除7天前的日志文件
 //
 // Description:
 //
-//	遍历 logs 目录下的所有文件，检查文件名是否符合 "YYYY-MM-DD.log" 的日期格式，
-//	若其日期早于7天前，则删除
-func cleanOldLogs(ctx context.Context) error {
-	cfg := LoadConfig()
-
-	committerName := cfg.GitHubName
-	committerEmail := cfg.GitHubName + "@users.noreply.github.com"
+//	检查 logs 目录下的日志文件（含分卷），若其日期早于7天前，则删除
+//
+// Returns:
+//   - []logFile : 清理后剩余的日志文件（删除失败的文件仍保留）
+func cleanOldLogs(ctx context.Context, cfg *Config, files []logFile) []logFile {
+	sevenDaysAgo := logDaysAgo(7)
 
-	files, err := listGitHubDir(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, "logs")
-	if err != nil {
-		return nil
+	var kept []logFile
+	for _, f := range files {
+		t, err := time.ParseInLocation("2006-01-02", f.Date, logLocation)
+		// 如果该日志的日期早于7天前，则删除
+		if err != nil || !t.Before(sevenDaysAgo) {
+			kept = append(kept, f)
+			continue
+		}
+		path := repoPath("logs", f.Name)
+		delErr := deleteGitHubFile(
+			ctx,
+			cfg.GitHubToken,
+			cfg.GitHubName,
+			cfg.GitHubRepo,
+			path,
+			f.SHA,
+			cfg.commitSignature(),
+		)
+		if delErr != nil {
+			fmt.Printf("删除旧日志 %s 失败: %v\n", f.Name, delErr)
+			kept = append(kept, f)
+		} else {
+			fmt.Printf("已删除旧日志 %s\n", f.Name)
+		}
 	}
+	return kept
+}
 
-	sevenDaysAgo := time.Now().AddDate(0, 0, -7)
+// summaryData 运行摘要模板可使用的数据
+type summaryData struct {
+	RunID        string              // 本次运行的唯一标识
+	Version      string              // 程序版本（版本号、提交与构建时间）
+	Total        int                 // 订阅总数
+	SuccessCount int                 // 成功抓取的订阅数
+	Duration     time.Duration       // 本次运行耗时
+	Problems     map[string][]string // 原始问题记录，键如 parseFails、feedEmpties
+	Sections     []summarySection    // 非空的问题分类，按固定顺序排列
+	NewArticles  []Article           // 与上次数据相比新增的文章
+	Retries      retryStats          // 重试与抓取方式统计
+	Bandwidth    bandwidthStats      // 各域名下载的数据量
+}
 
-	for _, f := range files {
-		if f.Type != "file" {
+// retryStats 成功抓取的订阅中，重试与修复模式的使用情况
+type retryStats struct {
+	FirstTry int      // 首次尝试即成功的订阅数
+	Retried  int      // 经过重试才成功的订阅数
+	FixMode  int      // 其中使用修复模式（忽略证书校验、自定义UA）成功的订阅数
+	FixHosts []string // 使用修复模式成功的主机名，便于确认哪些站点依赖 InsecureSkipVerify
+}
+
+// collectRetryStats 统计成功抓取的订阅的尝试次数与抓取方式
+func collectRetryStats(results []feedResult) retryStats {
+	var st retryStats
+	for _, r := range results {
+		if r.Err != nil || r.Attempts == 0 {
 			continue
 		}
-		matched, _ := regexp.MatchString(`^\d{4}-\d{2}-\d{2}\.log$`, f.Name)
-		if !matched {
+		if r.Attempts == 1 {
+			st.FirstTry++
 			continue
 		}
-		dateStr := strings.TrimSuffix(f.Name, ".log")
-		t, err := time.Parse("2006-01-02", dateStr)
-		if err != nil {
-			continue
+		st.Retried++
+		if r.Strategy == "fix" {
+			st.FixMode++
+			st.FixHosts = append(st.FixHosts, trf("%s (尝试 %d 次)", urlnorm.Host(r.FeedLink), r.Attempts))
 		}
+	}
+	sort.Strings(st.FixHosts)
+	return st
+}
 
-		// 如果该日志的日期早于7天前，则删除
-		if t.Before(sevenDaysAgo) {
-			path := filepath.Join("logs", f.Name)
-			delErr := deleteGitHubFile(
-				ctx,
-				cfg.GitHubToken,
-				cfg.GitHubName,
-				cfg.GitHubRepo,
-				path,
-				f.SHA,
-				committerName,
-				committerEmail,
-			)
-			if delErr != nil {
-				fmt.Printf("删除旧日志 %s 失败: %v\n", f.Name, delErr)
-			} else {
-				fmt.Printf("已删除旧日志 %s\n", f.Name)
-			}
+// summarySection 运行摘要中的一个问题分类
+type summarySection struct {
+	Key   string   // problems 中的键
+	Title string   // 已按当前语言格式化的标题（含数量）
+	Items []string // 问题记录
+}
+
+// summarySectionTitles 问题分类在摘要中的顺序及标题格式
+var summarySectionTitles = []struct{ key, format string }{
+	{"parseFails", "✘ 有 %d 条订阅解析失败:\n"},
+	{"authWalled", "✘ 有 %d 条订阅需要登录或付费才能访问:\n"},
+	{"htmlPages", "✘ 有 %d 条RSS地址返回的是网页而不是订阅:\n"},
+	{"feedEmpties", "✘ 有 %d 条订阅为空:\n"},
+	{"feedShort", "✘ 有 %d 条订阅文章数过少, 疑似被截断:\n"},
+	{"throttled", "✘ 有 %d 条订阅被服务器限流:\n"},
+	{"timeouts", "✘ 有 %d 条订阅请求超时:\n"},
+	{"noAvatar", "✘ 有 %d 条订阅头像字段为空, 已使用默认头像:\n"},
+	{"brokenAvatar", "✘ 有 %d 条订阅头像无法访问, 已使用默认头像:\n"},
+	{"badFields", "✘ 有 %d 条订阅的文章标题或链接异常, 已截断或替换:\n"},
+	{"insecureTLS", "✘ 有 %d 条订阅跳过了证书校验 (INSECURE_TLS_DOMAINS):\n"},
+	{"certExpiring", "✘ 有 %d 条订阅的 HTTPS 证书即将到期:\n"},
+	{"deadLinks", "✘ 有 %d 篇文章链接已失效:\n"},
+	{"lostBlogs", "✘ 有 %d 个失联博客:\n"},
+	{"dataShrink", "✘ 文章数比上次骤减:\n"},
+	{"dataSize", "✘ 有 %d 条 data.json 体积告警:\n"},
+	{"zombieFeeds", "✘ 有 %d 条订阅疑似域名停放或被替换, 已暂停发布:\n"},
+	{"quarantined", "✘ 有 %d 条订阅连续抓取失败, 已隔离 (QUARANTINE_AFTER):\n"},
+}
+
+// summaryBandwidthTop 运行摘要中列出的流量最多的域名数（BANDWIDTH_TOP），<= 0 表示不列出
+var summaryBandwidthTop = 5
+
+// defaultSummaryTemplate 默认的运行摘要模板
+const defaultSummaryTemplate = `{{tr "本次订阅抓取结果统计:\n"}}{{trf "共 %d 条RSS, 成功抓取 %d 条.\n" .Total .SuccessCount}}{{trf "运行ID: %s\n" .RunID}}{{trf "版本: %s\n" .Version}}` +
+	`{{with .Retries}}{{if .Retried}}{{trf "其中 %d 条经过重试才成功, %d 条使用了修复模式(忽略证书校验等):\n" .Retried .FixMode}}` +
+	`{{range .FixHosts}}  - {{.}}
+{{end}}{{end}}{{end}}` +
+	`{{with .Bandwidth}}{{if .Top}}{{trf "本次抓取共下载 %s, 流量最多的域名:\n" (bytes .Total)}}` +
+	`{{range .Top}}  - {{.Host}}: {{bytes .Bytes}}{{if gt .Feeds 1}} {{trf "(%d 条订阅)" .Feeds}}{{end}}
+{{end}}{{end}}{{end}}` +
+	`{{range .Sections}}{{.Title}}{{range .Items}}  - {{.}}
+{{end}}{{else}}{{tr "没有任何警告或错误, 一切正常\n"}}{{end}}`
+
+// newSummaryData 根据抓取结果整理运行摘要数据
+func newSummaryData(successCount, total int, results []feedResult, problems map[string][]string, duration time.Duration, newArticles []Article) summaryData {
+	data := summaryData{
+		RunID:        runID,
+		Version:      versionString(),
+		Total:        total,
+		SuccessCount: successCount,
+		Duration:     duration,
+		Problems:     problems,
+		NewArticles:  newArticles,
+		Retries:      collectRetryStats(results),
+		Bandwidth:    collectBandwidth(results, summaryBandwidthTop),
+	}
+	for _, s := range summarySectionTitles {
+		if items := problems[s.key]; len(items) > 0 {
+			data.Sections = append(data.Sections, summarySection{Key: s.key, Title: trf(s.format, len(items)), Items: items})
 		}
 	}
-	return nil
+	return data
 }
 
-// summarizeResults 根据抓取成功数、总数和问题信息, 生成日志字符串
+// summarizeResults 根据运行摘要数据和模板, 生成日志字符串
 //
 // Description:
 //
 //	将本次抓取的结果进行简单的统计说明，包含解析失败数量、空RSS数量、
-//	头像缺失或不可用的数量等，并以字符串形式返回，便于写日志
+//	头像缺失或不可用的数量等，并以字符串形式返回，便于写日志和发送通知
+//	tmplPath 为自定义 text/template 模板文件路径，为空时使用默认模板；
+//	模板可使用 summaryData 的所有字段，以及 tr/trf 文案函数和 duration、bytes 格式化函数；
+//	自定义模板读取、解析或执行失败时回退到默认模板
 //
 // Parameters:
-//   - successCount : 成功抓取的数量
-//   - total        : 总RSS链接数量
-//   - problems     : 各种问题的集合（parseFails, feedEmpties, noAvatar, brokenAvatar）
+//   - tmplPath : 自定义模板文件路径，可为空
+//   - data     : 运行摘要数据
 //
 // Returns:
 //   - string: 整理好的日志数据
-func summarizeResults(successCount, total int, problems map[string][]string) string {
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("本次订阅抓取结果统计:\n"))
-	sb.WriteString(fmt.Sprintf("共 %d 条RSS, 成功抓取 %d 条.\n", total, successCount))
-
-	parseFails := problems["parseFails"]
-	if len(parseFails) > 0 {
-		sb.WriteString(fmt.Sprintf("✘ 有 %d 条订阅解析失败:\n", len(parseFails)))
-		for _, l := range parseFails {
-			sb.WriteString("  - " + l + "\n")
+func summarizeResults(tmplPath string, data summaryData) string {
+	if tmplPath != "" {
+		out, err := renderSummary(tmplPath, data)
+		if err == nil {
+			return out
 		}
+		fmt.Printf("[WARN] 运行摘要模板 %s 不可用, 使用默认模板: %v\n", tmplPath, err)
 	}
+	out, _ := executeSummaryTemplate(defaultSummaryTemplate, data)
+	return out
+}
 
-	feedEmpties := problems["feedEmpties"]
-	if len(feedEmpties) > 0 {
-		sb.WriteString(fmt.Sprintf("✘ 有 %d 条订阅为空:\n", len(feedEmpties)))
-		for _, l := range feedEmpties {
-			sb.WriteString("  - " + l + "\n")
-		}
+// renderSummary 读取模板文件并生成运行摘要
+func renderSummary(tmplPath string, data summaryData) (string, error) {
+	raw, err := os.ReadFile(tmplPath)
+	if err != nil {
+		return "", err
 	}
+	return executeSummaryTemplate(string(raw), data)
+}
 
-	noAvatarList := problems["noAvatar"]
-	if len(noAvatarList) > 0 {
-		sb.WriteString(fmt.Sprintf("✘ 有 %d 条订阅头像字段为空, 已使用默认头像:\n", len(noAvatarList)))
-		for _, l := range noAvatarList {
-			sb.WriteString("  - " + l + "\n")
-		}
-	}
+// templateFuncs 运行摘要与通知模板可使用的函数：tr/trf 文案函数，duration 与 bytes 格式化函数
+var templateFuncs = template.FuncMap{
+	"tr":    tr,
+	"trf":   trf,
+	"bytes": formatBytes,
+	"duration": func(d time.Duration) string {
+		return d.Round(time.Second).String()
+	},
+}
 
-	brokenAvatarList := problems["brokenAvatar"]
-	if len(brokenAvatarList) > 0 {
-		sb.WriteString(fmt.Sprintf("✘ 有 %d 条订阅头像无法访问, 已使用默认头像:\n", len(brokenAvatarList)))
-		for _, l := range brokenAvatarList {
-			sb.WriteString("  - " + l + "\n")
-		}
+// executeSummaryTemplate 解析并执行运行摘要模板
+func executeSummaryTemplate(text string, data summaryData) (string, error) {
+	tmpl, err := template.New("summary").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
 	}
-
-	if len(parseFails) == 0 && len(feedEmpties) == 0 && len(noAvatarList) == 0 && len(brokenAvatarList) == 0 {
-		sb.WriteString("没有任何警告或错误, 一切正常\n")
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
 	}
-	return sb.String()
+	return sb.String(), nil
 }
 
 // getGitHubFileContent 获取指定文件的完整内容和SHA
@@ -197,7 +361,7 @@ func summarizeResults(successCount, total int, problems map[string][]string) str
 //	并同时获取其 SHA 值用于后续更新或删除操作
 //	如果文件不存在（404），则返回空内容、空SHA
 func getGitHubFileContent(ctx context.Context, token, owner, repo, path string) (string, string, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", owner, repo, path)
+	apiURL := contentsAPIURL(owner, repo, path)
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return "", "", err
@@ -222,13 +386,24 @@ func getGitHubFileContent(ctx context.Context, token, owner, repo, path string)
 	}
 
 	var response struct {
-		SHA     string `json:"sha"`
-		Content string `json:"content"`
+		SHA      string `json:"sha"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+		Size     int    `json:"size"`
 	}
 	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return "", "", err
 	}
 
+	// 超过 1MB 的文件不内联内容（encoding 为 none），需按 raw 格式重新读取，否则会被误当作空文件
+	if response.Encoding == "none" || (response.Content == "" && response.Size > 0) {
+		raw, err := getGitHubRawFile(ctx, token, owner, repo, path)
+		if err != nil {
+			return "", "", err
+		}
+		return string(raw), response.SHA, nil
+	}
+
 	decoded, err := decodeBase64(response.Content)
 	if err != nil {
 		return "", "", err
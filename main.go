@@ -7,15 +7,21 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"sort"
+	"os"
+	"slices"
+	"strings"
 	"time"
 )
 
 // articleToKey generates a unique, comparable string key for an Article.
-// This key includes BlogName, Title, and Link. Published time is excluded as per requirements.
+// This key includes BlogName, Title, Link, Group, the pinned/dead/updated flags and the enrichment fields (Summary, Topic,
+// TranslatedTitle), so enriching existing articles is saved. Published time is excluded as per requirements,
+// as are the volatile UpdatedAt value and the top-level updated timestamp, so timezone or clock changes alone never count as a change.
 func articleToKey(a Article) string {
-	return fmt.Sprintf("Blog:%s|Title:%s|Link:%s", a.BlogName, a.Title, a.Link)
+	return fmt.Sprintf("Blog:%s|Title:%s|Link:%s|Group:%s|Pinned:%t|Dead:%t|Updated:%t|Retired:%t|Archived:%t|Summary:%s|Topic:%s|Translated:%s",
+		a.BlogName, a.Title, a.Link, a.Group, a.Pinned, a.Dead, a.IsUpdated, a.Retired, a.Archived, a.Summary, a.Topic, a.TranslatedTitle)
 }
 
 // areArticlesIdentical checks if two slices of Article contain the same articles,
@@ -48,32 +54,14 @@ func areArticlesIdentical(articles1, articles2 []Article) bool {
 }
 
 // getExistingData fetches and parses the existing data.json from GitHub or COS.
-// Returns an empty slice if the file doesn't exist or cannot be parsed.
-func getExistingData(ctx context.Context, cfg *Config) ([]Article, error) {
-	var rawData []byte
-	var err error
-
-	switch cfg.SaveTarget {
-	case "GITHUB":
-		content, _, getErr := getGitHubFileContent(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, cfg.DataURL)
-		if getErr != nil {
-			return nil, wrapErrorf(getErr, "从 GitHub 获取旧 data.json 失败")
-		}
-		if content == "" { // File doesn't exist or is empty
-			return []Article{}, nil
-		}
-		rawData = []byte(content)
-	case "COS":
-		cosData, getErr := getCosFileContent(ctx, cfg.DataURL)
-		if getErr != nil {
-			return nil, wrapErrorf(getErr, "从 COS 获取旧 data.json 失败")
-		}
-		if cosData == nil { // File doesn't exist or is empty
-			return []Article{}, nil
-		}
-		rawData = cosData
-	default:
-		return nil, fmt.Errorf("SAVE_TARGET 值无效: %s (只能是 'GITHUB' 或 'COS')", cfg.SaveTarget)
+// Returns an empty slice if the file doesn't exist or cannot be parsed, along with the raw file size in bytes.
+func getExistingData(ctx context.Context, cfg *Config) ([]Article, int, error) {
+	rawData, err := readStoredFile(ctx, cfg, cfg.DataURL)
+	if err != nil {
+		return nil, 0, wrapErrorf(err, "获取旧 data.json 失败")
+	}
+	if len(rawData) == 0 { // File doesn't exist or is empty
+		return []Article{}, 0, nil
 	}
 
 	var existingAllData AllData
@@ -81,71 +69,364 @@ func getExistingData(ctx context.Context, cfg *Config) ([]Article, error) {
 		// If unmarshalling fails, it might be an old format or corrupted file.
 		// Treat as no existing valid data.
 		fmt.Printf("[WARN] 解析旧 data.json 失败: %v. 将视作无有效旧数据.\n", err)
-		return []Article{}, nil
+		return []Article{}, len(rawData), nil
 	}
-	return existingAllData.Items, nil
+	return existingAllData.Items, len(rawData), nil
 }
 
 // main 程序入口
 //
 // Description:
+//
+//	带子命令时执行对应的子命令，否则解析 --only 等参数后执行抓取流程；
+//	以 serverless 构建标签编译时改为运行云函数的运行时循环
+func main() {
+	ctx := context.Background()
+
+	if serverlessMain != nil {
+		serverlessMain()
+		return
+	}
+
+	// 子命令（如 forever）不执行抓取流程
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		cmd, ok := subcommands[os.Args[1]]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "未知子命令: %s\n", os.Args[1])
+			printSubcommands()
+			os.Exit(2)
+		}
+		os.Exit(cmd.Run(ctx, os.Args[2:]))
+	}
+	runOpts, err := parseRunFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		os.Exit(2)
+	}
+	if runOpts.Version {
+		fmt.Printf("lhasaRSS %s\n", versionString())
+		return
+	}
+
+	// 运行失败时以非零状态码退出；runPipeline 中的 defer（如保存缓存）已在返回前执行
+	if exitCode := runProfiles(ctx, runOpts); exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
+
+// serverlessMain 以 serverless 构建标签编译时设置的入口，nil 表示普通的命令行程序
+var serverlessMain func()
+
+// runPipeline 执行一次完整的抓取流程
+//
+// Description:
 //  1. 加载并校验环境变量(SecretID, SecretKey, RSS, DATA, RSS_SOURCE等)
 //  2. 拉取RSS列表并并发抓取
 //  3. 将结果整合为 data.json 并根据 SAVE_TARGET 上传到GitHub或COS
 //  4. 写执行日志到GitHub
-func main() {
-	ctx := context.Background()
+//
+// Returns:
+//   - int : 进程退出码，配置无效时为 2，抓取全部失败、成功率过低、上传被阻止或任一步骤出错时为 1
+func runPipeline(ctx context.Context, runOpts runOptions) (exitCode int) {
+	startedAt := time.Now()
 
 	// 加载配置
 	cfg := LoadConfig()
+	setLanguage(cfg.Lang)
+	if err := setLogTimezone(cfg.LogTimezone); err != nil {
+		fmt.Printf("[WARN] %v, 使用进程时区\n", err)
+	}
+	setLogBufferLimit(cfg.LogBufferMaxBytes)
+	summaryBandwidthTop = cfg.BandwidthTop
+	fmt.Printf("[INFO] 运行ID: %s\n", runID)
+	appendLog("[INFO] " + trf("版本: %s", versionString()))
+	banner := configBanner()
+	fmt.Print(banner)
+	appendLog("[INFO] " + banner)
+
+	// 运行期间的日志先写入内存缓冲，结束时一次性写入 GitHub（在退出前执行）
+	defer func() {
+		if err := flushLog(ctx); err != nil {
+			fmt.Printf("[WARN] 写入日志失败: %v\n", err)
+		}
+	}()
+	// 被恢复的 panic 在写入日志前汇总发送通知；主流程中的 panic 同样被恢复并记入日志，本次运行以失败结束
+	defer func() {
+		if err := notifyCrashReports(ctx, cfg); err != nil {
+			fmt.Printf("[WARN] 发送崩溃报告失败: %v\n", err)
+		}
+	}()
+	// 运行结束（含 panic 被恢复后）向监控服务上报结果与耗时，定时任务停止运行时由监控服务告警
+	defer func() {
+		if err := heartbeatFinish(ctx, cfg, exitCode, time.Since(startedAt)); err != nil {
+			fmt.Printf("[WARN] %v\n", err)
+		}
+	}()
+	defer recoverPanic(tr("主流程"), func(error) { exitCode = 1 })
+	if err := heartbeatStart(ctx, cfg); err != nil {
+		fmt.Printf("[WARN] %v\n", err)
+	}
 	// 校验配置（只需在此处集中校验一次）
 	if err := cfg.Validate(); err != nil {
 		// 这里可以将错误写入日志再退出
-		_ = appendLog(ctx, "[ERROR] "+err.Error())
+		appendLog("[ERROR] " + err.Error())
+		exitCode = 2
+		return
+	}
+
+	// 按需开启 HTTP 录制或回放
+	if err := setupHTTPReplay(cfg.HTTPRecordDir, cfg.HTTPReplayDir); err != nil {
+		fmt.Printf("[ERROR] %v\n", err)
+		exitCode = 1
 		return
 	}
+	if err := setupDevCache(runOpts.CacheDir); err != nil {
+		fmt.Printf("[ERROR] %v\n", err)
+		exitCode = 1
+		return
+	}
+	if err := setupFeedDump(runOpts.DumpDir); err != nil {
+		fmt.Printf("[ERROR] %v\n", err)
+		exitCode = 1
+		return
+	}
+	feedAcceptEncoding = cfg.FeedAcceptEncoding
+
+	// 启动预检，尽早暴露凭证、权限或地址配置错误
+	if cfg.Preflight {
+		preflightErrs := runPreflight(ctx, cfg)
+		if err := preflightErrs.Warning; err != nil {
+			fmt.Printf("[WARN] 预检警告（不影响本次发布）:\n%v\n", err)
+			appendLog("[WARN] " + tr("预检警告: ") + err.Error())
+		}
+		if err := preflightErrs.Fatal; err != nil {
+			fmt.Printf("[ERROR] 预检失败:\n%v\n", err)
+			appendLog("[ERROR] " + tr("预检失败: ") + err.Error())
+			exitCode = 1
+			return
+		}
+	}
 
-	// 拉取RSS列表
-	rssLinks, err := fetchRSSLinks(cfg)
+	// 加载抓取缓存，运行结束时写回
+	store, err := newCacheStore(cfg)
 	if err != nil {
-		_ = appendLog(ctx, fmt.Sprintf("[ERROR] 拉取RSS链接失败: %v", err))
+		appendLog(fmt.Sprintf("[WARN] %v", err))
+	}
+	cache := loadFetchCache(ctx, store)
+	cache.pruneNotified(time.Duration(cfg.NotifyDedupWindow)*time.Hour, time.Now())
+	defer func() {
+		if err := cache.save(ctx); err != nil {
+			fmt.Printf("[WARN] 保存抓取缓存失败: %v\n", err)
+		}
+	}()
+
+	// 并发加载RSS列表、头像映射、名称映射与固定数据，各自独立超时
+	var rssLinks []feedEntry
+	var foreverBlog *foreverBlogData
+	var foreverblogMembers []foreverblogMember
+	avatarMapper := NewAvatarMapper(cfg, cache)
+	nameMapper := NewNameMapper(cfg, cache)
+	topicTagger := NewTopicTagger(cfg, cache)
+	loadErrs := runLoadTasks(ctx, []loadTask{
+		{
+			Name:     "RSS列表",
+			Timeout:  30 * time.Second,
+			Required: true,
+			Load: func(ctx context.Context) error {
+				links, err := fetchRSSLinks(ctx, cfg, cache)
+				rssLinks = links
+				return err
+			},
+		},
+		{
+			Name:    "头像映射",
+			Timeout: 30 * time.Second,
+			Load:    avatarMapper.LoadAvatarMap,
+		},
+		{
+			Name:    "名称映射",
+			Timeout: 30 * time.Second,
+			Load:    nameMapper.LoadNameMap,
+		},
+		{
+			Name:    "主题规则",
+			Timeout: 30 * time.Second,
+			Load:    topicTagger.LoadTopicRules,
+		},
+		{
+			Name:    "固定数据",
+			Timeout: 30 * time.Second,
+			Load: func(ctx context.Context) error {
+				if cfg.ForeverBlogURL == "" {
+					return nil
+				}
+				data, err := loadForeverBlog(ctx, cfg, cache)
+				foreverBlog = data
+				return err
+			},
+		},
+		{
+			Name:    "十年之约成员列表",
+			Timeout: 30 * time.Second,
+			Load: func(ctx context.Context) error {
+				if cfg.ForeverblogMembersURL == "" {
+					return nil
+				}
+				members, err := fetchForeverblogMembers(ctx, cfg.ForeverblogMembersURL, cache)
+				foreverblogMembers = members
+				return err
+			},
+		},
+	})
+	if loadErrs.Fatal != nil {
+		appendLog("[ERROR] " + trf("拉取RSS链接失败: %v", loadErrs.Fatal))
+		exitCode = 1
 		return
 	}
+	if loadErrs.Warning != nil {
+		// 映射文件加载失败时继续执行，不阻止程序运行
+		appendLog(fmt.Sprintf("[WARN] %v", loadErrs.Warning))
+	}
+	// 按白名单合并十年之约成员
+	if len(foreverblogMembers) > 0 {
+		var added int
+		rssLinks, added = mergeForeverblogMembers(rssLinks, foreverblogMembers, cfg.ForeverblogAllowlist)
+		fmt.Printf("[INFO] 十年之约成员 %d 个, 白名单内新增 %d 个RSS\n", len(foreverblogMembers), added)
+	}
+	if runOpts.subset() {
+		rssLinks = runOpts.filter(rssLinks)
+		fmt.Printf("[INFO] 仅抓取 %d 条订阅, 本次不发布任何数据\n", len(rssLinks))
+		if len(rssLinks) == 0 {
+			fmt.Println("[WARN] 没有匹配 --only 的订阅")
+			return
+		}
+	}
+	// 已归档的订阅不再抓取，稍后从旧数据中保留其文章
+	rssLinks, archivedLinks := splitArchivedFeeds(rssLinks)
+	if len(archivedLinks) > 0 {
+		fmt.Printf("[INFO] 跳过 %d 个已归档的订阅\n", len(archivedLinks))
+	}
 	if len(rssLinks) == 0 {
-		_ = appendLog(ctx, "[WARN] RSS列表为空, 无需抓取")
+		appendLog("[WARN] " + tr("RSS列表为空, 无需抓取"))
 		return
 	}
 
-	// 创建并加载头像映射器
-	avatarMapper := NewAvatarMapper(cfg)
-	if err := avatarMapper.LoadAvatarMap(); err != nil {
-		_ = appendLog(ctx, fmt.Sprintf("[WARN] 加载头像映射失败: %v", err))
-		// 继续执行，不阻止程序运行
+	// RSS 列表中的全部订阅（含已归档的），在 pre-fetch 钩子之前记录：
+	// 钩子只是让某个订阅本次不抓取时，不应把它当作已从列表中删除（清理文章与缓存记录）
+	listed := listedFeeds(slices.Concat(rssLinks, archivedLinks))
+
+	// pre-fetch 钩子：可增删待抓取的RSS
+	if cfg.HookPreFetch != "" {
+		payload := prefetchPayload{Feeds: rssLinks}
+		if err := runJSONHook(ctx, "pre-fetch", cfg.HookPreFetch, &payload); err != nil {
+			appendLog(fmt.Sprintf("[WARN] %v", err))
+		} else {
+			rssLinks = payload.Feeds
+			// 钩子新增的订阅同样视为列表中的订阅
+			for _, e := range rssLinks {
+				listed[e.URL] = true
+			}
+		}
+	}
+
+	// 隔离中的订阅未到试探时间时本次不抓取（--only/--limit 调试时照常抓取）
+	fetchLinks, quarantined := rssLinks, []feedEntry(nil)
+	if cfg.QuarantineAfter > 0 && !runOpts.subset() {
+		fetchLinks, quarantined = cache.splitQuarantined(rssLinks, time.Duration(cfg.QuarantineRetryHours)*time.Hour, time.Now())
+		if len(quarantined) > 0 {
+			fmt.Printf("[INFO] 跳过 %d 个隔离中的订阅\n", len(quarantined))
+		}
 	}
 
 	// 并发抓取所有RSS，获取结果和问题统计
-	results, problems := fetchAllFeeds(ctx, rssLinks, cfg.DefaultAvatar, avatarMapper)
+	results, problems := fetchAllFeeds(ctx, fetchLinks, cfg, avatarMapper, nameMapper, cache)
 
-	// 提取成功抓取的项，并做按发布时间的倒序排序
-	var itemsWithTime []struct {
-		article Article
-		t       time.Time
+	// 只抓取部分订阅时（--only/--limit）仅打印结果，不发送通知、不更新任何数据
+	if runOpts.subset() {
+		printSubsetResults(results)
+		return
+	}
+
+	// 按抓取结果更新隔离列表，跳过的订阅按抓取失败处理
+	if cfg.QuarantineAfter > 0 {
+		results = append(results, quarantinedResults(quarantined)...)
+		cache.updateQuarantine(results, cfg.QuarantineAfter, problems, time.Now())
+	}
+
+	// 识别域名停放、被换成其他站点的僵尸订阅，不发布其内容
+	if cfg.ZombieCheck {
+		detectZombieFeeds(results, cache, cfg.ZombieAck, problems)
 	}
+
+	// 检查友链 HTTPS 证书是否即将到期，并发送通知提醒（同一订阅在 NOTIFY_DEDUP_WINDOW 内只提醒一次）
+	if expiring := checkCertExpiry(results, cfg.CertExpiryWarnDays, problems); len(expiring) > 0 {
+		if err := sendAlertItems(ctx, cfg, cache, "友链证书即将到期", "以下订阅的 HTTPS 证书即将到期:", expiring); err != nil {
+			fmt.Printf("[WARN] %v\n", err)
+		}
+	}
+
+	// 待上传的输出文件，最后统一并发上传
+	var outputs []outputFile
+
+	// 生成 blogs.json，开启 BLOG_LIVENESS 时独立于RSS抓取结果检查博客主页存活状态
+	if blogsJSONEnabled(cfg, results) {
+		if blogs, err := updateBlogsJSON(ctx, cfg, results, problems); err != nil {
+			appendLog("[WARN] " + trf("生成 blogs.json 失败: %v", err))
+		} else {
+			outputs = append(outputs, blogs)
+		}
+	}
+
+	// 提取成功抓取的项，并做按发布时间的倒序排序
+	var itemsWithTime []timedArticle
 	var successCount int
 	for _, r := range results {
-		if r.Err == nil {
+		if r.Err == nil && r.Article != nil {
 			successCount++
-			itemsWithTime = append(itemsWithTime, struct {
-				article Article
-				t       time.Time
-			}{*r.Article, r.ParsedTime})
+			itemsWithTime = append(itemsWithTime, timedArticle{*r.Article, r.ParsedTime})
 		}
 	}
 
-	// 按发布时间倒序排序
-	sort.Slice(itemsWithTime, func(i, j int) bool {
-		return itemsWithTime[i].t.After(itemsWithTime[j].t)
-	})
+	// 所有订阅均抓取失败（如运行环境断网）或成功率低于 PUBLISH_MIN_SUCCESS_RATIO 时，
+	// 保留上次的 data.json，不以残缺的列表覆盖，并将本次运行标记为失败
+	ratio := float64(successCount) / float64(len(rssLinks))
+	if successCount == 0 || ratio < cfg.PublishMinSuccessRatio {
+		var key, title, msg string
+		if successCount == 0 {
+			key = "fetch:all-failed"
+			title = tr("抓取全部失败")
+			msg = trf("所有 %d 条订阅均抓取失败, 保留现有 data.json 不做更新", len(rssLinks))
+		} else {
+			key = "fetch:low-success"
+			title = tr("抓取成功率过低")
+			msg = trf("成功抓取 %d/%d 条订阅 (%.0f%%), 低于发布阈值 %.0f%%, 保留现有 data.json 不做更新",
+				successCount, len(rssLinks), ratio*100, cfg.PublishMinSuccessRatio*100)
+		}
+		fmt.Println("[ERROR] " + msg)
+		appendLog("[ERROR] " + msg + "\n" + summarizeResults(cfg.SummaryTemplate, newSummaryData(successCount, len(rssLinks), results, problems, time.Since(startedAt), nil)))
+		if err := sendAlert(ctx, cfg, cache, key, "lhasaRSS "+title, msg); err != nil {
+			fmt.Printf("[WARN] %v\n", err)
+		}
+		exitCode = 1
+		return
+	}
+
+	// 新加入的订阅按配置回填历史文章
+	newFeeds := cache.markKnownFeeds(succeededFeeds(results))
+	itemsWithTime = append(itemsWithTime, backfillHistory(results, rssLinks, newFeeds)...)
+
+	// 可选：将文章链接替换为 rel=canonical 声明的规范地址，并合并重复文章
+	if cfg.CanonicalLinks {
+		itemsWithTime = resolveCanonicalLinks(ctx, itemsWithTime, time.Duration(cfg.CanonicalIntervalHours)*time.Hour, cache, newRobotsChecker(cfg.RespectRobots))
+	}
+
+	// 合并固定数据
+	if foreverBlog != nil {
+		itemsWithTime = mergeForeverItems(itemsWithTime, foreverBlog.Items, cfg.defaultAvatarFor)
+	}
+
+	// 按置顶、权重和发布时间排序
+	sortTimedArticles(itemsWithTime)
 
 	// 整理所有文章到一个切片
 	var newArticles []Article
@@ -153,57 +434,254 @@ func main() {
 		newArticles = append(newArticles, v.article)
 	}
 
+	// post-parse 钩子：可过滤或改写文章
+	if articles, err := runPostParseHook(ctx, cfg.HookPostParse, newArticles); err != nil {
+		appendLog(fmt.Sprintf("[WARN] %v", err))
+	} else {
+		newArticles = articles
+	}
+
+	// 定期检测文章链接是否失效，按配置标记或剔除
+	if cfg.LinkRotMode == "flag" || cfg.LinkRotMode == "drop" {
+		var dead []string
+		newArticles, dead = checkArticleLinks(ctx, newArticles, cfg.LinkRotMode, time.Duration(cfg.LinkRotIntervalHours)*time.Hour, cache)
+		problems["deadLinks"] = dead
+	}
+
 	// 获取现有的数据进行比较
-	existingArticles, err := getExistingData(ctx, cfg)
-	if err != nil {
-		// 记录错误，但仍尝试继续，因为获取旧数据失败不应阻止新数据的保存
-		_ = appendLog(ctx, fmt.Sprintf("[ERROR] 获取旧数据用于比较时失败: %v", err))
+	existingArticles, prevDataSize, err := getExistingData(ctx, cfg)
+	// 记录错误，但仍尝试继续，因为获取旧数据失败不应阻止新数据的保存；
+	// 无读取权限或存储暂时不可用时只跳过比较与增量合并，不视为错误
+	switch {
+	case errors.Is(err, ErrStorageForbidden):
+		appendLog("[WARN] " + trf("无权读取旧 data.json, 跳过与旧数据的比较: %v", err))
+	case errors.Is(err, ErrStorageTransient):
+		appendLog("[WARN] " + trf("旧 data.json 暂时无法读取, 跳过与旧数据的比较: %v", err))
+	case err != nil:
+		appendLog("[ERROR] " + trf("获取旧数据用于比较时失败: %v", err))
+	}
+
+	// 保留已归档订阅最后的文章
+	newArticles = keepArchivedArticles(newArticles, existingArticles, archivedLinks, cache)
+
+	// 与旧数据对比，标记被悄悄修改过的文章
+	markUpdatedArticles(newArticles, existingArticles)
+
+	// 可选：为新文章生成一句话摘要
+	if cfg.SummaryEnabled {
+		enrichSummaries(ctx, cfg, newArticles, existingArticles, cache)
+	}
+	// 可选：为文章打上主题标签
+	topicTagger.TagArticles(ctx, newArticles, existingArticles)
+	// 可选：翻译非中文标题
+	if translator, err := newTranslator(cfg); err != nil {
+		appendLog(fmt.Sprintf("[WARN] %v", err))
+	} else if translator != nil {
+		translateTitles(ctx, cfg, translator, newArticles, existingArticles, cache)
+	}
+
+	// 增量合并：在上次数据的基础上应用差异，抓取失败的订阅保留旧文章
+	unchanged := err == nil && areArticlesIdentical(newArticles, existingArticles)
+	if cfg.MergeMode == "incremental" && err == nil {
+		var delta dataDelta
+		newArticles, delta = mergeIncremental(existingArticles, newArticles, succeededFeeds(results), listed, cfg.RemovedFeeds)
+		fmt.Printf("[INFO] 增量合并: %s\n", delta)
+		if retired := delta.retiredBlogs(); len(retired) > 0 {
+			msg := tr("以下订阅已从RSS列表中删除, 其文章已清理:")
+			if cfg.RemovedFeeds == "retire" {
+				msg = tr("以下订阅已从RSS列表中删除, 其文章已标记为 retired:")
+			}
+			appendLog(msg + "\n" + strings.Join(retired, "\n"))
+		}
+		unchanged = delta.empty()
+	}
+
+	// 文章数骤减保护：RSS 列表被截断等情况下不覆盖线上数据，确认无误后以 --force 运行
+	shrinkBlocked := false
+	if msg := checkShrinkage(len(existingArticles), len(newArticles), cfg.DataShrinkPercent); msg != "" {
+		problems["dataShrink"] = append(problems["dataShrink"], msg)
+		shrinkBlocked = cfg.DataShrinkMode != "warn" && !runOpts.Force
+	}
+	// 清理缓存中已删除的订阅和已移出 data.json 的文章的记录；文章数骤减（RSS 列表可能被截断）时不清理
+	if !shrinkBlocked {
+		cache.prune(activeProfile, listed, newArticles, time.Now())
+	}
+
+	// 按类型整理的问题报告
+	if cfg.ProblemsJSON {
+		if report, err := problemsOutput(ctx, cfg, len(rssLinks), successCount, problems); err != nil {
+			appendLog(fmt.Sprintf("[WARN] %v", err))
+		} else {
+			outputs = append(outputs, report)
+		}
+	}
+
+	// 每日推荐博客，每天变化一次，与 data.json 是否变化无关
+	if cfg.Spotlight {
+		if spotlight, ok, err := spotlightOutput(cfg, newArticles, rssLinks, results, time.Now()); err != nil {
+			appendLog(fmt.Sprintf("[WARN] %v", err))
+		} else if ok {
+			outputs = append(outputs, spotlight)
+		}
+	}
+
+	// 那年今日，同样每天变化一次
+	if cfg.OnThisDay {
+		if onThisDay, err := onThisDayOutput(cfg, newArticles, results, time.Now()); err != nil {
+			appendLog(fmt.Sprintf("[WARN] %v", err))
+		} else {
+			outputs = append(outputs, onThisDay)
+		}
+	}
+
+	// 友链健康状况 RSS：问题出现或恢复时各生成一条事件
+	if cfg.HealthFeed != "" {
+		cache.recordHealthEvents(problems, time.Now())
+		if feed, err := healthFeedOutput(cfg, cache); err != nil {
+			appendLog(fmt.Sprintf("[WARN] %v", err))
+		} else {
+			outputs = append(outputs, feed)
+		}
 	}
 
-	if err == nil && areArticlesIdentical(newArticles, existingArticles) {
+	// 订阅数、失败数徽章
+	if cfg.Badges {
+		if badges, err := badgeOutputs(cfg, statsBadges(len(rssLinks), successCount)); err != nil {
+			appendLog("[WARN] " + trf("生成徽章失败: %v", err))
+		} else {
+			outputs = append(outputs, badges...)
+		}
+	}
+
+	// 更新仓库内文件中的统计表
+	if cfg.StatsFile != "" {
+		if err := updateStatsMarkdown(ctx, cfg, len(rssLinks), successCount, newArticles); err != nil {
+			appendLog("[WARN] " + trf("更新统计表失败: %v", err))
+		}
+	}
+
+	// 按时间点汇总发送新文章通知：新文章在 data.json 上传成功后才入队（上传失败时下次运行仍是新文章），
+	// 数据无变化时也需检查是否到达发送时间；没有旧数据（首次运行或读取失败）时不入队，避免把所有文章都当作新文章
+	notifyDigest := func(published []Article) {
+		if len(cfg.NotifyDigestTimes) == 0 {
+			return
+		}
+		if len(existingArticles) > 0 {
+			cache.queueDigest(published, time.Now())
+		}
+		if err := flushNotifyDigest(ctx, cfg, cache, time.Now()); err != nil {
+			appendLog("[WARN] " + trf("发送新文章摘要失败: %v", err))
+		}
+	}
+
+	// 新收录的文章加入 Webmention 队列，在 data.json 上传成功后发送（文章已出现在聚合页中）
+	if cfg.WebmentionSource != "" && len(existingArticles) > 0 {
+		cache.queueWebmentions(newArticlesSince(newArticles, existingArticles), time.Now())
+	}
+
+	if unchanged {
 		fmt.Println("抓取到的文章与现有数据相同，无需更新。")
-		_ = appendLog(ctx, "抓取到的文章与现有数据相同，无需更新。")
+		appendLog(tr("抓取到的文章与现有数据相同，无需更新。"))
+		// data.json 无需更新，但 blogs.json、徽章等仍需上传
+		if errs := uploadOutputs(ctx, cfg, outputs); errs.Warning != nil {
+			appendLog(fmt.Sprintf("[WARN] %v", errs.Warning))
+		}
+		notifyDigest(nil)
 		return // 停止执行
 	}
 
 	// 构造输出数据结构，并 JSON 序列化
-	allData := AllData{
-		Items:   newArticles, // 使用 newArticles
-		Updated: time.Now().Format("2006年01月02日 15:04:05"),
-	}
-	jsonBytes, err := json.MarshalIndent(allData, "", "  ")
+	jsonBytes, err := renderData(newArticles, feedGroups(slices.Concat(rssLinks, archivedLinks), newArticles), cfg.OutputUpdated, cfg.OutputIndent)
 	if err != nil {
-		_ = appendLog(ctx, fmt.Sprintf("[ERROR] JSON序列化失败: %v", err))
+		appendLog("[ERROR] " + trf("JSON序列化失败: %v", err))
+		exitCode = 1
 		return
 	}
 
-	// 根据 SAVE_TARGET 判断保存路径
-	switch cfg.SaveTarget {
-	case "GITHUB":
-		if err := uploadToGitHub(
-			ctx,
-			cfg.GitHubToken,
-			cfg.GitHubName,
-			cfg.GitHubRepo,
-			cfg.DataURL,
-			jsonBytes,
-		); err != nil {
-			_ = appendLog(ctx, fmt.Sprintf("[ERROR] 上传 data.json 到 GitHub 失败: %v", err))
-			return
+	// pre-upload 钩子：可改写最终上传的内容
+	if jsonBytes, err = runPreUploadHook(ctx, cfg.HookPreUpload, jsonBytes); err != nil {
+		appendLog(fmt.Sprintf("[WARN] %v", err))
+	}
+
+	if shrinkBlocked {
+		msg := trf("文章数骤减, 已阻止上传 data.json: %s. 确认无误后使用 --force 重新运行, 或设置 DATA_SHRINK_GUARD_MODE=warn",
+			strings.Join(problems["dataShrink"], "; "))
+		appendLog("[ERROR] " + msg)
+		if err := sendAlert(ctx, cfg, cache, "data:shrink-blocked", tr("data.json 上传已阻止"), msg); err != nil {
+			appendLog(fmt.Sprintf("[WARN] %v", err))
 		}
+		// 其他输出文件（problems.json 等）照常上传，便于排查
+		if errs := uploadOutputs(ctx, cfg, outputs); errs.Warning != nil {
+			appendLog(fmt.Sprintf("[WARN] %v", errs.Warning))
+		}
+		return 1
+	}
 
-	case "COS":
-		if err := uploadToCos(ctx, cfg.TencentSecretID, cfg.TencentSecretKey, cfg.DataURL, jsonBytes); err != nil {
-			_ = appendLog(ctx, fmt.Sprintf("[ERROR] 上传 data.json 到 COS 失败: %v", err))
-			return
+	// data.json 过大或比上次增长过快时在摘要中告警
+	checkDataSize(len(jsonBytes), prevDataSize, cfg.DataSizeWarnBytes, cfg.DataGrowthWarnRatio, problems)
+
+	// 覆盖前先在 COS 端备份旧数据，备份失败不阻止上传
+	if cfg.SaveTarget == "COS" && cfg.CosBackup {
+		if err := backupCosObject(ctx, cfg.TencentSecretID, cfg.TencentSecretKey, cfg.DataURL, cfg.CosBackupRetentionDays); err != nil {
+			appendLog("[WARN] " + trf("备份 COS 中的 data.json 失败: %v", err))
 		}
+	}
 
-	default:
-		_ = appendLog(ctx, fmt.Sprintf("[ERROR] SAVE_TARGET 值无效: %s (只能是 'GITHUB' 或 'COS')", cfg.SaveTarget))
+	// data.json 与其他输出文件一起并发上传
+	outputs = append(outputs, outputFile{Target: cfg.DataURL, Data: jsonBytes, CommitMsg: "Update data.json", Required: true})
+	if target := dataSnapshotTarget(cfg, time.Now()); target != "" {
+		outputs = append(outputs, outputFile{Target: target, Data: jsonBytes, CommitMsg: "Add data snapshot", SkipUnchanged: true})
+	}
+	outputs = append(outputs, extraFormatOutputs(cfg, jsonBytes)...)
+	if cfg.Badges {
+		if badges, err := badgeOutputs(cfg, []badgeFile{updatedBadge(time.Now())}); err == nil {
+			outputs = append(outputs, badges...)
+		}
+	}
+	errs := uploadOutputs(ctx, cfg, outputs)
+	if errs.Warning != nil {
+		appendLog(fmt.Sprintf("[WARN] %v", errs.Warning))
+	}
+	if errs.Fatal != nil {
+		appendLog("[ERROR] " + trf("上传 data.json 失败: %v", errs.Fatal))
+		exitCode = 1
 		return
 	}
 
+	// data.json 的提交上记录本次运行的抓取成功率与新文章数
+	statusDesc := commitStatusDescription(successCount, len(rssLinks), len(newArticlesSince(newArticles, existingArticles)))
+	if err := setDataCommitStatus(ctx, cfg, statusDesc); err != nil {
+		appendLog(fmt.Sprintf("[WARN] %v", err))
+	}
+
+	// 上传成功后按需刷新 CDN 缓存，失败仅记录警告
+	if cfg.SaveTarget == "COS" && cfg.CDNPurge {
+		urls := append([]string{cdnURLFor(cfg.DataURL, cfg.CDNDomain)}, cfg.CDNPurgeURLs...)
+		if cfg.CosGzip && !isGzipTarget(cfg.DataURL) {
+			urls = append(urls, cdnURLFor(cfg.DataURL+".gz", cfg.CDNDomain))
+		}
+		if taskID, err := purgeCDNCache(ctx, cfg.TencentSecretID, cfg.TencentSecretKey, urls); err != nil {
+			appendLog("[WARN] " + trf("刷新CDN缓存失败: %v", err))
+		} else {
+			fmt.Printf("[INFO] 已提交CDN刷新任务: %s\n", taskID)
+		}
+	}
+
+	notifyDigest(newArticlesSince(newArticles, existingArticles))
+	if cfg.WebmentionSource != "" {
+		sendWebmentions(ctx, cfg, cache)
+	}
+
 	// 写执行日志
-	logSummary := summarizeResults(successCount, len(rssLinks), problems)
-	_ = appendLog(ctx, logSummary)
+	summary := newSummaryData(successCount, len(rssLinks), results, problems, time.Since(startedAt), newArticlesSince(newArticles, existingArticles))
+	logSummary := summarizeResults(cfg.SummaryTemplate, summary)
+	appendLog(logSummary)
+	if cfg.NotifySummary {
+		report := newRunReport("lhasaRSS "+tr("运行摘要"), logSummary)
+		report.Summary = &summary
+		if err := sendRunReport(ctx, cfg, report); err != nil {
+			fmt.Printf("[WARN] %v\n", err)
+		}
+	}
+	return 0
 }
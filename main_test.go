@@ -0,0 +1,25 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: main_test.go
+// Description: 文章比较（articleToKey / areArticlesIdentical）的测试
+
+package main
+
+import "testing"
+
+func TestAreArticlesIdenticalDetectsEnrichment(t *testing.T) {
+	base := Article{BlogName: "Blog", Title: "Post", Link: "https://example.com/post"}
+	for name, enrich := range map[string]func(*Article){
+		"summary":          func(a *Article) { a.Summary = "一句话摘要" },
+		"topic":            func(a *Article) { a.Topic = "技术" },
+		"translated_title": func(a *Article) { a.TranslatedTitle = "文章" },
+	} {
+		enriched := base
+		enrich(&enriched)
+		if areArticlesIdentical([]Article{base}, []Article{enriched}) {
+			t.Errorf("只补充了 %s 时 areArticlesIdentical = true, want false", name)
+		}
+		if fields := changedArticleFields(base, enriched); len(fields) != 1 || fields[0] != name {
+			t.Errorf("changedArticleFields() = %v, want [%s]", fields, name)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: memory_storage.go
+// Description: 内存存储后端（SAVE_TARGET=MEMORY），无需 GitHub、COS 或 KV 凭证即可完整运行一次流程，便于本地开发与调试
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// memoryStorage 将输出文件保存在内存中的 Storage 实现
+//
+// Description:
+//
+//	写入的内容只在本次运行内有效，读取未写入过的文件时视为不存在（与首次运行相同）；
+//	每次写入都会输出文件名与大小，便于确认本次运行会生成哪些文件
+type memoryStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// sharedMemoryStorage SAVE_TARGET=MEMORY 时所有读写共用的实例，集成测试中替换为 testsupport.Storage 以注入错误
+var sharedMemoryStorage Storage = newMemoryStorage()
+
+// newMemoryStorage 创建一个空的内存存储
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{files: make(map[string][]byte)}
+}
+
+// Read 返回已写入内容的副本，未写入过时返回 nil, nil
+func (s *memoryStorage) Read(ctx context.Context, target string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[target]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Write 保存内容的副本，调用方之后修改 data 不影响已保存的内容
+func (s *memoryStorage) Write(ctx context.Context, target string, data []byte, commitMsg string) error {
+	s.mu.Lock()
+	s.files[target] = append([]byte(nil), data...)
+	s.mu.Unlock()
+	fmt.Printf("[MEMORY] %s (%s)\n", target, formatBytes(len(data)))
+	return nil
+}
+
+func (s *memoryStorage) String() string {
+	return "memory"
+}
@@ -6,6 +6,8 @@ package main
 
 import (
 	"time"
+
+	"github.com/mmcdole/gofeed"
 )
 
 // Article 关键字段
@@ -14,11 +16,37 @@ import (
 //
 //	表示一篇文章及其所属博客的关键信息，比如博客名称、文章标题、发布时间、链接和头像URL
 type Article struct {
-	BlogName  string `json:"blog_name"` // 博客名称
-	Title     string `json:"title"`     // 文章标题
-	Published string `json:"published"` // 文章发布时间 (已格式化，如 "Mar 09, 2025")
-	Link      string `json:"link"`      // 文章链接
-	Avatar    string `json:"avatar"`    // 博客头像
+	BlogName  string `json:"blog_name"`        // 博客名称
+	Title     string `json:"title"`            // 文章标题
+	Published string `json:"published"`        // 文章发布时间 (已格式化，如 "Mar 09, 2025")
+	Link      string `json:"link"`             // 文章链接
+	Avatar    string `json:"avatar"`           // 博客头像
+	Feed      string `json:"feed,omitempty"`   // 文章来源的 RSS 地址（固定数据为空）
+	Dead      bool   `json:"dead,omitempty"`   // 文章链接已失效（404/410）
+	Group     string `json:"group,omitempty"`  // 博客分组（RSS列表中的 group 配置）
+	Pinned    bool   `json:"pinned,omitempty"` // 置顶博客的文章
+
+	Retired  bool `json:"retired,omitempty"`  // 所属订阅已从RSS列表中删除（REMOVED_FEEDS=retire 时保留的旧文章）
+	Archived bool `json:"archived,omitempty"` // 所属订阅已归档（RSS列表中 archived=true），不再抓取，保留最后的文章
+
+	UpdatedAt string `json:"updated_at,omitempty"` // 文章更新时间 (RFC3339)，RSS 未提供时为空
+	IsUpdated bool   `json:"is_updated,omitempty"` // 与上次相比，标题或更新时间发生过变化
+	PrevTitle string `json:"prev_title,omitempty"` // 标题被修改前的旧标题
+	Summary   string `json:"summary,omitempty"`    // 大模型生成的一句话摘要
+	Topic     string `json:"topic,omitempty"`      // 文章主题标签（技术/生活/摄影等）
+
+	TranslatedTitle string `json:"translated_title,omitempty"` // 非中文标题的译文
+
+	Thumbnail string `json:"thumbnail,omitempty"` // 文章缩略图（media:thumbnail、itunes:image 等，FEED_EXTENSIONS 开启时）
+	Author    string `json:"author,omitempty"`    // 文章作者（dc:creator 或 author，FEED_EXTENSIONS 开启时）
+
+	Source     string `json:"source,omitempty"`      // 产生该文章的数据来源：rss、atom、jsonfeed（抓取的订阅格式）或 manual（固定数据）
+	FetchedURL string `json:"fetched_url,omitempty"` // 实际抓取的地址（跟随重定向后），固定数据为空
+
+	Extra map[string]any `json:"extra,omitempty"` // 按 OUTPUT_RAW_FIELDS 输出的 gofeed 原始字段（guid、enclosures、extensions 等），默认不输出
+
+	content string // 文章正文摘录（纯文本），仅用于内容增强，不输出
+	weight  int    // 所属博客的排序权重，不输出
 }
 
 // AllData 用于最终输出 JSON
@@ -27,8 +55,48 @@ type Article struct {
 //
 //	包含文章条目，及更新日期格式（中文格式的时间字符串）
 type AllData struct {
-	Items   []Article `json:"items"`   // 所有文章条目
-	Updated string    `json:"updated"` // 数据更新时间（如 "2025年03月09日 15:04:05"）
+	Items   []Article `json:"items"`             // 所有文章条目
+	Groups  []string  `json:"groups,omitempty"`  // 所有分组，按RSS列表中的顺序，便于前端渲染标签页
+	Updated string    `json:"updated,omitempty"` // 数据更新时间（如 "2025年03月09日 15:04:05"），OUTPUT_UPDATED=false 时省略
+
+	Generator string `json:"generator,omitempty"` // 生成 data.json 的程序版本（如 "lhasaRSS v1.2.0+1a2b3c4"），便于反馈问题时定位构建
+}
+
+// BlogStatus 单个博客的存活状态，写入 blogs.json
+//
+// Description:
+//
+//	与 RSS 是否可解析无关，仅记录博客主页能否访问，以及连续无法访问的次数，
+//	用于生成"失联博客"报告
+type BlogStatus struct {
+	Name        string `json:"name"`                    // 博客名称
+	FeedLink    string `json:"feed"`                    // RSS 地址
+	Homepage    string `json:"homepage"`                // 博客主页
+	Alive       bool   `json:"alive"`                   // 本次检查主页是否可访问
+	HTTPStatus  int    `json:"http_status"`             // 本次检查的 HTTP 状态码，请求失败时为 0
+	Error       string `json:"error,omitempty"`         // 请求失败的原因
+	CheckedAt   string `json:"checked_at"`              // 本次检查时间
+	LastAliveAt string `json:"last_alive_at,omitempty"` // 最近一次可访问的时间，同一天内不再刷新
+	DeadRuns    int    `json:"dead_runs"`               // 连续无法访问的运行次数
+	FeedStatus  string `json:"feed_status"`             // 本次 RSS 抓取状态：ok / empty / auth（需要登录或付费）/ error
+	Posts       int    `json:"posts"`                   // 本次 RSS 中的文章数，空订阅与抓取失败时为 0
+	Note        string `json:"note,omitempty"`          // RSS 列表中填写的备注（note=），如 高中同学、已停更保留纪念
+
+	// 站点元数据（BLOG_METADATA=true 时从 RSS 中提取，抓取失败时沿用上次的值）
+	Description string `json:"description,omitempty"` // 博客简介（RSS description，纯文本）
+	Language    string `json:"language,omitempty"`    // 博客语言（RSS language）
+	Generator   string `json:"generator,omitempty"`   // 博客程序（RSS generator，如 Hexo、Hugo）
+
+	// 订阅内容的变化记录，与文章发布时间无关，可用于发现时间戳错误（如每次构建都刷新发布时间）的订阅
+	ContentHash   string `json:"content_hash,omitempty"`    // 订阅文章内容的哈希
+	ETag          string `json:"etag,omitempty"`            // 上次响应的 ETag
+	LastChangedAt string `json:"last_changed_at,omitempty"` // 订阅内容最近一次变化的时间
+}
+
+// BlogsData 用于输出 blogs.json
+type BlogsData struct {
+	Items   []BlogStatus `json:"items"`   // 所有博客的状态
+	Updated string       `json:"updated"` // 数据更新时间
 }
 
 // feedResult 用于并发抓取时，保存单个 RSS feed 的抓取结果（或错误信息）
@@ -39,6 +107,46 @@ type AllData struct {
 type feedResult struct {
 	Article    *Article  // 抓取到的最新一篇文章（若失败则为 nil）
 	FeedLink   string    // RSS 地址
+	Homepage   string    // 博客主页（RSS 中的 link，抓取失败时为空）
+	FinalURL   string    // 跟随重定向后实际抓取的地址，用于识别跳转到域名停放服务的订阅
 	Err        error     // 抓取过程中的错误
 	ParsedTime time.Time // 正确解析到的发布时间，用于后续对抓取结果排序
+	CertExpiry time.Time // HTTPS 证书到期时间（非 HTTPS 或未取得时为零值）
+	Attempts   int       // 抓取 RSS 的尝试次数
+	Strategy   string    // 最后一次尝试使用的抓取方式：plain 或 fix
+	Name       string    // RSS列表中为该订阅指定的博客名称（name=），为空表示未指定
+	Note       string    // RSS列表中为该订阅填写的备注（note=），为空表示未填写
+
+	Feed *gofeed.Feed // 解析后的原始 Feed（抓取失败时为 nil），供后续检测使用
+
+	Empty     bool // 订阅可正常解析但没有任何文章，此时 Err 与 Article 均为 nil
+	ItemCount int  // 订阅中的文章数
+
+	FieldIssues []string // 输出前对文章字段所做的修正（截断标题、替换危险链接等），为空表示字段正常
+
+	InsecureTLS bool // 修复模式重试时跳过了证书校验（INSECURE_TLS_DOMAINS 白名单内）
+
+	ETag string // 订阅响应的 ETag，用于判断内容是否变化
+
+	Bytes int // 抓取订阅下载的字节数（含重试），用于流量统计
+}
+
+// fetchInfo 记录单个RSS抓取过程中的附加信息
+type fetchInfo struct {
+	FinalURL   string    // 跟随重定向后实际抓取的地址
+	CertExpiry time.Time // HTTPS 证书到期时间
+	Attempts   int       // 实际尝试次数（包含首次尝试）
+	Strategy   string    // 最后一次尝试使用的抓取方式：plain（常规）或 fix（忽略证书、自定义UA）
+	Insecure   bool      // 是否有尝试跳过了证书校验
+	ETag       string    // 最后一次响应的 ETag
+
+	Bytes int // 所有尝试累计读取的响应体字节数
+
+	Latency time.Duration // 成功的那次尝试的耗时，用于 ADAPTIVE_TIMEOUT
+}
+
+// timedArticle 带有已解析发布时间的文章，用于排序
+type timedArticle struct {
+	article Article
+	t       time.Time
 }
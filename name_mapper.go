@@ -0,0 +1,146 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: name_mapper.go
+// Description: 博客名称映射，支持按 RSS 地址、域名、完整标题及正则表达式匹配，用于缩短或统一博客名称
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// NameMappingRule 名称映射规则
+//
+// Description:
+//
+//	URL、Domain、Title、Pattern 只需填写其一，Name 为替换后的博客名称
+//	Pattern 为正则表达式，会同时尝试匹配博客标题与 RSS 地址
+type NameMappingRule struct {
+	URL     string `json:"url,omitempty"`     // 完整 RSS 地址
+	Domain  string `json:"domain,omitempty"`  // 域名，如 lhasa.icu
+	Title   string `json:"title,omitempty"`   // RSS 中的完整博客标题
+	Pattern string `json:"pattern,omitempty"` // 正则表达式
+	Name    string `json:"name"`              // 映射后的名称
+}
+
+// NameMappingData 名称映射文件的数据结构
+type NameMappingData struct {
+	Items []NameMappingRule `json:"items"`
+}
+
+// regexRule 预编译后的正则规则
+type regexRule struct {
+	re   *regexp.Regexp
+	name string
+}
+
+// NameMapper 名称映射器
+type NameMapper struct {
+	byURL    map[string]string
+	byDomain map[string]string
+	byTitle  map[string]string
+	patterns []regexRule
+	config   *Config
+	cache    *fetchCache
+}
+
+// NewNameMapper 创建新的名称映射器，cache 为 nil 时不使用条件请求
+func NewNameMapper(config *Config, cache *fetchCache) *NameMapper {
+	return &NameMapper{
+		byURL:    make(map[string]string),
+		byDomain: make(map[string]string),
+		byTitle:  make(map[string]string),
+		config:   config,
+		cache:    cache,
+	}
+}
+
+// LoadNameMap 从远程URL或本地文件加载名称映射
+//
+// Description:
+//
+//	NameMappingURL 以 http(s):// 开头时通过条件请求下载，否则视为本地文件路径
+//	未配置时直接返回，不视为错误
+func (nm *NameMapper) LoadNameMap(ctx context.Context) error {
+	src := nm.config.NameMappingURL
+	if src == "" {
+		return nil
+	}
+
+	var body []byte
+	var err error
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		client := &http.Client{Timeout: 30 * time.Second}
+		body, _, err = nm.cache.conditionalGet(ctx, client, src)
+	} else {
+		body, err = os.ReadFile(src)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch name mapping: %w", err)
+	}
+
+	var data NameMappingData
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("failed to parse name mapping JSON: %w", err)
+	}
+
+	for _, rule := range data.Items {
+		if rule.Name == "" {
+			continue
+		}
+		switch {
+		case rule.URL != "":
+			nm.byURL[strings.TrimSpace(rule.URL)] = rule.Name
+		case rule.Domain != "":
+			nm.byDomain[urlnorm.CanonicalHost(rule.Domain)] = rule.Name
+		case rule.Title != "":
+			nm.byTitle[strings.TrimSpace(rule.Title)] = rule.Name
+		case rule.Pattern != "":
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				fmt.Printf("[WARN] 名称映射正则无效, 已忽略: %s (%v)\n", rule.Pattern, err)
+				continue
+			}
+			nm.patterns = append(nm.patterns, regexRule{re: re, name: rule.Name})
+		}
+	}
+
+	fmt.Printf("[INFO] 成功加载 %d 条名称映射\n", len(nm.byURL)+len(nm.byDomain)+len(nm.byTitle)+len(nm.patterns))
+	return nil
+}
+
+// Lookup 根据 RSS 地址和博客标题查找映射后的名称
+//
+// Description:
+//
+//	匹配优先级: 完整 RSS 地址 > 域名 > 完整标题 > 正则(先标题后地址)
+func (nm *NameMapper) Lookup(feedURL, title string) (string, bool) {
+	if nm == nil {
+		return "", false
+	}
+	if name, ok := nm.byURL[strings.TrimSpace(feedURL)]; ok {
+		return name, true
+	}
+	if host := urlnorm.Host(feedURL); host != "" {
+		if name, ok := nm.byDomain[host]; ok {
+			return name, true
+		}
+	}
+	if name, ok := nm.byTitle[strings.TrimSpace(title)]; ok {
+		return name, true
+	}
+	for _, rule := range nm.patterns {
+		if rule.re.MatchString(title) || rule.re.MatchString(feedURL) {
+			return rule.name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,175 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: notify.go
+// Description: 通知渠道的统一接口（证书即将到期、运行摘要等需要及时处理的情况），
+// 消息正文由所有渠道共用的模板（NOTIFY_TEMPLATE）生成，新增渠道只需实现 Notifier
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// RunReport 一条通知的内容
+type RunReport struct {
+	Title   string       // 标题
+	Content string       // 正文（未经模板处理）
+	RunID   string       // 本次运行的唯一标识
+	Version string       // 程序版本
+	Profile string       // 当前配置方案，未使用多配置方案时为空
+	Time    time.Time    // 生成时间
+	Summary *summaryData // 运行摘要数据，仅运行摘要通知中不为空
+
+	Body string // 按 NOTIFY_TEMPLATE 生成的正文，发送前填充，各渠道发送的正是该内容
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	// Name 渠道名称，用于日志
+	Name() string
+	// Send 发送一条通知，正文使用 report.Body
+	Send(ctx context.Context, report RunReport) error
+}
+
+// defaultNotifyTemplate 默认的通知正文模板
+const defaultNotifyTemplate = `{{.Content}}`
+
+// configNotifiers 返回已配置的通知渠道
+func configNotifiers(cfg *Config) []Notifier {
+	var notifiers []Notifier
+	if cfg.NotifyWebhook != "" {
+		notifiers = append(notifiers, webhookNotifier{url: cfg.NotifyWebhook})
+	}
+	if cfg.TelegramBotToken != "" {
+		notifiers = append(notifiers, telegramNotifier{token: cfg.TelegramBotToken, chatID: cfg.TelegramChatID})
+	}
+	if cfg.SMTPHost != "" {
+		notifiers = append(notifiers, newEmailNotifier(cfg))
+	}
+	return notifiers
+}
+
+// newRunReport 生成一条通知，附带本次运行的标识与版本
+func newRunReport(title, content string) RunReport {
+	return RunReport{
+		Title:   title,
+		Content: content,
+		RunID:   runID,
+		Version: versionString(),
+		Profile: activeProfile,
+		Time:    time.Now(),
+	}
+}
+
+// renderNotifyBody 按 NOTIFY_TEMPLATE 生成通知正文
+//
+// Description:
+//
+//	模板可使用 RunReport 的所有字段（运行摘要通知中还可使用 .Summary），以及 tr/trf 文案函数和 duration、bytes 格式化函数；
+//	模板读取、解析或执行失败时回退到默认模板（只输出正文）
+func renderNotifyBody(tmplPath string, report RunReport) string {
+	if tmplPath != "" {
+		raw, err := os.ReadFile(tmplPath)
+		if err == nil {
+			var out string
+			if out, err = executeNotifyTemplate(string(raw), report); err == nil {
+				return out
+			}
+		}
+		fmt.Printf("[WARN] 通知模板 %s 不可用, 使用默认模板: %v\n", tmplPath, err)
+	}
+	out, _ := executeNotifyTemplate(defaultNotifyTemplate, report)
+	return out
+}
+
+// executeNotifyTemplate 解析并执行通知正文模板
+func executeNotifyTemplate(text string, report RunReport) (string, error) {
+	tmpl, err := template.New("notify").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, report); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// sendNotification 向所有已配置的通知渠道发送一条通知，未配置任何渠道时直接返回
+func sendNotification(ctx context.Context, cfg *Config, title, content string) error {
+	return sendRunReport(ctx, cfg, newRunReport(title, content))
+}
+
+// sendRunReport 按模板生成正文后发送到所有通知渠道
+//
+// Description:
+//
+//	各渠道依次发送，单个渠道失败不影响其他渠道，所有失败合并为一个错误返回
+func sendRunReport(ctx context.Context, cfg *Config, report RunReport) error {
+	notifiers := configNotifiers(cfg)
+	if len(notifiers) == 0 {
+		return nil
+	}
+	report.Body = renderNotifyBody(cfg.NotifyTemplate, report)
+	var errs []error
+	for _, n := range notifiers {
+		if err := n.Send(ctx, report); err != nil {
+			errs = append(errs, wrapErrorf(err, "发送通知失败 (%s)", n.Name()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// webhookNotifier 以 POST JSON {"title": ..., "content": ..., "run_id": ...} 的形式发送到 NOTIFY_WEBHOOK
+type webhookNotifier struct {
+	url string
+}
+
+// Name 渠道名称
+func (n webhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Send 发送一条通知
+func (n webhookNotifier) Send(ctx context.Context, report RunReport) error {
+	payload, err := json.Marshal(map[string]string{
+		"title":   report.Title,
+		"content": report.Body,
+		"run_id":  report.RunID,
+	})
+	if err != nil {
+		return err
+	}
+	return postNotification(ctx, n.url, "application/json", payload)
+}
+
+// postNotification 以 POST 请求发送通知，非 2xx 响应视为失败
+func postNotification(ctx context.Context, url, contentType string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP状态码: %d, Body: %s", resp.StatusCode, string(respBytes))
+	}
+	return nil
+}
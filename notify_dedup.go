@@ -0,0 +1,106 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: notify_dedup.go
+// Description: 告警通知的去重，同一问题在 NOTIFY_DEDUP_WINDOW 时间窗口内只通知一次，记录保存在抓取缓存中
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// notifyItem 告警通知中的一项
+type notifyItem struct {
+	Key  string // 去重键，同一问题在多次运行中保持不变（如 "cert:" + RSS 地址），不能包含剩余天数等会变化的内容
+	Text string // 通知中显示的文字
+}
+
+// notifyDue 判断去重键在时间窗口内是否尚未通知过
+//
+// Description:
+//
+//	window <= 0 或没有抓取缓存（c 为 nil）时不去重，每次都通知
+func (c *fetchCache) notifyDue(key string, window time.Duration, now time.Time) bool {
+	if c == nil || window <= 0 {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	last, ok := c.Notified[key]
+	return !ok || now.Sub(last) >= window
+}
+
+// markNotified 记录去重键的通知时间
+func (c *fetchCache) markNotified(key string, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Notified == nil {
+		c.Notified = make(map[string]time.Time)
+	}
+	c.Notified[key] = now
+}
+
+// pruneNotified 删除超过时间窗口的通知记录
+//
+// Description:
+//
+//	问题恢复后其记录不再更新，过期后删除，避免缓存无限增长；之后再次出现时会重新通知
+func (c *fetchCache) pruneNotified(window time.Duration, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, last := range c.Notified {
+		if now.Sub(last) >= window {
+			delete(c.Notified, key)
+		}
+	}
+}
+
+// sendAlert 发送一条告警通知，时间窗口内已通知过的同一告警不再发送
+//
+// Description:
+//
+//	只有发送成功后才记录通知时间，所有渠道都失败时下一次运行会重新尝试
+func sendAlert(ctx context.Context, cfg *Config, cache *fetchCache, key, title, content string) error {
+	window := time.Duration(cfg.NotifyDedupWindow) * time.Hour
+	now := time.Now()
+	if !cache.notifyDue(key, window, now) {
+		fmt.Printf("[INFO] 告警 %s 在 %s 内已通知过, 本次不再发送\n", title, window)
+		return nil
+	}
+	if err := sendNotification(ctx, cfg, title, content); err != nil {
+		return err
+	}
+	cache.markNotified(key, now)
+	return nil
+}
+
+// sendAlertItems 将多项告警合并为一条通知发送，时间窗口内已通知过的项不再包含在内，全部通知过时不发送
+func sendAlertItems(ctx context.Context, cfg *Config, cache *fetchCache, title, header string, items []notifyItem) error {
+	window := time.Duration(cfg.NotifyDedupWindow) * time.Hour
+	now := time.Now()
+	var due []notifyItem
+	content := header
+	for _, item := range items {
+		if cache.notifyDue(item.Key, window, now) {
+			due = append(due, item)
+			content += "\n" + item.Text
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+	if err := sendNotification(ctx, cfg, title, content); err != nil {
+		return err
+	}
+	for _, item := range due {
+		cache.markNotified(item.Key, now)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: notify_digest.go
+// Description: 新文章通知摘要，按 NOTIFY_DIGEST_TIMES 配置的时间点汇总发送，而不是每次运行都通知
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// digestAnnouncedTTL 已通知文章的保留时长，超过后从缓存中清理
+const digestAnnouncedTTL = 30 * 24 * time.Hour
+
+// digestItem 待发送摘要中的一篇文章
+type digestItem struct {
+	BlogName string    `json:"blog_name"`
+	Title    string    `json:"title"`
+	Link     string    `json:"link"`
+	Found    time.Time `json:"found"` // 首次发现的时间
+}
+
+// digestState 通知摘要的跨运行状态，保存在抓取缓存中
+type digestState struct {
+	Pending   []digestItem         `json:"pending,omitempty"`   // 尚未发送的文章
+	Announced map[string]time.Time `json:"announced,omitempty"` // 已发送过的文章链接及发送时间
+	LastSent  time.Time            `json:"last_sent,omitempty"` // 上次检查并发送摘要的时间
+}
+
+// parseDigestTimes 解析 NOTIFY_DIGEST_TIMES 中的时间点（HH:MM），返回距当天零点的时长
+func parseDigestTimes(times []string) ([]time.Duration, error) {
+	var slots []time.Duration
+	for _, s := range times {
+		t, err := time.Parse("15:04", s)
+		if err != nil {
+			return nil, fmt.Errorf("NOTIFY_DIGEST_TIMES 中的时间无效: %s (格式为 HH:MM)", s)
+		}
+		slots = append(slots, time.Duration(t.Hour())*time.Hour+time.Duration(t.Minute())*time.Minute)
+	}
+	return slots, nil
+}
+
+// lastDigestSlot 返回不晚于 now 的最近一个发送时间点
+func lastDigestSlot(now time.Time, slots []time.Duration) time.Time {
+	var latest time.Time
+	for _, day := range []int{0, -1} {
+		midnight := time.Date(now.Year(), now.Month(), now.Day()+day, 0, 0, 0, 0, now.Location())
+		for _, d := range slots {
+			if t := midnight.Add(d); !t.After(now) && t.After(latest) {
+				latest = t
+			}
+		}
+	}
+	return latest
+}
+
+// queueDigest 将新文章加入待发送摘要，已在队列中或已通知过的文章跳过
+func (c *fetchCache) queueDigest(articles []Article, now time.Time) {
+	if c == nil || len(articles) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Digest == nil {
+		c.Digest = &digestState{}
+	}
+	queued := make(map[string]bool, len(c.Digest.Pending))
+	for _, it := range c.Digest.Pending {
+		queued[it.Link] = true
+	}
+	for _, a := range articles {
+		if _, ok := c.Digest.Announced[a.Link]; ok || queued[a.Link] || a.Link == "" {
+			continue
+		}
+		queued[a.Link] = true
+		c.Digest.Pending = append(c.Digest.Pending, digestItem{BlogName: a.BlogName, Title: a.Title, Link: a.Link, Found: now})
+	}
+}
+
+// flushNotifyDigest 到达发送时间点时发送摘要
+//
+// Description:
+//
+//	自上次检查以来经过了 NOTIFY_DIGEST_TIMES 中的任一时间点（按 TZ 所在时区）且有待发送的文章时，
+//	将其汇总为一条通知发送；首次运行只记录检查时间。发送失败时保留队列，下次运行重试
+func flushNotifyDigest(ctx context.Context, cfg *Config, cache *fetchCache, now time.Time) error {
+	if len(cfg.NotifyDigestTimes) == 0 || cache == nil {
+		return nil
+	}
+	slots, err := parseDigestTimes(cfg.NotifyDigestTimes)
+	if err != nil {
+		return err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.Digest == nil {
+		cache.Digest = &digestState{}
+	}
+	state := cache.Digest
+	for link, t := range state.Announced {
+		if now.Sub(t) > digestAnnouncedTTL {
+			delete(state.Announced, link)
+		}
+	}
+	if state.LastSent.IsZero() {
+		state.LastSent = now
+		return nil
+	}
+	if !lastDigestSlot(now, slots).After(state.LastSent) {
+		return nil
+	}
+	if len(state.Pending) == 0 {
+		state.LastSent = now
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(trf("共 %d 篇新文章:\n", len(state.Pending)))
+	for _, it := range state.Pending {
+		fmt.Fprintf(&sb, "- %s: %s\n  %s\n", it.BlogName, it.Title, it.Link)
+	}
+	if err := sendNotification(ctx, cfg, "lhasaRSS "+tr("新文章摘要"), sb.String()); err != nil {
+		return err
+	}
+	if state.Announced == nil {
+		state.Announced = make(map[string]time.Time)
+	}
+	for _, it := range state.Pending {
+		state.Announced[it.Link] = now
+	}
+	fmt.Printf("[INFO] 已发送新文章摘要, 共 %d 篇\n", len(state.Pending))
+	state.Pending = nil
+	state.LastSent = now
+	return nil
+}
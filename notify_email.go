@@ -0,0 +1,108 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: notify_email.go
+// Description: 邮件通知渠道，通过 SMTP 发送纯文本邮件，支持 STARTTLS（587 端口）与 SSL（465 端口）
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emailNotifier 通过 SMTP_* 配置的邮件服务器发送到 NOTIFY_EMAIL_TO
+type emailNotifier struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// newEmailNotifier 根据配置创建邮件渠道，未设置发件人时使用 SMTP 用户名
+func newEmailNotifier(cfg *Config) emailNotifier {
+	from := cfg.NotifyEmailFrom
+	if from == "" {
+		from = cfg.SMTPUsername
+	}
+	return emailNotifier{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     from,
+		to:       cfg.NotifyEmailTo,
+	}
+}
+
+// Name 渠道名称
+func (n emailNotifier) Name() string {
+	return "email"
+}
+
+// Send 发送一封纯文本邮件，标题作为邮件主题
+func (n emailNotifier) Send(ctx context.Context, report RunReport) error {
+	var msg strings.Builder
+	msg.WriteString("From: " + n.from + "\r\n")
+	msg.WriteString("To: " + strings.Join(n.to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", report.Title) + "\r\n")
+	msg.WriteString("Date: " + report.Time.Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(report.Body, "\n", "\r\n"))
+
+	addr := net.JoinHostPort(n.host, strconv.Itoa(n.port))
+	var auth smtp.Auth
+	if n.username != "" {
+		auth = smtp.PlainAuth("", n.username, n.password, n.host)
+	}
+	if n.port != 465 {
+		// smtp.SendMail 在服务器支持时自动使用 STARTTLS
+		return smtp.SendMail(addr, auth, n.from, n.to, []byte(msg.String()))
+	}
+
+	// 465 端口从连接建立起就使用 TLS，smtp.SendMail 不支持，需要手动建立连接
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: 10 * time.Second}, Config: &tls.Config{ServerName: n.host}}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(n.from); err != nil {
+		return err
+	}
+	for _, to := range n.to {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("收件人 %s 被拒绝: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(msg.String())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,48 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: notify_telegram.go
+// Description: Telegram 通知渠道，通过 Bot API 将通知发送到指定的会话
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// telegramMaxRunes Telegram 单条消息的长度上限
+const telegramMaxRunes = 4096
+
+// telegramNotifier 通过 TELEGRAM_BOT_TOKEN 对应的机器人发送到 TELEGRAM_CHAT_ID
+type telegramNotifier struct {
+	token  string
+	chatID string
+}
+
+// Name 渠道名称
+func (n telegramNotifier) Name() string {
+	return "telegram"
+}
+
+// Send 以纯文本发送一条消息，标题作为第一行，超过长度上限时截断
+func (n telegramNotifier) Send(ctx context.Context, report RunReport) error {
+	text := []rune(report.Title + "\n\n" + report.Body)
+	if len(text) > telegramMaxRunes {
+		text = text[:telegramMaxRunes]
+	}
+	payload, err := json.Marshal(map[string]any{
+		"chat_id":                  n.chatID,
+		"text":                     string(text),
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	err = postNotification(ctx, "https://api.telegram.org/bot"+n.token+"/sendMessage", "application/json", payload)
+	if err != nil {
+		// 请求地址中包含机器人 Token，网络错误的信息里会带上地址，输出前隐去
+		return errors.New(strings.ReplaceAll(err.Error(), n.token, "****"))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: on_this_day.go
+// Description: “那年今日” onthisday.json，列出往年同月同日发布的友链文章
+
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// OnThisDayItem 往年今日发布的一篇文章
+type OnThisDayItem struct {
+	BlogName  string `json:"blog_name"`
+	Title     string `json:"title"`
+	Link      string `json:"link"`
+	Avatar    string `json:"avatar"`
+	Published string `json:"published"` // 发布日期，如 2021-06-01
+	YearsAgo  int    `json:"years_ago"` // 距今年数
+}
+
+// OnThisDayData 用于输出 onthisday.json
+type OnThisDayData struct {
+	Date  string          `json:"date"` // 今天的日期，如 2025-06-01
+	Items []OnThisDayItem `json:"items"`
+}
+
+// onThisDayOutput 生成待上传的 onthisday.json
+//
+// Description:
+//
+//	历史文章来自 data.json 中的所有文章（含固定数据及增量合并保留的旧文章）以及本次抓取到的各订阅的全部条目，
+//	按链接去重后筛选发布日期（按 TZ 所在时区）与今天同月同日、且早于今年的文章，从最早的年份开始排列；
+//	同一天内多次运行结果相同，内容未变化时跳过上传
+//
+// Parameters:
+//   - articles : 最终输出的文章列表
+//   - results  : 本次抓取结果，使用其中每个订阅的全部条目
+//   - now      : 当前时间
+//
+// Returns:
+//   - outputFile : 待上传的 onthisday.json，没有往年今日的文章时 items 为空数组
+//   - error      : 序列化失败时返回错误
+func onThisDayOutput(cfg *Config, articles []Article, results []feedResult, now time.Time) (outputFile, error) {
+	var items []OnThisDayItem
+	seen := make(map[string]bool)
+	add := func(a Article, t time.Time) {
+		t = t.In(now.Location())
+		if a.Link == "" || seen[a.Link] || t.Month() != now.Month() || t.Day() != now.Day() || t.Year() >= now.Year() {
+			return
+		}
+		seen[a.Link] = true
+		items = append(items, OnThisDayItem{
+			BlogName:  a.BlogName,
+			Title:     a.Title,
+			Link:      a.Link,
+			Avatar:    a.Avatar,
+			Published: t.Format("2006-01-02"),
+			YearsAgo:  now.Year() - t.Year(),
+		})
+	}
+
+	for _, a := range articles {
+		if a.Dead {
+			continue
+		}
+		if _, t, err := normalizeDate(a.Published); err == nil {
+			add(a, t)
+		}
+	}
+	// 订阅中的其他条目沿用最新文章已完成映射的博客名称与头像
+	for _, r := range results {
+		if r.Err != nil || r.Article == nil || r.Feed == nil {
+			continue
+		}
+		for _, item := range r.Feed.Items {
+			if item.PublishedParsed == nil {
+				continue
+			}
+			a := *r.Article
+			a.Title, a.Link = item.Title, item.Link
+			guardArticleFields(&a, r.Homepage)
+			add(a, *item.PublishedParsed)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Published != items[j].Published {
+			return items[i].Published < items[j].Published
+		}
+		return items[i].Link < items[j].Link
+	})
+	if items == nil {
+		items = []OnThisDayItem{}
+	}
+
+	jsonBytes, err := marshalJSON(OnThisDayData{Date: now.Format("2006-01-02"), Items: items}, cfg.OutputIndent)
+	if err != nil {
+		return outputFile{}, wrapErrorf(err, "onthisday.json 序列化失败")
+	}
+	return outputFile{
+		Target:        siblingPath(cfg.DataURL, "onthisday.json"),
+		Data:          jsonBytes,
+		CommitMsg:     "Update onthisday.json",
+		SkipUnchanged: true,
+	}, nil
+}
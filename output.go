@@ -0,0 +1,200 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: output.go
+// Description: 输出排序与序列化，保证相同输入生成逐字节相同的 data.json；并发上传多个输出文件
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// sortTimedArticles 按置顶、权重和发布时间排序
+//
+// Description:
+//
+//	置顶博客的文章排在最前，其次按权重从大到小，权重相同时按发布时间倒序；
+//	发布时间也相同时依次按博客名称、文章链接排序，避免并发抓取的完成顺序影响输出
+func sortTimedArticles(items []timedArticle) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.article.Pinned != b.article.Pinned {
+			return a.article.Pinned
+		}
+		if a.article.weight != b.article.weight {
+			return a.article.weight > b.article.weight
+		}
+		if !a.t.Equal(b.t) {
+			return a.t.After(b.t)
+		}
+		if a.article.BlogName != b.article.BlogName {
+			return a.article.BlogName < b.article.BlogName
+		}
+		return a.article.Link < b.article.Link
+	})
+}
+
+// renderData 将文章序列化为最终的 data.json 内容
+//
+// Description:
+//
+//	字段顺序由结构体定义固定，按 indent 缩进（为空时输出紧凑格式）并以换行结尾；
+//	withUpdated 为 false 时省略易变的 updated 字段，内容不变时输出完全一致；
+//	返回完整内容而不是写入存储的流：上传（Storage.Write、GitHub 内容接口）、变化比较与 pre-upload 钩子都以 []byte 为输入
+func renderData(articles []Article, groups []string, withUpdated bool, indent string) ([]byte, error) {
+	allData := AllData{Items: articles, Groups: groups, Generator: generatorName()}
+	if withUpdated {
+		allData.Updated = time.Now().Format("2006年01月02日 15:04:05")
+	}
+	var buf bytes.Buffer
+	if err := writeDataJSON(&buf, allData, indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeDataJSON 写出 AllData，输出与 marshalJSON(data, indent) 加换行完全一致
+//
+// Description:
+//
+//	文章逐篇序列化后写出，省去 json.MarshalIndent 先整体序列化、再复制一份缩进结果的中间副本；
+//	调用方（renderData）仍会在内存中得到完整的 data.json，峰值内存约为最终文件大小，而不是其两到三倍；
+//	items 以外的字段（groups、updated、generator 等）由结构体整体序列化得到，新增字段时无需修改本函数，
+//	要求 items 是 AllData 的第一个字段
+func writeDataJSON(w io.Writer, data AllData, indent string) error {
+	nl, colon := "\n", ": "
+	if indent == "" {
+		nl, colon = "", ":"
+	}
+	itemIndent := indent + indent
+
+	// 不含文章的其余部分，"items" 的值为 null，之后替换为逐篇写出的文章
+	rest := data
+	rest.Items = nil
+	skeleton, err := marshalIndent(rest, "", indent)
+	if err != nil {
+		return err
+	}
+	head := []byte("{" + nl + indent + `"items"` + colon + "null")
+	if !bytes.HasPrefix(skeleton, head) {
+		return errors.New("AllData 的第一个字段必须是 items")
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("{" + nl + indent + `"items"` + colon + "[")
+	if len(data.Items) > 0 {
+		bw.WriteString(nl)
+		for i, item := range data.Items {
+			b, err := marshalIndent(item, itemIndent, indent)
+			if err != nil {
+				return err
+			}
+			bw.WriteString(itemIndent)
+			bw.Write(b)
+			if i < len(data.Items)-1 {
+				bw.WriteString(",")
+			}
+			bw.WriteString(nl)
+		}
+		bw.WriteString(indent)
+	}
+	bw.WriteString("]")
+	bw.Write(skeleton[len(head):])
+	bw.WriteString("\n")
+	return bw.Flush()
+}
+
+// marshalJSON 按 OUTPUT_INDENT 序列化输出文件，indent 为空时输出紧凑格式
+func marshalJSON(v interface{}, indent string) ([]byte, error) {
+	return marshalIndent(v, "", indent)
+}
+
+// marshalIndent 与 json.MarshalIndent 相同，但 indent 为空时不换行
+func marshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	if indent == "" {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+// outputFile 一个待上传的输出文件
+type outputFile struct {
+	Target        string // 保存地址（COS URL 或 GitHub 仓库内路径）
+	Data          []byte // 文件内容
+	CommitMsg     string // 保存到 GitHub 时的提交信息
+	Required      bool   // 是否为必需文件，失败时本次运行视为失败（如 data.json）
+	SkipUnchanged bool   // 内容与已保存的文件相同时跳过上传
+	// Volatile 判断内容是否相同时忽略的 JSON 字段名（任意层级），如每次运行都会刷新的 updated、run_id，仅在 SkipUnchanged 时生效
+	Volatile []string
+}
+
+// uploadOutputs 并发上传多个输出文件，并汇总错误
+//
+// Description:
+//
+//	SAVE_TARGET=COS 时使用有界并发池同时上传；SAVE_TARGET=GITHUB 时每个文件对应一次提交，
+//	同一分支上并发提交会相互冲突（409），因此依次上传
+//	必需文件与可选文件的错误分别用 errors.Join 汇总，与 runLoadTasks 的约定一致
+func uploadOutputs(ctx context.Context, cfg *Config, files []outputFile) loadErrors {
+	workers := 4
+	if cfg.SaveTarget == "GITHUB" {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		fatals   []error
+		warnings []error
+	)
+	for _, f := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(f outputFile) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer recoverPanic(trf("上传 %s", f.Target), func(err error) {
+				mu.Lock()
+				if f.Required {
+					fatals = append(fatals, fmt.Errorf("上传 %s 失败: %w", f.Target, err))
+				} else {
+					warnings = append(warnings, fmt.Errorf("上传 %s 失败: %w", f.Target, err))
+				}
+				mu.Unlock()
+			})
+
+			var err error
+			if f.SkipUnchanged {
+				err = saveStoredFileIfChanged(ctx, cfg, f.Target, f.Data, f.CommitMsg, f.Volatile...)
+			} else {
+				err = saveStoredFile(ctx, cfg, f.Target, f.Data, f.CommitMsg)
+			}
+			if err == nil {
+				return
+			}
+			err = fmt.Errorf("上传 %s 失败: %w", f.Target, err)
+			mu.Lock()
+			if f.Required {
+				fatals = append(fatals, err)
+			} else {
+				warnings = append(warnings, err)
+			}
+			mu.Unlock()
+		}(f)
+	}
+	wg.Wait()
+
+	return loadErrors{
+		Fatal:   errors.Join(fatals...),
+		Warning: errors.Join(warnings...),
+	}
+}
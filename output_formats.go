@@ -0,0 +1,65 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: output_formats.go
+// Description: data.json 的额外输出格式（data.js 全局变量、JSONP），供无法跨域读取 JSON 的旧静态页面使用
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// jsIdentifierPattern 允许的全局变量名或回调函数名，如 window.__FRIENDS__、friendsCallback
+var jsIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+
+// validateOutputFormats 校验 OUTPUT_FORMATS 及对应的变量名、回调函数名
+func validateOutputFormats(cfg *Config) error {
+	for _, f := range cfg.OutputFormats {
+		switch f {
+		case "json":
+		case "js":
+			if !jsIdentifierPattern.MatchString(cfg.OutputJSVar) {
+				return fmt.Errorf("OUTPUT_JS_VAR 不是合法的 JavaScript 标识符: %s", cfg.OutputJSVar)
+			}
+		case "jsonp":
+			if !jsIdentifierPattern.MatchString(cfg.OutputCallback) {
+				return fmt.Errorf("OUTPUT_JSONP_CALLBACK 不是合法的 JavaScript 标识符: %s", cfg.OutputCallback)
+			}
+		default:
+			return fmt.Errorf("OUTPUT_FORMATS 中的格式无效: %s (只能是 json、js 或 jsonp)", f)
+		}
+	}
+	return nil
+}
+
+// extraFormatOutputs 按 OUTPUT_FORMATS 将 data.json 的内容包装为其他格式
+//
+// Description:
+//
+//	js    : 与 data.json 同目录同名的 .js 文件，内容为 "window.__FRIENDS__ = {...};"，页面用 <script> 引入后直接读取该变量
+//	jsonp : 同名的 .jsonp 文件，内容为 "friendsCallback({...});"
+//	json 始终输出（即 data.json 本身），列出时不产生额外文件
+func extraFormatOutputs(cfg *Config, jsonBytes []byte) []outputFile {
+	base := strings.TrimSuffix(cfg.DataURL, ".json")
+	payload := strings.TrimSpace(string(jsonBytes))
+
+	var outputs []outputFile
+	for _, f := range cfg.OutputFormats {
+		switch f {
+		case "js":
+			outputs = append(outputs, outputFile{
+				Target:    base + ".js",
+				Data:      []byte(cfg.OutputJSVar + " = " + payload + ";\n"),
+				CommitMsg: "Update data.js",
+			})
+		case "jsonp":
+			outputs = append(outputs, outputFile{
+				Target:    base + ".jsonp",
+				Data:      []byte(cfg.OutputCallback + "(" + payload + ");\n"),
+				CommitMsg: "Update data.jsonp",
+			})
+		}
+	}
+	return outputs
+}
@@ -0,0 +1,42 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: output_test.go
+// Description: writeDataJSON 的测试，逐篇写出的结果必须与 json 序列化整个 AllData 逐字节一致
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestWriteDataJSONMatchesMarshal(t *testing.T) {
+	articles := []Article{
+		{BlogName: "博客", Title: `含 "引号" 与 <尖括号>`, Link: "https://example.com/a?x=1&y=2", Avatar: "https://example.com/a.png",
+			Group: "朋友", Pinned: true, Summary: "摘要", Extra: map[string]any{"guid": "g1", "tags": []string{"a", "b"}}},
+		{BlogName: "Blog", Title: "Post", Link: "https://example.org/p", Archived: true, Retired: true},
+	}
+	cases := map[string]AllData{
+		"full":        {Items: articles, Groups: []string{"朋友", "技术"}, Updated: "2025年03月09日 15:04:05", Generator: "lhasaRSS test"},
+		"no optional": {Items: articles},
+		"empty items": {Items: []Article{}, Generator: "lhasaRSS test"},
+	}
+	for name, data := range cases {
+		for _, indent := range []string{"", "  ", "\t"} {
+			t.Run(fmt.Sprintf("%s/indent=%q", name, indent), func(t *testing.T) {
+				want, err := marshalJSON(data, indent)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want = append(want, '\n')
+				var got bytes.Buffer
+				if err := writeDataJSON(&got, data, indent); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got.Bytes(), want) {
+					t.Fatalf("writeDataJSON 与 json 序列化不一致:\ngot:\n%s\nwant:\n%s", got.Bytes(), want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,329 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: pipeline_test.go
+// Description: 抓取流程（runPipeline）的集成测试，使用 testsupport 的假存储与假 GitHub API，
+//   订阅由 httptest 服务器提供，不访问网络、不需要任何凭证
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/achuanya/lhasaRSS/testsupport"
+)
+
+// pipelineEnv 一次集成测试的运行环境
+type pipelineEnv struct {
+	storage *testsupport.Storage
+	github  *testsupport.GitHub
+	server  *httptest.Server
+	mux     *http.ServeMux // 测试可追加路由，如博客主页
+	dataURL string
+}
+
+// newPipelineEnv 启动提供 RSS 列表与 feeds 个订阅的 httptest 服务器，并以假存储、假 GitHub API 替换真实后端
+func newPipelineEnv(t *testing.T, feeds int) *pipelineEnv {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	var list strings.Builder
+	for i := range feeds {
+		fmt.Fprintf(&list, "%s/feed/%d\n", server.URL, i)
+	}
+	mux.HandleFunc("/rss.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, list.String())
+	})
+	// /list?ids=0-2 只列出指定的订阅（RSS 配置中的逗号表示多个列表，因此用 - 分隔），供测试切换不同的 RSS 列表
+	mux.HandleFunc("/list", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"`+r.URL.RawQuery+`"`)
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), "-") {
+			fmt.Fprintf(w, "%s/feed/%s\n", server.URL, id)
+		}
+	})
+	mux.HandleFunc("/feed/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/feed/")
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel>
+<title>Blog %[1]s</title><link>%[2]s/blog/%[1]s</link>
+<item><title>Post %[1]s</title><link>%[2]s/blog/%[1]s/post</link><pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate></item>
+</channel></rss>`, id, server.URL)
+	})
+
+	env := &pipelineEnv{storage: testsupport.NewStorage(), github: testsupport.NewGitHub(), server: server, mux: mux, dataURL: "data/data.json"}
+
+	prevStorage, prevTransport := sharedMemoryStorage, http.DefaultTransport
+	sharedMemoryStorage, http.DefaultTransport = env.storage, env.github
+	t.Cleanup(func() { sharedMemoryStorage, http.DefaultTransport = prevStorage, prevTransport })
+
+	for k, v := range map[string]string{
+		"SAVE_TARGET":         "MEMORY",
+		"DATA":                env.dataURL,
+		"RSS":                 server.URL + "/rss.txt",
+		"TOKEN":               "test-token",
+		"NAME":                "owner",
+		"REPOSITORY":          "repo",
+		"FETCH_CACHE":         t.TempDir() + "/fetch_cache.json",
+		"AVATAR_CHECK_METHOD": "off",
+		"PREFLIGHT":           "false",
+		"ZOMBIE_CHECK":        "false",
+		"MAX_RETRIES":         "1",
+	} {
+		t.Setenv(k, v)
+	}
+	return env
+}
+
+// data 返回假存储中保存的 data.json
+func (env *pipelineEnv) data(t *testing.T) AllData {
+	t.Helper()
+	raw, ok := env.storage.File(env.dataURL)
+	if !ok {
+		t.Fatalf("%s 未写入", env.dataURL)
+	}
+	var all AllData
+	if err := json.Unmarshal(raw, &all); err != nil {
+		t.Fatalf("解析 %s 失败: %v", env.dataURL, err)
+	}
+	return all
+}
+
+func TestPipelinePublishesData(t *testing.T) {
+	env := newPipelineEnv(t, 3)
+	if code := runPipeline(context.Background(), runOptions{}); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	if got := len(env.data(t).Items); got != 3 {
+		t.Fatalf("data.json 中有 %d 篇文章, want 3", got)
+	}
+	if len(env.github.Commits()) == 0 {
+		t.Error("运行日志没有写入（假）GitHub 仓库")
+	}
+}
+
+func TestPipelineUnchangedSkipsDataUpload(t *testing.T) {
+	env := newPipelineEnv(t, 2)
+	for run := 1; run <= 2; run++ {
+		if code := runPipeline(context.Background(), runOptions{}); code != 0 {
+			t.Fatalf("第 %d 次运行 exit code = %d, want 0", run, code)
+		}
+	}
+	if n := env.storage.Writes(env.dataURL); n != 1 {
+		t.Fatalf("data.json 写入了 %d 次, want 1（第二次运行内容未变化）", n)
+	}
+}
+
+func TestPipelineShrinkGuardBlocksUpload(t *testing.T) {
+	env := newPipelineEnv(t, 1)
+	previous := AllData{}
+	for i := range 10 {
+		previous.Items = append(previous.Items, Article{
+			BlogName: fmt.Sprintf("Old %d", i),
+			Title:    "Old post",
+			Link:     fmt.Sprintf("https://old%d.example/post", i),
+		})
+	}
+	raw, err := json.Marshal(previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.storage.Put(env.dataURL, raw)
+
+	if code := runPipeline(context.Background(), runOptions{}); code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if n := env.storage.Writes(env.dataURL); n != 0 {
+		t.Fatalf("文章数骤减时 data.json 仍被写入 %d 次", n)
+	}
+	if got := len(env.data(t).Items); got != 10 {
+		t.Fatalf("data.json 中有 %d 篇文章, want 保留原有的 10 篇", got)
+	}
+
+	// --force 跳过保护
+	if code := runPipeline(context.Background(), runOptions{Force: true}); code != 0 {
+		t.Fatalf("--force exit code = %d, want 0", code)
+	}
+	if got := len(env.data(t).Items); got != 1 {
+		t.Fatalf("--force 后 data.json 中有 %d 篇文章, want 1", got)
+	}
+}
+
+func TestPipelineUploadFailureExitsNonZero(t *testing.T) {
+	env := newPipelineEnv(t, 2)
+	env.storage.WriteErr = func(target string) error {
+		if target == env.dataURL {
+			return errors.New("injected upload failure")
+		}
+		return nil
+	}
+	if code := runPipeline(context.Background(), runOptions{}); code == 0 {
+		t.Fatal("data.json 上传失败时 exit code = 0, want 非零")
+	}
+	if n := env.storage.Writes(env.dataURL); n == 0 {
+		t.Fatal("没有尝试上传 data.json")
+	}
+}
+
+func TestPipelinePreflightOnlyFailsOnRequiredChecks(t *testing.T) {
+	env := newPipelineEnv(t, 1)
+	t.Setenv("PREFLIGHT", "true")
+	// 数据保存在 MEMORY 中，GitHub 只用于写运行日志，Token 没有写权限只应记录警告
+	env.github.Push = false
+	if code := runPipeline(context.Background(), runOptions{}); code != 0 {
+		t.Fatalf("GitHub 预检失败（与 SAVE_TARGET 无关）时 exit code = %d, want 0", code)
+	}
+
+	t.Setenv("RSS", env.server.URL+"/missing.txt")
+	if code := runPipeline(context.Background(), runOptions{}); code != 1 {
+		t.Fatalf("RSS 列表不可访问时 exit code = %d, want 1", code)
+	}
+}
+
+func TestPipelinePreFetchHookSkipKeepsArticles(t *testing.T) {
+	env := newPipelineEnv(t, 2)
+	t.Setenv("MERGE_MODE", "incremental")
+	if code := runPipeline(context.Background(), runOptions{}); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+
+	// 钩子本次只抓取第一个订阅，第二个订阅仍在 RSS 列表中，其文章不应被清理
+	t.Setenv("HOOK_PRE_FETCH", fmt.Sprintf(`echo '{"feeds":[{"url":"%s/feed/0"}]}'`, env.server.URL))
+	if code := runPipeline(context.Background(), runOptions{}); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	if got := len(env.data(t).Items); got != 2 {
+		t.Fatalf("钩子跳过一个订阅后 data.json 中有 %d 篇文章, want 2", got)
+	}
+	for _, a := range env.data(t).Items {
+		if a.Retired {
+			t.Errorf("%s 被标记为 retired", a.Feed)
+		}
+	}
+}
+
+func TestPipelineProfilesShareFetchCache(t *testing.T) {
+	env := newPipelineEnv(t, 2)
+	for k, v := range map[string]string{
+		"PROFILES":       "A,B",
+		"PROFILE_A_RSS":  env.server.URL + "/list?ids=0",
+		"PROFILE_A_DATA": "data/a.json",
+		"PROFILE_B_RSS":  env.server.URL + "/list?ids=1",
+		"PROFILE_B_DATA": "data/b.json",
+	} {
+		t.Setenv(k, v)
+	}
+	for run := 1; run <= 2; run++ {
+		if code := runProfiles(context.Background(), runOptions{}); code != 0 {
+			t.Fatalf("第 %d 次运行 exit code = %d, want 0", run, code)
+		}
+	}
+
+	raw, err := os.ReadFile(os.Getenv("FETCH_CACHE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cache fetchCache
+	if err := json.Unmarshal(raw, &cache); err != nil {
+		t.Fatal(err)
+	}
+	// 方案 B 运行后清理缓存时，不应删除方案 A 的已知订阅与 RSS 列表的 ETag 记录
+	for _, id := range []string{"0", "1"} {
+		if _, ok := cache.KnownFeeds[env.server.URL+"/feed/"+id]; !ok {
+			t.Errorf("KnownFeeds 中缺少 feed/%s", id)
+		}
+		if _, ok := cache.Entries[env.server.URL+"/list?ids="+id]; !ok {
+			t.Errorf("Entries 中缺少 list?ids=%s", id)
+		}
+	}
+}
+
+func TestPipelineBlogsJSONWithoutLiveness(t *testing.T) {
+	env := newPipelineEnv(t, 2)
+	t.Setenv("BLOGS_JSON", "true")
+	var probes atomic.Int32
+	// 主页检查先发送 HEAD 请求；GET 请求来自头像发现等其他功能
+	env.mux.HandleFunc("/blog/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			probes.Add(1)
+		}
+	})
+	if code := runPipeline(context.Background(), runOptions{}); code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+
+	raw, ok := env.storage.File("data/blogs.json")
+	if !ok {
+		t.Fatal("BLOGS_JSON=true 时未输出 blogs.json")
+	}
+	var blogs BlogsData
+	if err := json.Unmarshal(raw, &blogs); err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs.Items) != 2 || blogs.Items[0].FeedStatus != "ok" || blogs.Items[0].Posts != 1 {
+		t.Errorf("blogs.json = %+v, want 2 个 feed_status=ok、posts=1 的博客", blogs.Items)
+	}
+	if n := probes.Load(); n != 0 {
+		t.Errorf("未开启 BLOG_LIVENESS 时检查了 %d 次博客主页, want 0", n)
+	}
+}
+
+func TestPipelineDigestQueuedAfterUpload(t *testing.T) {
+	env := newPipelineEnv(t, 2)
+	env.mux.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {})
+	t.Setenv("NOTIFY_WEBHOOK", env.server.URL+"/webhook")
+	t.Setenv("NOTIFY_DIGEST_TIMES", "00:00")
+	t.Setenv("RSS", env.server.URL+"/list?ids=0")
+	if code := runPipeline(context.Background(), runOptions{}); code != 0 {
+		t.Fatalf("第 1 次运行 exit code = %d, want 0", code)
+	}
+
+	pending := func() []digestItem {
+		t.Helper()
+		raw, err := os.ReadFile(os.Getenv("FETCH_CACHE"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cache fetchCache
+		if err := json.Unmarshal(raw, &cache); err != nil {
+			t.Fatal(err)
+		}
+		if cache.Digest == nil {
+			return nil
+		}
+		return cache.Digest.Pending
+	}
+
+	// 新增订阅，但 data.json 上传失败：新文章尚未发布，不应进入摘要队列
+	t.Setenv("RSS", env.server.URL+"/list?ids=0-1")
+	env.storage.WriteErr = func(target string) error {
+		if target == env.dataURL {
+			return errors.New("injected write failure")
+		}
+		return nil
+	}
+	if code := runPipeline(context.Background(), runOptions{}); code == 0 {
+		t.Fatal("data.json 上传失败时 exit code = 0, want 非 0")
+	}
+	if got := pending(); len(got) != 0 {
+		t.Fatalf("上传失败后摘要队列 = %+v, want 空", got)
+	}
+
+	// 上传恢复后，同一篇文章仍是新文章并进入队列
+	env.storage.WriteErr = nil
+	if code := runPipeline(context.Background(), runOptions{}); code != 0 {
+		t.Fatalf("第 3 次运行 exit code = %d, want 0", code)
+	}
+	if got := pending(); len(got) != 1 || !strings.HasSuffix(got[0].Link, "/blog/1/post") {
+		t.Fatalf("摘要队列 = %+v, want 只有 blog/1 的文章", got)
+	}
+}
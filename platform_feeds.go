@@ -0,0 +1,131 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: platform_feeds.go
+// Description: 知名平台订阅（YouTube 频道、GitHub Releases 等）的规范化，
+// 使用频道名、仓库名作为博客名称，频道头像、仓库所有者头像作为头像，而不是平台本身的标题和图标
+
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+	"github.com/mmcdole/gofeed"
+)
+
+// platformFeed 平台订阅的规范化结果
+type platformFeed struct {
+	Platform   string // 平台：youtube 或 github
+	Title      string // 博客名称（频道名、owner/repo）
+	Homepage   string // 主页（频道页、仓库页）
+	Avatar     string // 可直接使用的头像地址，为空时从 AvatarPage 的 og:image 获取
+	AvatarPage string // 头像所在页面
+	CacheKey   string // 头像缓存键，同一平台上不同频道、仓库互不共用
+}
+
+// detectPlatformFeed 识别 RSS 地址是否为已知平台的订阅
+//
+// Description:
+//
+//	支持以下地址（均可带 www.）:
+//	  https://www.youtube.com/feeds/videos.xml?channel_id=UCxxx（或 playlist_id=、user=）
+//	  https://github.com/<owner>/<repo>/releases.atom、tags.atom、commits/<branch>.atom
+//	  https://github.com/<user>.atom
+//
+// Returns:
+//   - platformFeed : 规范化结果
+//   - bool         : 是否为已知平台的订阅
+func detectPlatformFeed(rssLink string, feed *gofeed.Feed) (platformFeed, bool) {
+	u, err := url.Parse(rssLink)
+	if err != nil {
+		return platformFeed{}, false
+	}
+	switch urlnorm.SiteHost(rssLink) {
+	case "youtube.com":
+		return youtubeFeed(u, feed)
+	case "github.com":
+		return githubFeed(u)
+	}
+	return platformFeed{}, false
+}
+
+// youtubeFeed 规范化 YouTube 频道或播放列表订阅
+//
+// Description:
+//
+//	订阅标题为频道名（播放列表订阅为列表名），作者为频道名；头像取频道页的 og:image，
+//	订阅本身不包含头像，频道页的 <link rel="icon"> 是 YouTube 的图标
+func youtubeFeed(u *url.URL, feed *gofeed.Feed) (platformFeed, bool) {
+	if u.Path != "/feeds/videos.xml" {
+		return platformFeed{}, false
+	}
+	q := u.Query()
+	p := platformFeed{Platform: "youtube", Title: feed.Title, Homepage: feed.Link}
+	if len(feed.Authors) > 0 && feed.Authors[0].Name != "" {
+		p.Title = feed.Authors[0].Name
+	}
+	switch {
+	case q.Get("channel_id") != "":
+		id := q.Get("channel_id")
+		if p.Homepage == "" {
+			p.Homepage = "https://www.youtube.com/channel/" + id
+		}
+		p.CacheKey = "youtube.com/channel/" + id
+	case q.Get("user") != "":
+		if p.Homepage == "" {
+			p.Homepage = "https://www.youtube.com/user/" + q.Get("user")
+		}
+		p.CacheKey = "youtube.com/user/" + q.Get("user")
+	case q.Get("playlist_id") != "":
+		if p.Homepage == "" {
+			p.Homepage = "https://www.youtube.com/playlist?list=" + q.Get("playlist_id")
+		}
+		p.CacheKey = "youtube.com/playlist/" + q.Get("playlist_id")
+	default:
+		return platformFeed{}, false
+	}
+	// 播放列表页的 og:image 为列表封面
+	p.AvatarPage = p.Homepage
+	return p, true
+}
+
+// githubFeed 规范化 GitHub 的 Releases、Tags、Commits 及用户动态订阅
+//
+// Description:
+//
+//	订阅标题形如 "Release notes from repo"，博客名称改为 owner/repo（用户动态为用户名），
+//	头像使用 https://github.com/<owner>.png（重定向到所有者头像）
+func githubFeed(u *url.URL) (platformFeed, bool) {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && strings.HasSuffix(parts[0], ".atom"):
+		user := strings.TrimSuffix(parts[0], ".atom")
+		return platformFeed{
+			Platform: "github",
+			Title:    user,
+			Homepage: "https://github.com/" + user,
+			Avatar:   "https://github.com/" + user + ".png",
+			CacheKey: "github.com/" + user,
+		}, true
+	case len(parts) >= 3 && (parts[2] == "releases.atom" || parts[2] == "tags.atom" || parts[2] == "commits"):
+		owner, repo := parts[0], parts[1]
+		return platformFeed{
+			Platform: "github",
+			Title:    owner + "/" + repo,
+			Homepage: "https://github.com/" + owner + "/" + repo,
+			Avatar:   "https://github.com/" + owner + ".png",
+			CacheKey: "github.com/" + owner + "/" + repo,
+		}, true
+	}
+	return platformFeed{}, false
+}
+
+// apply 用规范化结果覆盖订阅的标题与主页，后续的名称映射、博客存活检查均基于覆盖后的值
+func (p platformFeed) apply(feed *gofeed.Feed) {
+	if p.Title != "" {
+		feed.Title = p.Title
+	}
+	if p.Homepage != "" {
+		feed.Link = p.Homepage
+	}
+}
@@ -0,0 +1,196 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: preflight.go
+// Description: 启动前的连通性预检，在抓取上百个RSS之前先确认COS、GitHub及RSS列表均可用
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// runPreflight 启动前依次检查本次运行依赖的外部资源
+//
+// Description:
+//
+//	使用 COS 时校验 SecretID/SecretKey 及 Bucket 是否可访问；配置了 GitHub Token 时校验 Token 是否有效、
+//	是否具备仓库写权限；最后校验 RSS 列表文件是否可读取
+//	所有检查都会执行完毕，失败项汇总返回，便于一次性修正配置：SAVE_TARGET、RSS_SOURCE 依赖的检查失败记入 Fatal，
+//	其余（如 SAVE_TARGET=COS 时只用于写运行日志的 GitHub）记入 Warning，不阻止本次运行
+func runPreflight(ctx context.Context, cfg *Config) loadErrors {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var errs, warnings []error
+	if cfg.SaveTarget == "COS" {
+		if err := checkCosBucket(ctx, cfg.TencentSecretID, cfg.TencentSecretKey, cfg.DataURL); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cfg.SaveTarget == "KV" {
+		if err := checkKVAccess(ctx, cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// 日志总是写入 GitHub，因此只要配置了 Token 就需要校验；只有 SAVE_TARGET=GITHUB 时写入失败才会影响发布
+	if cfg.SaveTarget == "GITHUB" || cfg.GitHubToken != "" {
+		if err := checkGitHubAccess(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo); err != nil {
+			if cfg.SaveTarget == "GITHUB" {
+				errs = append(errs, err)
+			} else {
+				warnings = append(warnings, err)
+			}
+		}
+	}
+	if err := checkRSSListReachable(ctx, cfg); err != nil {
+		errs = append(errs, err)
+	}
+	return loadErrors{Fatal: errors.Join(errs...), Warning: errors.Join(warnings...)}
+}
+
+// checkCosBucket 通过 HEAD Bucket 校验 COS 凭证与存储桶是否可用
+func checkCosBucket(ctx context.Context, secretID, secretKey, dataURL string) error {
+	client, _, err := newCosClient(secretID, secretKey, dataURL)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Bucket.Head(ctx)
+	if err != nil {
+		if resp != nil {
+			switch resp.StatusCode {
+			case http.StatusForbidden:
+				return wrapErrorf(err, "COS预检失败: 无权访问存储桶, 请检查 TENCENT_CLOUD_SECRET_ID/TENCENT_CLOUD_SECRET_KEY 的权限")
+			case http.StatusNotFound:
+				return wrapErrorf(err, "COS预检失败: 存储桶不存在, 请检查 DATA 地址: %s", dataURL)
+			}
+		}
+		return wrapErrorf(err, "COS预检失败: %s", dataURL)
+	}
+	return nil
+}
+
+// checkGitHubAccess 校验 GitHub Token 是否有效，以及是否对目标仓库具备写权限
+//
+// Description:
+//
+//	经典 Token 通过响应头 X-OAuth-Scopes 判断是否包含 repo/public_repo，
+//	细粒度 Token 没有该响应头，则以仓库信息中的 permissions.push 为准
+func checkGitHubAccess(ctx context.Context, token, owner, repo string) error {
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return wrapErrorf(err, "GitHub预检失败: 无法访问 %s", apiURL)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return fmt.Errorf("GitHub预检失败: TOKEN 无效或已过期")
+	case http.StatusNotFound:
+		return fmt.Errorf("GitHub预检失败: 仓库 %s/%s 不存在或 TOKEN 无权访问, 请检查 NAME/REPOSITORY", owner, repo)
+	default:
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub预检失败, status: %d, body: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	if scopes := resp.Header.Get("X-OAuth-Scopes"); scopes != "" {
+		hasRepo := false
+		for _, s := range strings.Split(scopes, ",") {
+			s = strings.TrimSpace(s)
+			if s == "repo" || s == "public_repo" {
+				hasRepo = true
+				break
+			}
+		}
+		if !hasRepo {
+			return fmt.Errorf("GitHub预检失败: TOKEN 缺少 repo 权限, 当前权限: %s", scopes)
+		}
+	}
+
+	var info struct {
+		Permissions struct {
+			Push bool `json:"push"`
+		} `json:"permissions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return wrapErrorf(err, "GitHub预检失败: 解析仓库信息失败")
+	}
+	if !info.Permissions.Push {
+		return fmt.Errorf("GitHub预检失败: TOKEN 对仓库 %s/%s 没有写权限", owner, repo)
+	}
+	return nil
+}
+
+// checkRSSListReachable 校验所有 RSS 列表文件是否可读取
+func checkRSSListReachable(ctx context.Context, cfg *Config) error {
+	for _, src := range cfg.RssLists {
+		if err := checkRSSListSource(ctx, cfg, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRSSListSource 校验单个 RSS 列表文件是否可读取
+func checkRSSListSource(ctx context.Context, cfg *Config, src string) error {
+	if strings.HasPrefix(src, "gist:") {
+		// Gist 无轻量的存在性接口, 直接读取一次
+		if _, err := fetchRSSLinksFromGist(ctx, cfg, src); err != nil {
+			return wrapErrorf(err, "RSS列表预检失败: %s", src)
+		}
+		return nil
+	}
+	if cfg.RssSource == "COS" || isRemoteURL(src) {
+		req, err := http.NewRequestWithContext(ctx, "HEAD", src, nil)
+		if err != nil {
+			return wrapErrorf(err, "RSS列表预检失败: %s", src)
+		}
+		client, err := rssListClient(cfg)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return wrapErrorf(err, "RSS列表预检失败: 无法访问 %s", src)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("RSS列表预检失败: %s 返回状态码 %d", src, resp.StatusCode)
+		}
+		return nil
+	}
+	if cfg.RssSource != "GITHUB" {
+		return nil
+	}
+	_, err := os.Stat(src)
+	if os.IsNotExist(err) && cfg.GitHubName != "" && cfg.GitHubRepo != "" {
+		// 本地不存在时从仓库读取, 确认仓库中存在该文件
+		_, sha, err := getGitHubFileContent(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, src)
+		if err != nil {
+			return wrapErrorf(err, "RSS列表预检失败: 无法从GitHub仓库读取 %s", src)
+		}
+		if sha == "" {
+			return errors.New(trf("GitHub仓库 %s/%s 中不存在RSS列表: %s", cfg.GitHubName, cfg.GitHubRepo, src))
+		}
+		return nil
+	}
+	if err != nil {
+		return wrapErrorf(err, "RSS列表预检失败: 本地文件不可读, 请检查 RSS: %s", src)
+	}
+	return nil
+}
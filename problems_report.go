@@ -0,0 +1,86 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: problems_report.go
+// Description: 将本次运行的问题统计按类型整理为 problems.json，与 data.json 一起上传，便于仪表盘展示
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProblemItem 单条问题记录
+type ProblemItem struct {
+	Target    string `json:"target"`           // 出问题的订阅地址、博客主页或文章链接
+	Detail    string `json:"detail,omitempty"` // 问题详情（如 HTTP 状态码、证书到期时间）
+	FirstSeen string `json:"first_seen"`       // 该问题首次出现的时间，连续出现时沿用上次的记录
+}
+
+// ProblemsData 用于输出 problems.json
+type ProblemsData struct {
+	RunID        string                   `json:"run_id"`        // 本次运行的唯一标识，与日志、提交信息中的一致
+	Updated      string                   `json:"updated"`       // 本次运行时间
+	Total        int                      `json:"total"`         // 订阅总数
+	SuccessCount int                      `json:"success_count"` // 成功抓取的订阅数
+	Categories   map[string][]ProblemItem `json:"categories"`    // 按问题类型分类，键同 problems（parseFails、feedEmpties 等）
+}
+
+// problemsOutput 生成待上传的 problems.json
+//
+// Description:
+//
+//	problems 中的每条记录形如 "地址" 或 "地址 (详情)"，拆分为 target 与 detail；
+//	读取上次的 problems.json，同一类型下仍存在的问题沿用其首次出现时间，已消失的问题不再输出
+func problemsOutput(ctx context.Context, cfg *Config, total, successCount int, problems map[string][]string) (outputFile, error) {
+	target := siblingPath(cfg.DataURL, "problems.json")
+
+	firstSeen := make(map[string]string)
+	if raw, err := readStoredFile(ctx, cfg, target); err != nil {
+		fmt.Printf("[WARN] 读取旧 problems.json 失败: %v\n", err)
+	} else if len(raw) > 0 {
+		var old ProblemsData
+		if err := json.Unmarshal(raw, &old); err != nil {
+			fmt.Printf("[WARN] 解析旧 problems.json 失败: %v\n", err)
+		}
+		for kind, items := range old.Categories {
+			for _, it := range items {
+				firstSeen[kind+"\x00"+it.Target] = it.FirstSeen
+			}
+		}
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	data := ProblemsData{RunID: runID, Updated: now, Total: total, SuccessCount: successCount, Categories: make(map[string][]ProblemItem)}
+	for kind, lines := range problems {
+		if len(lines) == 0 {
+			continue
+		}
+		items := make([]ProblemItem, 0, len(lines))
+		for _, l := range lines {
+			it := splitProblem(l)
+			it.FirstSeen = now
+			if prev, ok := firstSeen[kind+"\x00"+it.Target]; ok && prev != "" {
+				it.FirstSeen = prev
+			}
+			items = append(items, it)
+		}
+		data.Categories[kind] = items
+	}
+
+	jsonBytes, err := marshalJSON(data, cfg.OutputIndent)
+	if err != nil {
+		return outputFile{}, wrapErrorf(err, "problems.json 序列化失败")
+	}
+	return outputFile{Target: target, Data: jsonBytes, CommitMsg: "Update problems.json", SkipUnchanged: true, Volatile: []string{"run_id", "updated"}}, nil
+}
+
+// splitProblem 将 "地址 (详情)" 形式的问题记录拆分为 target 与 detail
+func splitProblem(line string) ProblemItem {
+	if i := strings.Index(line, " ("); i > 0 && strings.HasSuffix(line, ")") {
+		return ProblemItem{Target: line[:i], Detail: line[i+2 : len(line)-1]}
+	}
+	return ProblemItem{Target: line}
+}
@@ -0,0 +1,89 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: profiles.go
+// Description: 多配置方案，在一次运行中依次处理多个朋友圈（如技术圈、同学圈），
+// 每个方案可单独指定 RSS 列表、输出位置与存储目标，抓取缓存等共用
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// activeProfile 当前处理的配置方案名称，为空表示未使用多配置方案
+var activeProfile string
+
+// profileNamePattern 方案名称只能包含字母、数字与下划线，以便拼接为环境变量名
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// profileEnvKey 返回配置方案中覆盖 key 的环境变量名，如方案 tech 的 RSS 为 PROFILE_TECH_RSS
+func profileEnvKey(profile, key string) string {
+	return "PROFILE_" + strings.ToUpper(profile) + "_" + key
+}
+
+// lookupConfigEnv 读取配置项对应的环境变量
+//
+// Description:
+//
+//	处理配置方案时优先使用方案中的同名配置（PROFILE_<方案>_<KEY>），未设置时使用全局的 <KEY>
+//
+// Returns:
+//   - string : 环境变量的值，未设置时为空
+//   - string : 来源：profile（方案配置）或 env（全局配置）
+func lookupConfigEnv(key string) (string, string) {
+	if activeProfile != "" {
+		if v := os.Getenv(profileEnvKey(activeProfile, key)); v != "" {
+			return v, sourceProfile
+		}
+	}
+	return os.Getenv(key), sourceEnv
+}
+
+// configProfiles 解析 PROFILES 中的方案名称，未设置时返回 nil
+func configProfiles() ([]string, error) {
+	names := splitList(os.Getenv("PROFILES"), ",")
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !profileNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("PROFILES 中的方案名称无效: %s (只能包含字母、数字与下划线)", name)
+		}
+		if seen[strings.ToUpper(name)] {
+			return nil, fmt.Errorf("PROFILES 中的方案名称重复: %s", name)
+		}
+		seen[strings.ToUpper(name)] = true
+	}
+	return names, nil
+}
+
+// runProfiles 执行抓取流程，设置了 PROFILES 时依次处理每个配置方案
+//
+// Description:
+//
+//	各方案在同一进程中先后运行，未单独配置的项沿用全局配置：
+//	抓取缓存（FETCH_CACHE）默认共用，后一个方案可复用前一个方案的头像、ETag 等缓存；
+//	日志同样写入共用的日志文件，每个方案的日志以方案名称开头。单个方案失败不影响其他方案
+//
+// Returns:
+//   - int : 所有方案中最大的退出码
+func runProfiles(ctx context.Context, runOpts runOptions) int {
+	profiles, err := configProfiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		return 2
+	}
+	if len(profiles) == 0 {
+		return runPipeline(ctx, runOpts)
+	}
+	defer func() { activeProfile = "" }()
+
+	exitCode := 0
+	for _, name := range profiles {
+		activeProfile = name
+		fmt.Printf("[INFO] ===== 配置方案: %s =====\n", name)
+		exitCode = max(exitCode, runPipeline(ctx, runOpts))
+	}
+	return exitCode
+}
@@ -0,0 +1,107 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: quarantine.go
+// Description: 订阅隔离列表（QUARANTINE_AFTER）：连续多次抓取失败的订阅暂停抓取，
+//   每隔 QUARANTINE_RETRY_HOURS 试探一次，成功后自动解除；记录保存在抓取缓存中
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// errQuarantined 订阅处于隔离中，本次未抓取
+var errQuarantined = errors.New("feed quarantined")
+
+// quarantineRecord 单个订阅的连续失败记录
+type quarantineRecord struct {
+	Failures  int       `json:"failures"`            // 连续抓取失败的运行次数
+	Reason    string    `json:"reason,omitempty"`    // 最近一次失败的原因
+	Since     time.Time `json:"since,omitzero"`      // 进入隔离的时间，尚未隔离时为零值
+	LastTried time.Time `json:"last_tried,omitzero"` // 最近一次实际抓取的时间，隔离期间据此决定何时试探
+}
+
+// splitQuarantined 将隔离中且未到试探时间的订阅从待抓取列表中分出
+//
+// Description:
+//
+//	隔离中的订阅距上次抓取超过 retry 时照常抓取（试探），成功后由 updateQuarantine 解除隔离
+//
+// Returns:
+//   - []feedEntry : 本次需要抓取的订阅
+//   - []feedEntry : 本次跳过的隔离中订阅
+func (c *fetchCache) splitQuarantined(feeds []feedEntry, retry time.Duration, now time.Time) ([]feedEntry, []feedEntry) {
+	if c == nil {
+		return feeds, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var fetch, skipped []feedEntry
+	for _, e := range feeds {
+		rec, ok := c.Quarantine[e.URL]
+		if ok && !rec.Since.IsZero() && now.Sub(rec.LastTried) < retry {
+			skipped = append(skipped, e)
+			continue
+		}
+		fetch = append(fetch, e)
+	}
+	return fetch, skipped
+}
+
+// quarantinedResults 为跳过的隔离中订阅生成抓取结果，按抓取失败处理（增量合并时保留旧文章）
+func quarantinedResults(skipped []feedEntry) []feedResult {
+	results := make([]feedResult, 0, len(skipped))
+	for _, e := range skipped {
+		results = append(results, feedResult{FeedLink: e.URL, Name: e.Name, Note: e.Note, Err: errQuarantined})
+	}
+	return results
+}
+
+// updateQuarantine 按本次抓取结果更新隔离列表，并将隔离中的订阅记入 problems["quarantined"]
+//
+// Description:
+//
+//	抓取成功（含没有文章的订阅）时删除记录，隔离中的订阅随之解除；
+//	抓取失败时连续失败次数加一，达到 after 次时进入隔离；本次跳过的订阅不改变记录
+func (c *fetchCache) updateQuarantine(results []feedResult, after int, problems map[string][]string, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, r := range results {
+		rec, ok := c.Quarantine[r.FeedLink]
+		switch {
+		case r.Err == nil:
+			if ok && !rec.Since.IsZero() {
+				fmt.Printf("[INFO] %s 抓取成功, 解除隔离\n", r.FeedLink)
+			}
+			delete(c.Quarantine, r.FeedLink)
+		case errors.Is(r.Err, errQuarantined):
+		default:
+			rec.Failures++
+			rec.Reason = r.Err.Error()
+			rec.LastTried = now
+			if rec.Since.IsZero() && rec.Failures >= after {
+				rec.Since = now
+			}
+			if c.Quarantine == nil {
+				c.Quarantine = make(map[string]quarantineRecord)
+			}
+			c.Quarantine[r.FeedLink] = rec
+		}
+	}
+
+	var items []string
+	for link, rec := range c.Quarantine {
+		if !rec.Since.IsZero() {
+			items = append(items, trf("%s (连续 %d 次抓取失败, 隔离自 %s)", link, rec.Failures, rec.Since.Format("2006-01-02")))
+		}
+	}
+	sort.Strings(items)
+	if len(items) > 0 {
+		problems["quarantined"] = items
+	}
+}
@@ -0,0 +1,56 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: quarantine_test.go
+// Description: 订阅隔离列表的测试
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuarantineCycle(t *testing.T) {
+	const retry = 24 * time.Hour
+	c := &fetchCache{}
+	feeds := []feedEntry{{URL: "https://a/feed"}, {URL: "https://b/feed"}}
+	now := time.Now()
+	run := func(failing bool) (fetched, skipped []feedEntry, problems map[string][]string) {
+		fetched, skipped = c.splitQuarantined(feeds, retry, now)
+		var results []feedResult
+		for _, e := range fetched {
+			r := feedResult{FeedLink: e.URL}
+			if e.URL == "https://b/feed" && failing {
+				r.Err = errors.New("HTTP 500")
+			}
+			results = append(results, r)
+		}
+		problems = make(map[string][]string)
+		c.updateQuarantine(append(results, quarantinedResults(skipped)...), 3, problems, now)
+		return fetched, skipped, problems
+	}
+
+	// 连续失败 3 次后进入隔离
+	for i := 1; i <= 3; i++ {
+		if _, skipped, _ := run(true); len(skipped) != 0 {
+			t.Fatalf("第 %d 次失败前就跳过了 %v", i, skipped)
+		}
+		now = now.Add(30 * time.Minute)
+	}
+	_, skipped, problems := run(true)
+	if len(skipped) != 1 || skipped[0].URL != "https://b/feed" {
+		t.Fatalf("隔离后跳过 = %v, want 只有 b", skipped)
+	}
+	if len(problems["quarantined"]) != 1 {
+		t.Errorf(`problems["quarantined"] = %v, want 1 条`, problems["quarantined"])
+	}
+
+	// 超过试探间隔后再次抓取，成功即解除隔离
+	now = now.Add(retry)
+	if _, skipped, problems := run(false); len(skipped) != 0 || len(problems["quarantined"]) != 0 {
+		t.Fatalf("试探成功后 skipped = %v, quarantined = %v, want 都为空", skipped, problems["quarantined"])
+	}
+	if _, ok := c.Quarantine["https://b/feed"]; ok {
+		t.Error("试探成功后隔离记录未删除")
+	}
+}
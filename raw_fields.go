@@ -0,0 +1,108 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: raw_fields.go
+// Description: 将 gofeed 解析出的原始文章字段（GUID、enclosure、扩展元素等）按 OUTPUT_RAW_FIELDS 原样写入文章的 extra 字段，
+// 供需要 data.json 精简结构之外数据的前端使用
+
+package main
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// rawFieldExtractors 可输出的原始字段及其提取方式，字段为空时返回 nil
+var rawFieldExtractors = map[string]func(item *gofeed.Item) any{
+	"guid": func(item *gofeed.Item) any {
+		return nonEmpty(item.GUID)
+	},
+	"enclosures": func(item *gofeed.Item) any {
+		if len(item.Enclosures) == 0 {
+			return nil
+		}
+		return item.Enclosures
+	},
+	"categories": func(item *gofeed.Item) any {
+		if len(item.Categories) == 0 {
+			return nil
+		}
+		return item.Categories
+	},
+	"image": func(item *gofeed.Item) any {
+		if item.Image == nil {
+			return nil
+		}
+		return item.Image
+	},
+	"authors": func(item *gofeed.Item) any {
+		if len(item.Authors) == 0 {
+			return nil
+		}
+		return item.Authors
+	},
+	"extensions": func(item *gofeed.Item) any {
+		if len(item.Extensions) == 0 {
+			return nil
+		}
+		return item.Extensions
+	},
+	"itunes": func(item *gofeed.Item) any {
+		if item.ITunesExt == nil {
+			return nil
+		}
+		return item.ITunesExt
+	},
+	"dublin_core": func(item *gofeed.Item) any {
+		if item.DublinCoreExt == nil {
+			return nil
+		}
+		return item.DublinCoreExt
+	},
+	"custom": func(item *gofeed.Item) any {
+		// 本程序写入的扩展字段提取结果（lhasa: 前缀）已有对应的文章字段，不重复输出
+		custom := maps.Clone(item.Custom)
+		maps.DeleteFunc(custom, func(k, _ string) bool { return strings.HasPrefix(k, "lhasa:") })
+		if len(custom) == 0 {
+			return nil
+		}
+		return custom
+	},
+}
+
+// nonEmpty 空字符串返回 nil，使其不出现在 extra 中
+func nonEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// validateRawFields 检查 OUTPUT_RAW_FIELDS 中的字段名是否受支持
+func validateRawFields(fields []string) error {
+	for _, f := range fields {
+		if _, ok := rawFieldExtractors[f]; !ok {
+			return fmt.Errorf("OUTPUT_RAW_FIELDS 中的字段无效: %s (可选: guid, enclosures, categories, image, authors, extensions, itunes, dublin_core, custom)", f)
+		}
+	}
+	return nil
+}
+
+// rawItemFields 提取文章的原始字段，没有任何字段时返回 nil（不输出 extra）
+func rawItemFields(item *gofeed.Item, fields []string) map[string]any {
+	var extra map[string]any
+	for _, f := range fields {
+		extract, ok := rawFieldExtractors[f]
+		if !ok {
+			continue
+		}
+		if v := extract(item); v != nil {
+			if extra == nil {
+				extra = make(map[string]any, len(fields))
+			}
+			extra[f] = v
+		}
+	}
+	return extra
+}
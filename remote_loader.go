@@ -0,0 +1,83 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: remote_loader.go
+// Description: 并发加载运行所需的远程配置文件（RSS列表、头像映射等），各自独立超时并汇总错误
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// loadTask 表示一个需要在启动阶段加载的配置文件任务
+type loadTask struct {
+	Name     string                          // 任务名称，用于错误信息
+	Timeout  time.Duration                   // 单个任务的超时时间
+	Required bool                            // 是否为必需任务，必需任务失败时应终止运行
+	Load     func(ctx context.Context) error // 实际加载逻辑
+}
+
+// loadErrors 汇总并发加载中的错误，区分必需任务与可选任务
+type loadErrors struct {
+	Fatal   error // 必需任务的错误汇总
+	Warning error // 可选任务的错误汇总
+}
+
+// runLoadTasks 并发执行所有加载任务，并汇总错误
+//
+// Description:
+//
+//	每个任务使用独立的超时上下文，互不阻塞；全部完成后，
+//	将必需任务与可选任务的错误分别用 errors.Join 汇总返回
+func runLoadTasks(ctx context.Context, tasks []loadTask) loadErrors {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		fatals   []error
+		warnings []error
+	)
+
+	for _, task := range tasks {
+		wg.Add(1)
+		go func(t loadTask) {
+			defer wg.Done()
+			defer recoverPanic(trf("加载 %s", t.Name), func(err error) {
+				mu.Lock()
+				if t.Required {
+					fatals = append(fatals, fmt.Errorf("加载%s失败: %w", t.Name, err))
+				} else {
+					warnings = append(warnings, fmt.Errorf("加载%s失败: %w", t.Name, err))
+				}
+				mu.Unlock()
+			})
+
+			taskCtx, cancel := context.WithTimeout(ctx, t.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := t.Load(taskCtx)
+			if err == nil {
+				fmt.Printf("[INFO] 加载 %s 完成, 耗时 %v\n", t.Name, time.Since(start).Round(time.Millisecond))
+				return
+			}
+
+			err = fmt.Errorf("加载%s失败: %w", t.Name, err)
+			mu.Lock()
+			if t.Required {
+				fatals = append(fatals, err)
+			} else {
+				warnings = append(warnings, err)
+			}
+			mu.Unlock()
+		}(task)
+	}
+	wg.Wait()
+
+	return loadErrors{
+		Fatal:   errors.Join(fatals...),
+		Warning: errors.Join(warnings...),
+	}
+}
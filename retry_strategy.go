@@ -0,0 +1,96 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: retry_strategy.go
+// Description: 统一的重试策略（最大尝试次数、初始间隔、增长倍数、随机抖动、总时长上限），
+// 由 Config 集中定义，RSS 抓取与存储读取共用
+
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// retryStrategy 指数退避重试策略
+type retryStrategy struct {
+	MaxAttempts  int           // 最大尝试次数（包含首次尝试），< 1 时按 1 处理
+	BaseInterval time.Duration // 首次重试前的等待时间
+	Multiplier   float64       // 每次重试等待时间的增长倍数，< 1 时按 1 处理
+	Jitter       float64       // 等待时间的随机浮动比例（0~1），如 0.2 表示在 ±20% 内浮动，避免大量请求同时重试
+	MaxElapsed   time.Duration // 从首次尝试开始的总时长上限，下一次等待会超过上限时不再重试，0 表示不限制
+}
+
+// attempts 返回生效的最大尝试次数
+func (s retryStrategy) attempts() int {
+	return max(s.MaxAttempts, 1)
+}
+
+// delay 返回第 n 次尝试（从 1 开始）失败后、下一次尝试前的等待时间
+func (s retryStrategy) delay(n int) time.Duration {
+	wait := float64(s.BaseInterval) * math.Pow(max(s.Multiplier, 1), float64(n-1))
+	if s.Jitter > 0 {
+		wait *= 1 + s.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(wait)
+}
+
+// allows 判断已经过 elapsed 后，再等待 wait 是否仍在总时长上限内
+func (s retryStrategy) allows(elapsed, wait time.Duration) bool {
+	return s.MaxElapsed <= 0 || elapsed+wait <= s.MaxElapsed
+}
+
+// permanentError 不应重试的错误
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// noRetry 标记错误不可重试，withRetry 遇到后立即返回原错误
+func noRetry(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// withRetry 按重试策略执行 fn，直到成功、返回不可重试的错误、达到最大尝试次数或超过总时长上限
+//
+// Parameters:
+//   - ctx     : 等待期间 ctx 结束时立即返回 ctx.Err()
+//   - s       : 重试策略
+//   - fn      : 每次尝试执行的操作，参数为尝试序号（从 1 开始），返回 noRetry(err) 表示不再重试
+//   - onRetry : 每次失败且将要重试时调用（可为 nil），用于记录日志
+//
+// Returns:
+//   - error : 成功时为 nil，否则为最后一次尝试的错误（已去掉 noRetry 标记）
+func withRetry(ctx context.Context, s retryStrategy, fn func(attempt int) error, onRetry func(attempt int, err error)) error {
+	start := time.Now()
+	var err error
+	for n := 1; n <= s.attempts(); n++ {
+		if err = fn(n); err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if n == s.attempts() {
+			break
+		}
+		wait := s.delay(n)
+		if !s.allows(time.Since(start), wait) {
+			break
+		}
+		if onRetry != nil {
+			onRetry(n, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return err
+}
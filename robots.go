@@ -0,0 +1,205 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: robots.go
+// Description: robots.txt 解析与检查，抓取博客主页（如解析头像）前按需遵守站点的 Disallow 规则
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// robotsAgent 匹配 robots.txt 中 User-agent 时使用的爬虫名称，与请求头中的 User-Agent 一致
+const robotsAgent = "rssfetcher"
+
+// robotsMaxBytes robots.txt 最多读取的字节数（RFC 9309 要求至少解析 500 KiB）
+const robotsMaxBytes = 500 << 10
+
+// robotsRule 单条 Allow/Disallow 规则
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsRules 某个站点适用于本程序的规则，disallowAll 表示 robots.txt 不可访问时全部禁止
+type robotsRules struct {
+	rules       []robotsRule
+	disallowAll bool
+}
+
+// robotsChecker 按站点缓存 robots.txt 规则
+//
+// Description:
+//
+//	每个站点（scheme://host）在一次运行中只请求一次 robots.txt；
+//	nil 表示不遵守 robots.txt，所有方法对 nil 接收者安全
+type robotsChecker struct {
+	mu    sync.Mutex
+	sites map[string]*robotsRules
+}
+
+// newRobotsChecker 创建 robots.txt 检查器，enabled 为 false 时返回 nil
+func newRobotsChecker(enabled bool) *robotsChecker {
+	if !enabled {
+		return nil
+	}
+	return &robotsChecker{sites: make(map[string]*robotsRules)}
+}
+
+// allowed 判断是否允许抓取指定页面
+//
+// Description:
+//
+//	按 RFC 9309 处理：robots.txt 返回 4xx 时视为全部允许；返回 5xx 或无法访问时视为全部禁止
+func (rc *robotsChecker) allowed(ctx context.Context, pageURL string) bool {
+	if rc == nil {
+		return true
+	}
+	u, err := url.Parse(pageURL)
+	if err != nil || u.Host == "" {
+		return true
+	}
+	site := u.Scheme + "://" + u.Host
+
+	rc.mu.Lock()
+	rules, ok := rc.sites[site]
+	rc.mu.Unlock()
+	if !ok {
+		rules = fetchRobots(ctx, site)
+		rc.mu.Lock()
+		rc.sites[site] = rules
+		rc.mu.Unlock()
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return rules.allows(path)
+}
+
+// fetchRobots 下载并解析站点的 robots.txt
+func fetchRobots(ctx context.Context, site string) *robotsRules {
+	req, err := http.NewRequestWithContext(ctx, "GET", site+"/robots.txt", nil)
+	if err != nil {
+		return &robotsRules{}
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+	resp, err := pageClient.Do(req)
+	if err != nil {
+		return &robotsRules{disallowAll: true}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return &robotsRules{disallowAll: true}
+	case resp.StatusCode != http.StatusOK:
+		return &robotsRules{}
+	}
+	return parseRobots(io.LimitReader(resp.Body, robotsMaxBytes), robotsAgent)
+}
+
+// parseRobots 解析 robots.txt，返回适用于 agent 的规则
+//
+// Description:
+//
+//	连续的 User-agent 行组成一组，组内的 Allow/Disallow 规则适用于这些爬虫；
+//	存在名称匹配 agent 的组时只使用这些组，否则使用 "*" 组
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	var specific, wildcard []robotsRule
+	var hasSpecific bool
+	var groupAgents []string
+	inRules := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				groupAgents = nil
+				inRules = false
+			}
+			groupAgents = append(groupAgents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				// 空的 Disallow 表示不限制
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value}
+			for _, a := range groupAgents {
+				switch {
+				case a == "*":
+					wildcard = append(wildcard, rule)
+				case strings.Contains(agent, a):
+					hasSpecific = true
+					specific = append(specific, rule)
+				}
+			}
+		}
+	}
+	if hasSpecific {
+		return &robotsRules{rules: specific}
+	}
+	return &robotsRules{rules: wildcard}
+}
+
+// allows 判断路径是否允许抓取：匹配最长的规则生效，长度相同时 Allow 优先
+func (rr *robotsRules) allows(path string) bool {
+	if rr.disallowAll {
+		return false
+	}
+	best, allow := -1, true
+	for _, rule := range rr.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || (n == best && rule.allow) {
+			best, allow = n, rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsMatch 判断路径是否匹配规则，支持 "*" 通配符和结尾的 "$"
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		if i == len(parts)-2 && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return !anchored || rest == ""
+}
@@ -0,0 +1,56 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: rss_list_auth.go
+// Description: 读取需要认证的远程 RSS 列表（私有 COS 存储桶、Basic Auth 或 Bearer Token 保护的地址），
+// 认证信息由 RSS_AUTH 配置，只用于读取 RSS 列表
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// rssListAuthModes RSS_AUTH 可选的认证方式
+var rssListAuthModes = []string{"none", "basic", "bearer", "cos"}
+
+// authHeaderTransport 为每个请求设置认证请求头
+type authHeaderTransport struct {
+	header string
+	value  string
+}
+
+// RoundTrip 复制请求后设置请求头，再交给当前的 http.DefaultTransport（录制、回放时同样生效）
+func (t authHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(t.header, t.value)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// rssListClient 返回读取远程 RSS 列表使用的 HTTP 客户端
+//
+// Description:
+//
+//	none  : 匿名请求（默认）
+//	basic : 使用 RSS_AUTH_USERNAME、RSS_AUTH_PASSWORD 进行 Basic Auth
+//	bearer: 以 Authorization: Bearer RSS_AUTH_TOKEN 请求
+//	cos   : 使用 TENCENT_CLOUD_SECRET_ID/KEY 为请求签名，可读取私有存储桶中的列表
+//	认证方式对所有 HTTP(S) 形式的 RSS 列表生效，条件请求（ETag）照常使用
+func rssListClient(cfg *Config) (*http.Client, error) {
+	switch cfg.RssAuth {
+	case "", "none":
+		return http.DefaultClient, nil
+	case "basic":
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.SetBasicAuth(cfg.RssAuthUsername, cfg.RssAuthPassword)
+		return &http.Client{Transport: authHeaderTransport{header: "Authorization", value: req.Header.Get("Authorization")}}, nil
+	case "bearer":
+		return &http.Client{Transport: authHeaderTransport{header: "Authorization", value: "Bearer " + cfg.RssAuthToken}}, nil
+	case "cos":
+		// Transport 留空时 SDK 使用 http.DefaultTransport
+		return &http.Client{Transport: &cos.AuthorizationTransport{SecretID: cfg.TencentSecretID, SecretKey: cfg.TencentSecretKey}}, nil
+	default:
+		return nil, fmt.Errorf("RSS_AUTH 值无效: %s (只能是 none、basic、bearer 或 cos)", cfg.RssAuth)
+	}
+}
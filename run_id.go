@@ -0,0 +1,29 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: run_id.go
+// Description: 每次运行的唯一标识，用于在日志、运行摘要、problems.json、提交信息和通知之间关联同一次运行
+
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// runID 本次运行的唯一标识
+//
+// Description:
+//
+//	可通过 RUN_ID 环境变量指定（如 GitHub Actions 的 run id），未设置时随机生成 UUID v4
+var runID = envWithDefault("RUN_ID", newRunID())
+
+// newRunID 生成 UUID v4，随机数不可用时退化为基于时间的标识
+func newRunID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("run-%d", time.Now().UnixNano())
+	}
+	b[6] = b[6]&0x0f | 0x40 // 版本 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 变体
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
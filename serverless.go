@@ -0,0 +1,141 @@
+//go:build serverless
+
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: serverless.go
+// Description: 云函数入口（腾讯云 SCF / AWS Lambda 的自定义运行时），由定时触发器调用完整的抓取流程，
+// 以 go build -tags serverless 编译
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	serverlessMain = runServerless
+}
+
+// Handler 云函数的处理函数，每次调用执行一次完整的抓取流程
+//
+// Description:
+//
+//	配置从函数的环境变量读取（与 GitHub Actions 中的环境变量相同），触发事件的内容不影响抓取；
+//	同一实例被多次调用时，每次调用都会重新读取配置并生成新的运行ID（除非设置了 RUN_ID）
+//
+// Returns:
+//   - string : 执行结果说明
+//   - error  : 抓取流程以非零状态码结束时返回错误，便于在云函数控制台中告警
+func Handler(ctx context.Context, event json.RawMessage) (string, error) {
+	runID = envWithDefault("RUN_ID", newRunID())
+	if code := runProfiles(ctx, runOptions{}); code != 0 {
+		return "", fmt.Errorf("抓取流程失败, 运行ID: %s, 退出码: %d", runID, code)
+	}
+	return fmt.Sprintf("抓取完成, 运行ID: %s", runID), nil
+}
+
+// serverlessRuntime 自定义运行时接口的地址与路径
+type serverlessRuntime struct {
+	base      string // 运行时接口地址
+	ready     string // 初始化完成通知路径，为空表示不需要
+	next      string // 获取下一次调用的路径
+	response  string // 返回结果的路径，%s 为请求ID（SCF 不需要）
+	fail      string // 返回错误的路径，%s 为请求ID（SCF 不需要）
+	requestID string // 响应头中请求ID的名称
+}
+
+// detectRuntime 根据环境变量识别当前运行的云函数平台
+func detectRuntime() (serverlessRuntime, error) {
+	if api := os.Getenv("AWS_LAMBDA_RUNTIME_API"); api != "" {
+		return serverlessRuntime{
+			base:      "http://" + api + "/2018-06-01",
+			next:      "/runtime/invocation/next",
+			response:  "/runtime/invocation/%s/response",
+			fail:      "/runtime/invocation/%s/error",
+			requestID: "Lambda-Runtime-Aws-Request-Id",
+		}, nil
+	}
+	if api := os.Getenv("SCF_RUNTIME_API"); api != "" {
+		return serverlessRuntime{
+			base:     "http://" + api + ":" + envWithDefault("SCF_RUNTIME_API_PORT", "9001"),
+			ready:    "/runtime/init/ready",
+			next:     "/runtime/invocation/next",
+			response: "/runtime/invocation/response",
+			fail:     "/runtime/invocation/error",
+		}, nil
+	}
+	return serverlessRuntime{}, fmt.Errorf("未检测到云函数运行时 (AWS_LAMBDA_RUNTIME_API 或 SCF_RUNTIME_API)")
+}
+
+// runServerless 自定义运行时的主循环：获取调用事件、执行 Handler、返回结果
+func runServerless() {
+	rt, err := detectRuntime()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		os.Exit(1)
+	}
+	// 获取下一次调用是长轮询，不设置超时
+	client := &http.Client{}
+	if rt.ready != "" {
+		if err := rt.post(client, rt.ready, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] 通知运行时初始化完成失败: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	for {
+		resp, err := client.Get(rt.base + rt.next)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] 获取调用事件失败: %v\n", err)
+			time.Sleep(time.Second)
+			continue
+		}
+		event, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		requestID := ""
+		if rt.requestID != "" {
+			requestID = resp.Header.Get(rt.requestID)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		if ms, err := strconv.ParseInt(resp.Header.Get("Lambda-Runtime-Deadline-Ms"), 10, 64); err == nil {
+			cancel()
+			ctx, cancel = context.WithDeadline(context.Background(), time.UnixMilli(ms))
+		}
+		result, err := Handler(ctx, event)
+		cancel()
+
+		if err != nil {
+			body, _ := json.Marshal(map[string]string{"errorMessage": err.Error(), "errorType": "PipelineError"})
+			if perr := rt.post(client, strings.Replace(rt.fail, "%s", requestID, 1), body); perr != nil {
+				fmt.Fprintf(os.Stderr, "[ERROR] 返回调用错误失败: %v\n", perr)
+			}
+			continue
+		}
+		body, _ := json.Marshal(result)
+		if perr := rt.post(client, strings.Replace(rt.response, "%s", requestID, 1), body); perr != nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] 返回调用结果失败: %v\n", perr)
+		}
+	}
+}
+
+// post 向运行时接口发送结果
+func (rt serverlessRuntime) post(client *http.Client, path string, body []byte) error {
+	resp, err := client.Post(rt.base+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP状态码: %d", resp.StatusCode)
+	}
+	return nil
+}
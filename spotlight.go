@@ -0,0 +1,116 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: spotlight.go
+// Description: 每日推荐博客 spotlight.json，按日期作为随机种子、按订阅权重每天选出一个博客及其最新文章
+
+package main
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+)
+
+// SpotlightData 用于输出 spotlight.json
+type SpotlightData struct {
+	Date     string  `json:"date"`               // 推荐日期，如 2025-06-01
+	BlogName string  `json:"blog_name"`          // 博客名称
+	Avatar   string  `json:"avatar"`             // 博客头像
+	Homepage string  `json:"homepage,omitempty"` // 博客主页（本次未抓取成功时为空）
+	Feed     string  `json:"feed"`               // RSS 地址
+	Group    string  `json:"group,omitempty"`    // 博客分组
+	Articles int     `json:"articles"`           // 该博客在 data.json 中的文章数
+	Latest   Article `json:"latest"`             // 该博客的最新文章
+}
+
+// spotlightOutput 生成待上传的 spotlight.json
+//
+// Description:
+//
+//	候选为 data.json 中的每个博客（已失效或已退役的文章不参与），以当天日期（按 TZ 所在时区）的哈希为随机种子，
+//	按RSS列表中的 weight 加权抽取一个，weight 小于 1 时按 1 计算；同一天内多次运行结果相同，内容未变化时跳过上传
+//
+// Parameters:
+//   - articles : 最终输出的文章列表（已按发布时间倒序）
+//   - entries  : RSS列表中的订阅，用于读取权重
+//   - results  : 本次抓取结果，用于补充博客主页
+//   - now      : 当前时间
+//
+// Returns:
+//   - outputFile : 待上传的 spotlight.json
+//   - bool       : 是否有可推荐的博客，为 false 时不输出
+//   - error      : 序列化失败时返回错误
+func spotlightOutput(cfg *Config, articles []Article, entries []feedEntry, results []feedResult, now time.Time) (outputFile, bool, error) {
+	weights := make(map[string]int, len(entries))
+	for _, e := range entries {
+		weights[urlnorm.Key(e.URL)] = e.Weight
+	}
+	homepages := make(map[string]string, len(results))
+	for _, r := range results {
+		homepages[urlnorm.Key(r.FeedLink)] = r.Homepage
+	}
+
+	// 每个博客取最新一篇文章，并统计文章数
+	latest := make(map[string]Article)
+	counts := make(map[string]int)
+	for _, a := range articles {
+		if a.Dead || a.Retired || a.Archived || a.Feed == "" {
+			continue
+		}
+		key := urlnorm.Key(a.Feed)
+		counts[key]++
+		if _, ok := latest[key]; !ok {
+			latest[key] = a
+		}
+	}
+	if len(latest) == 0 {
+		return outputFile{}, false, nil
+	}
+	keys := make([]string, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	date := now.Format("2006-01-02")
+	h := fnv.New64a()
+	h.Write([]byte(date))
+	rng := rand.New(rand.NewPCG(h.Sum64(), 0))
+
+	total := 0
+	for _, key := range keys {
+		total += max(weights[key], 1)
+	}
+	pick := rng.IntN(total)
+	chosen := keys[len(keys)-1]
+	for _, key := range keys {
+		if pick -= max(weights[key], 1); pick < 0 {
+			chosen = key
+			break
+		}
+	}
+
+	a := latest[chosen]
+	data := SpotlightData{
+		Date:     date,
+		BlogName: a.BlogName,
+		Avatar:   a.Avatar,
+		Homepage: homepages[chosen],
+		Feed:     a.Feed,
+		Group:    a.Group,
+		Articles: counts[chosen],
+		Latest:   a,
+	}
+	jsonBytes, err := marshalJSON(data, cfg.OutputIndent)
+	if err != nil {
+		return outputFile{}, false, wrapErrorf(err, "spotlight.json 序列化失败")
+	}
+	return outputFile{
+		Target:        siblingPath(cfg.DataURL, "spotlight.json"),
+		Data:          jsonBytes,
+		CommitMsg:     "Update spotlight.json",
+		SkipUnchanged: true,
+	}, true, nil
+}
@@ -0,0 +1,90 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: stats_markdown.go
+// Description: 在仓库内指定文件的标记注释之间自动更新 Markdown 统计表（订阅数、成功率、最新文章）
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	statsStartMarker = "<!-- lhasaRSS:stats:start -->"
+	statsEndMarker   = "<!-- lhasaRSS:stats:end -->"
+)
+
+// renderStatsMarkdown 生成统计表 Markdown
+func renderStatsMarkdown(total, successCount int, articles []Article, recent int) string {
+	var sb strings.Builder
+	rate := 0.0
+	if total > 0 {
+		rate = float64(successCount) * 100 / float64(total)
+	}
+	sb.WriteString("| 订阅数 | 成功抓取 | 成功率 |\n")
+	sb.WriteString("|:---:|:---:|:---:|\n")
+	sb.WriteString(fmt.Sprintf("| %d | %d | %.1f%% |\n", total, successCount, rate))
+
+	if recent > len(articles) {
+		recent = len(articles)
+	}
+	if recent > 0 {
+		sb.WriteString("\n**最新文章**\n\n")
+		for _, a := range articles[:recent] {
+			sb.WriteString(fmt.Sprintf("- [%s](%s) — %s · %s\n", escapeMarkdown(a.Title), a.Link, escapeMarkdown(a.BlogName), a.Published))
+		}
+	}
+	return sb.String()
+}
+
+// escapeMarkdown 转义会破坏链接或表格的 Markdown 字符
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]", "|", "\\|", "\n", " ").Replace(s)
+}
+
+// replaceBetweenMarkers 用 block 替换 content 中两个标记之间的内容，未找到标记时返回 false
+func replaceBetweenMarkers(content, block string) (string, bool) {
+	start := strings.Index(content, statsStartMarker)
+	if start < 0 {
+		return content, false
+	}
+	bodyStart := start + len(statsStartMarker)
+	end := strings.Index(content[bodyStart:], statsEndMarker)
+	if end < 0 {
+		return content, false
+	}
+	end += bodyStart
+	return content[:bodyStart] + "\n" + block + content[end:], true
+}
+
+// updateStatsMarkdown 更新 STATS_FILE 中标记之间的统计表，内容无变化时不提交
+//
+// Description:
+//
+//	目标文件位于 GitHub 仓库中（与 SAVE_TARGET 无关），文件中需预先写好开始和结束标记:
+//	  <!-- lhasaRSS:stats:start -->
+//	  <!-- lhasaRSS:stats:end -->
+func updateStatsMarkdown(ctx context.Context, cfg *Config, total, successCount int, articles []Article) error {
+	target := repoPath(cfg.StatsFile)
+	content, sha, err := getGitHubFileContent(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, target)
+	if err != nil {
+		return wrapErrorf(err, "获取 %s 失败", target)
+	}
+	if sha == "" {
+		return fmt.Errorf("统计表目标文件不存在: %s", target)
+	}
+
+	updated, ok := replaceBetweenMarkers(content, renderStatsMarkdown(total, successCount, articles, cfg.StatsRecent))
+	if !ok {
+		return fmt.Errorf("%s 中未找到统计表标记 %s / %s", target, statsStartMarker, statsEndMarker)
+	}
+	if updated == content {
+		return nil
+	}
+	if err := putGitHubFile(ctx, cfg.GitHubToken, cfg.GitHubName, cfg.GitHubRepo, target, sha,
+		updated, "Update stats in "+target, cfg.commitSignature()); err != nil {
+		return wrapErrorf(err, "更新 %s 失败", target)
+	}
+	return nil
+}
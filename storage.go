@@ -0,0 +1,299 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: storage.go
+// Description: 按 SAVE_TARGET 读写 data.json 及其同目录下的其他输出文件（blogs.json 等）
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// siblingPath 生成与 data.json 位于同一目录的文件地址
+//
+// Description:
+//
+//	dataURL 为 HTTP(S) 地址（COS）时替换 URL 路径的文件名部分，否则视为 GitHub 仓库内路径（或 KV 中的键）
+//	例如 https://x.cos.../lhasaRSS/data.json + blogs.json => https://x.cos.../lhasaRSS/blogs.json
+func siblingPath(dataURL, name string) string {
+	if isRemoteURL(dataURL) {
+		u, err := url.Parse(dataURL)
+		if err != nil {
+			return dataURL
+		}
+		u.Path = path.Join(path.Dir(u.Path), name)
+		return u.String()
+	}
+	return repoPath(path.Dir(repoPath(dataURL)), name)
+}
+
+// 读取已保存文件时的错误类别
+//
+// Description:
+//
+//	与文件不存在（返回 nil, nil）区分开，调用方据此降级：无权限或暂时不可用时跳过与旧数据的比较，
+//	而不是把本次运行当作失败
+var (
+	ErrStorageForbidden = errors.New("storage read forbidden")          // 凭证无读取权限（如只写的子账号）
+	ErrStorageTransient = errors.New("storage temporarily unavailable") // 网络错误或服务端错误，重试后仍失败
+)
+
+// isGzipTarget 保存地址以 .gz 结尾时，内容以 gzip 压缩后保存，读取时自动解压
+//
+// Description:
+//
+//	用于在 GitHub 中保存体积较大的输出（GitHub 单个文件不能超过 100MB），JSON 通常可压缩到原来的 1/5 以下
+func isGzipTarget(target string) bool {
+	return strings.HasSuffix(strings.ToLower(target), ".gz")
+}
+
+// readStoredFile 从 SAVE_TARGET 对应的存储读取文件内容，文件不存在时返回 nil, nil
+func readStoredFile(ctx context.Context, cfg *Config, target string) ([]byte, error) {
+	data, err := readStoredRaw(ctx, cfg, target)
+	if err != nil || len(data) == 0 || !isGzipTarget(target) {
+		return data, err
+	}
+	decoded, err := decodeContent("gzip", data)
+	if err != nil {
+		return nil, wrapErrorf(err, "解压 %s 失败", target)
+	}
+	return decoded, nil
+}
+
+// readStoredRaw 读取存储中的原始内容，不做解压
+func readStoredRaw(ctx context.Context, cfg *Config, target string) ([]byte, error) {
+	store, err := newStorage(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return store.Read(ctx, target)
+}
+
+// saveStoredFile 将 JSON 文件保存到 SAVE_TARGET 对应的存储，.gz 结尾的地址先以 gzip 压缩
+func saveStoredFile(ctx context.Context, cfg *Config, target string, data []byte, commitMsg string) error {
+	if isGzipTarget(target) {
+		compressed, err := gzipBytes(data)
+		if err != nil {
+			return wrapErrorf(err, "gzip压缩失败")
+		}
+		data = compressed
+	}
+	store, err := newStorage(cfg)
+	if err != nil {
+		return err
+	}
+	return store.Write(ctx, target, data, commitMsg)
+}
+
+// Storage 输出文件的存储后端
+//
+// Description:
+//
+//	data.json 及同目录的其他输出文件都通过 Storage 读写，GitHub、COS、KV 的 API 调用各自封装在实现中，
+//	gzip 压缩与"内容未变化时跳过"等逻辑由 readStoredFile/saveStoredFile 统一处理，新增后端只需实现本接口
+type Storage interface {
+	// Read 读取文件的原始内容，文件不存在时返回 nil, nil
+	Read(ctx context.Context, target string) ([]byte, error)
+	// Write 覆盖保存文件，commitMsg 仅对会产生提交的后端（GitHub）有效
+	Write(ctx context.Context, target string, data []byte, commitMsg string) error
+	// String 返回便于日志输出的后端名称
+	String() string
+}
+
+// newStorage 根据 SAVE_TARGET 创建存储后端
+func newStorage(cfg *Config) (Storage, error) {
+	switch cfg.SaveTarget {
+	case "GITHUB":
+		return githubStorage{cfg: cfg}, nil
+	case "COS":
+		return cosStorage{cfg: cfg}, nil
+	case "KV":
+		return kvStorage{cfg: cfg}, nil
+	case "MEMORY":
+		return sharedMemoryStorage, nil
+	default:
+		return nil, fmt.Errorf("SAVE_TARGET 值无效: %s (只能是 'GITHUB'、'COS'、'KV' 或 'MEMORY')", cfg.SaveTarget)
+	}
+}
+
+// githubStorage 保存到 GitHub 仓库（NAME/REPOSITORY），每次写入对应一次提交
+type githubStorage struct {
+	cfg *Config
+}
+
+func (s githubStorage) Read(ctx context.Context, target string) ([]byte, error) {
+	content, _, err := getGitHubFileContent(ctx, s.cfg.GitHubToken, s.cfg.GitHubName, s.cfg.GitHubRepo, target)
+	if err != nil {
+		return nil, wrapErrorf(err, "从 GitHub 获取 %s 失败", target)
+	}
+	if content == "" {
+		return nil, nil
+	}
+	return []byte(content), nil
+}
+
+func (s githubStorage) Write(ctx context.Context, target string, data []byte, commitMsg string) error {
+	sha, err := getGitHubFileSHA(ctx, s.cfg.GitHubToken, s.cfg.GitHubName, s.cfg.GitHubRepo, target)
+	if err != nil {
+		return wrapErrorf(err, "获取 %s 文件SHA失败", target)
+	}
+	if err := putGitHubFile(ctx, s.cfg.GitHubToken, s.cfg.GitHubName, s.cfg.GitHubRepo, target, sha,
+		string(data), commitMsg, s.cfg.commitSignature()); err != nil {
+		return wrapErrorf(err, "上传 %s 到 GitHub 失败", target)
+	}
+	return nil
+}
+
+func (s githubStorage) String() string {
+	return "github:" + s.cfg.GitHubName + "/" + s.cfg.GitHubRepo
+}
+
+// cosStorage 保存到腾讯云 COS，target 为对象的完整地址
+type cosStorage struct {
+	cfg *Config
+}
+
+func (s cosStorage) Read(ctx context.Context, target string) ([]byte, error) {
+	data, err := readCosObject(ctx, s.cfg.TencentSecretID, s.cfg.TencentSecretKey, target, s.cfg.Retry)
+	if err != nil {
+		return nil, wrapErrorf(err, "从 COS 获取 %s 失败", target)
+	}
+	return data, nil
+}
+
+// Write 上传对象；开启 COS_GZIP 时另外上传 gzip 版本（地址加 .gz，Content-Encoding: gzip），
+// 原对象保持未压缩，不支持 gzip 的客户端和读取旧数据的逻辑不受影响
+func (s cosStorage) Write(ctx context.Context, target string, data []byte, commitMsg string) error {
+	opts := cosUploadOptions{
+		ContentType:  storedContentType(target),
+		CacheControl: s.cfg.CosCacheControl,
+	}
+	if err := uploadToCos(ctx, s.cfg.TencentSecretID, s.cfg.TencentSecretKey, target, data, opts); err != nil {
+		return err
+	}
+	if s.cfg.CosGzip && !isGzipTarget(target) {
+		opts.Gzip = true
+		if err := uploadToCos(ctx, s.cfg.TencentSecretID, s.cfg.TencentSecretKey, target+".gz", data, opts); err != nil {
+			return wrapErrorf(err, "上传 %s 的 gzip 版本失败", target)
+		}
+	}
+	return nil
+}
+
+func (s cosStorage) String() string {
+	return "cos"
+}
+
+// kvStorage 保存到 Cloudflare Workers KV，target 为键名
+type kvStorage struct {
+	cfg *Config
+}
+
+func (s kvStorage) Read(ctx context.Context, target string) ([]byte, error) {
+	data, err := getKVValue(ctx, s.cfg, target)
+	if err != nil {
+		return nil, wrapErrorf(err, "从 Cloudflare KV 获取 %s 失败", target)
+	}
+	return data, nil
+}
+
+func (s kvStorage) Write(ctx context.Context, target string, data []byte, commitMsg string) error {
+	if err := putKVValue(ctx, s.cfg, target, data); err != nil {
+		return wrapErrorf(err, "上传 %s 到 Cloudflare KV 失败", target)
+	}
+	return nil
+}
+
+func (s kvStorage) String() string {
+	return "kv:" + s.cfg.CFKVNamespaceID
+}
+
+// gzipBytes 以 gzip 压缩数据，不写入文件名与修改时间，相同内容的压缩结果保持一致
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// storedContentType 按扩展名返回上传到 COS 时的 Content-Type，输出文件默认为 JSON
+func storedContentType(target string) string {
+	switch {
+	case isGzipTarget(target):
+		return "application/gzip"
+	case strings.HasSuffix(target, ".xml"):
+		return "application/rss+xml; charset=utf-8"
+	case strings.HasSuffix(target, ".js"), strings.HasSuffix(target, ".jsonp"):
+		return "application/javascript; charset=utf-8"
+	case strings.HasSuffix(target, ".txt"):
+		return "text/plain; charset=utf-8"
+	case strings.HasSuffix(target, ".opml"):
+		return "text/x-opml; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// saveStoredFileIfChanged 仅在内容与已保存的文件不同时才保存，避免产生无意义的提交
+//
+// Description:
+//
+//	volatile 非空时按 JSON 比较，忽略其中列出的字段（任意层级），
+//	这样只有 updated、run_id 这类每次运行都会刷新的字段变化时不会再产生提交
+func saveStoredFileIfChanged(ctx context.Context, cfg *Config, target string, data []byte, commitMsg string, volatile ...string) error {
+	old, err := readStoredFile(ctx, cfg, target)
+	if err == nil && sameContent(old, data, volatile) {
+		return nil
+	}
+	return saveStoredFile(ctx, cfg, target, data, commitMsg)
+}
+
+// sameContent 判断两份文件内容是否相同，volatile 非空且两边都是合法 JSON 时忽略这些字段后再比较
+func sameContent(old, data []byte, volatile []string) bool {
+	if bytes.Equal(bytes.TrimSpace(old), bytes.TrimSpace(data)) {
+		return true
+	}
+	if len(volatile) == 0 {
+		return false
+	}
+	var a, b any
+	if json.Unmarshal(old, &a) != nil || json.Unmarshal(data, &b) != nil {
+		return false
+	}
+	skip := make(map[string]bool, len(volatile))
+	for _, k := range volatile {
+		skip[k] = true
+	}
+	return reflect.DeepEqual(dropJSONKeys(a, skip), dropJSONKeys(b, skip))
+}
+
+// dropJSONKeys 递归删除 JSON 值中名称在 skip 中的对象字段
+func dropJSONKeys(v any, skip map[string]bool) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if skip[k] {
+				delete(t, k)
+				continue
+			}
+			t[k] = dropJSONKeys(child, skip)
+		}
+	case []any:
+		for i, child := range t {
+			t[i] = dropJSONKeys(child, skip)
+		}
+	}
+	return v
+}
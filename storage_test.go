@@ -0,0 +1,31 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: storage_test.go
+// Description: 输出文件内容比较（忽略每次运行都会刷新的字段）的测试
+
+package main
+
+import "testing"
+
+func TestSameContentIgnoresVolatileFields(t *testing.T) {
+	volatile := []string{"run_id", "updated", "checked_at"}
+	old := []byte(`{"run_id":"a","updated":"2026-01-01 00:00:00","items":[{"feed":"x","checked_at":"2026-01-01 00:00:00","dead_runs":0}]}`)
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"只有易变字段变化", `{"run_id":"b","updated":"2026-01-02 00:00:00","items":[{"feed":"x","checked_at":"2026-01-02 00:00:00","dead_runs":0}]}`, true},
+		{"格式不同", "{\n  \"items\": [{\"dead_runs\": 0, \"feed\": \"x\"}]\n}", true},
+		{"内容变化", `{"run_id":"b","updated":"2026-01-02 00:00:00","items":[{"feed":"x","checked_at":"2026-01-02 00:00:00","dead_runs":1}]}`, false},
+		{"新增条目", `{"items":[{"feed":"x","dead_runs":0},{"feed":"y"}]}`, false},
+		{"不是 JSON", `not json`, false},
+	}
+	for _, tt := range tests {
+		if got := sameContent(old, []byte(tt.data), volatile); got != tt.want {
+			t.Errorf("%s: sameContent = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if sameContent(old, []byte(`{"run_id":"b","updated":"x","items":[{"feed":"x","checked_at":"y","dead_runs":0}]}`), nil) {
+		t.Error("未指定 volatile 时应按字节比较")
+	}
+}
@@ -0,0 +1,61 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: summarize.go
+// Description: 可选的文章摘要增强，调用大模型为新文章生成一句话中文摘要，并按文章缓存避免重复生成
+
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// summaryPrompt 生成摘要的系统提示词
+const summaryPrompt = "你是一名博客编辑。请根据给出的文章标题和正文摘录，用一句简洁的中文概括文章内容，不超过60个字，不要添加引号或额外说明。"
+
+// enrichSummaries 为没有摘要的文章生成一句话摘要
+//
+// Description:
+//
+//	摘要按文章链接缓存：先从旧 data.json 和抓取缓存中查找，只有从未生成过摘要的新文章才会调用大模型，
+//	每次运行最多调用 maxPerRun 次，失败的文章留待下次运行
+func enrichSummaries(ctx context.Context, cfg *Config, articles []Article, previous []Article, cache *fetchCache) {
+	prevSummary := make(map[string]string, len(previous))
+	for _, p := range previous {
+		if p.Summary != "" {
+			prevSummary[p.Link] = p.Summary
+		}
+	}
+
+	var pending []int
+	for i := range articles {
+		a := &articles[i]
+		if s, ok := prevSummary[a.Link]; ok {
+			a.Summary = s
+			continue
+		}
+		if s, ok := cache.enrichment("summary", a.Link); ok {
+			a.Summary = s
+			continue
+		}
+		if len(pending) < cfg.LLMMaxPerRun {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	forEachLimited(pending, enrichConcurrency, func(idx int) {
+		a := &articles[idx]
+		defer recoverPanic(trf("生成摘要 %s", a.Link), nil)
+
+		input := fmt.Sprintf("标题: %s\n正文摘录: %s", a.Title, a.content)
+		summary, err := chatCompletion(ctx, cfg, summaryPrompt, input)
+		if err != nil {
+			fmt.Printf("[WARN] 生成摘要失败: %s: %v\n", a.Link, err)
+			return
+		}
+		a.Summary = summary
+		cache.setEnrichment("summary", a.Link, summary)
+	})
+}
@@ -0,0 +1,191 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: tencentcloud_api.go
+// Description: 腾讯云 API 3.0 通用调用（TC3-HMAC-SHA256 签名）以及 CDN 缓存刷新
+// Technical documentation:
+// 签名方法 v3: https://cloud.tencent.com/document/api/228/30977
+// 刷新 URL: https://cloud.tencent.com/document/api/228/37870
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tencentCloudRequest 调用腾讯云 API 3.0 接口，返回 Response 字段的原始 JSON
+//
+// Description:
+//
+//	使用 TC3-HMAC-SHA256 对请求签名，以 POST + JSON 的方式调用指定服务的 Action，
+//	若接口返回 Response.Error 则转换为错误返回
+//
+// Parameters:
+//   - service : 服务名，如 cdn、tmt
+//   - action  : 接口名，如 PurgeUrlsCache
+//   - version : 接口版本，如 2018-06-06
+//   - region  : 地域，不需要地域的接口传空字符串
+//   - payload : 请求参数，会被序列化为 JSON
+//
+// Returns:
+//   - json.RawMessage: Response 字段内容
+//   - error          : 请求或签名失败、接口返回错误时返回
+func tencentCloudRequest(ctx context.Context, secretID, secretKey, service, action, version, region string, payload interface{}) (json.RawMessage, error) {
+	host := service + ".tencentcloudapi.com"
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	date := now.Format("2006-01-02")
+	contentType := "application/json; charset=utf-8"
+
+	// 1. 拼接规范请求串
+	canonicalHeaders := "content-type:" + contentType + "\n" + "host:" + host + "\n"
+	signedHeaders := "content-type;host"
+	canonicalRequest := strings.Join([]string{
+		"POST",
+		"/",
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	// 2. 拼接待签名字符串
+	credentialScope := date + "/" + service + "/tc3_request"
+	stringToSign := "TC3-HMAC-SHA256\n" + timestamp + "\n" + credentialScope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	// 3. 计算签名
+	secretDate := hmacSHA256([]byte("TC3"+secretKey), date)
+	secretService := hmacSHA256(secretDate, service)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	authorization := fmt.Sprintf("TC3-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		secretID, credentialScope, signedHeaders, signature)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://"+host, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Host", host)
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Timestamp", timestamp)
+	req.Header.Set("X-TC-Version", version)
+	if region != "" {
+		req.Header.Set("X-TC-Region", region)
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tencent cloud %s.%s failed, status: %d, body: %s",
+			service, action, resp.StatusCode, string(respBytes))
+	}
+
+	var result struct {
+		Response json.RawMessage `json:"Response"`
+	}
+	if err := json.Unmarshal(respBytes, &result); err != nil {
+		return nil, err
+	}
+	var apiErr struct {
+		Error *struct {
+			Code    string `json:"Code"`
+			Message string `json:"Message"`
+		} `json:"Error"`
+		RequestID string `json:"RequestId"`
+	}
+	if err := json.Unmarshal(result.Response, &apiErr); err != nil {
+		return nil, err
+	}
+	if apiErr.Error != nil {
+		return nil, fmt.Errorf("tencent cloud %s.%s error: %s %s (RequestId: %s)",
+			service, action, apiErr.Error.Code, apiErr.Error.Message, apiErr.RequestID)
+	}
+	return result.Response, nil
+}
+
+// purgeCDNCache 调用腾讯云 CDN 的 PurgeUrlsCache 接口刷新指定 URL 的缓存
+//
+// Description:
+//
+//	上传 COS 后调用本函数，让访客立即看到最新数据，而不用等待 CDN 缓存过期
+//	返回刷新任务的 TaskId，便于在日志中追踪
+func purgeCDNCache(ctx context.Context, secretID, secretKey string, urls []string) (string, error) {
+	if len(urls) == 0 {
+		return "", nil
+	}
+	resp, err := tencentCloudRequest(ctx, secretID, secretKey, "cdn", "PurgeUrlsCache", "2018-06-06", "",
+		map[string]interface{}{"Urls": urls})
+	if err != nil {
+		return "", wrapErrorf(err, "刷新CDN缓存失败: %v", urls)
+	}
+	var result struct {
+		TaskID string `json:"TaskId"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", wrapErrorf(err, "解析CDN刷新结果失败")
+	}
+	return result.TaskID, nil
+}
+
+// cdnURLFor 将 COS 对象地址转换为 CDN 加速域名下的访问地址
+//
+// Description:
+//
+//	若未配置 CDN 域名，则原样返回 COS 地址；否则仅替换主机名，保留路径
+func cdnURLFor(cosURL, cdnDomain string) string {
+	if cdnDomain == "" {
+		return cosURL
+	}
+	u, err := url.Parse(cosURL)
+	if err != nil {
+		return cosURL
+	}
+	if strings.Contains(cdnDomain, "://") {
+		if d, err := url.Parse(cdnDomain); err == nil {
+			u.Scheme, u.Host = d.Scheme, d.Host
+		}
+	} else {
+		u.Scheme, u.Host = "https", cdnDomain
+	}
+	return u.String()
+}
+
+// sha256Hex 计算数据的 SHA256 并返回十六进制字符串
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 计算 HMAC-SHA256
+func hmacSHA256(key []byte, msg string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
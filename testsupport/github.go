@@ -0,0 +1,209 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: testsupport/github.go
+// Description: 测试用的假 GitHub API，以 http.RoundTripper 的形式实现仓库信息与 contents API（读取、目录列表、创建/更新、删除），
+//   文件保存在内存中；替换 http.DefaultTransport 后，日志写入、仓库内 RSS 列表等 GitHub 操作都不再访问网络
+
+package testsupport
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// GitHub 假 GitHub API
+//
+// Description:
+//
+//	只处理 api.github.com 的请求，不区分仓库（所有仓库共用同一组文件）；
+//	其他主机中回环地址（httptest 服务器）的请求交给 Base 发送，其余请求直接返回错误，保证测试不会访问外网
+type GitHub struct {
+	// Base 发送回环地址请求的 RoundTripper，NewGitHub 中为新建的 http.Transport（不能是被替换的 http.DefaultTransport）
+	Base http.RoundTripper
+	// Push 仓库信息中的 permissions.push，默认 true
+	Push bool
+
+	mu      sync.Mutex
+	files   map[string][]byte
+	commits []string
+}
+
+// NewGitHub 创建一个空仓库的假 GitHub API
+func NewGitHub() *GitHub {
+	return &GitHub{Base: &http.Transport{}, Push: true, files: make(map[string][]byte)}
+}
+
+// Put 预置仓库内文件（如 data/rss.txt），不计入提交记录
+func (g *GitHub) Put(filePath string, content []byte) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.files[strings.TrimPrefix(filePath, "/")] = append([]byte(nil), content...)
+}
+
+// File 返回仓库内文件的内容
+func (g *GitHub) File(filePath string) ([]byte, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	data, ok := g.files[strings.TrimPrefix(filePath, "/")]
+	return append([]byte(nil), data...), ok
+}
+
+// Commits 返回通过 contents API 创建、更新或删除过的文件路径，按提交顺序排列
+func (g *GitHub) Commits() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.commits...)
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (g *GitHub) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != "api.github.com" {
+		host := req.URL.Hostname()
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return nil, fmt.Errorf("testsupport: unexpected request to %s", req.URL)
+		}
+		return g.Base.RoundTrip(req)
+	}
+
+	// /repos/{owner}/{repo}[/contents/{path}]
+	parts := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/"), "/", 5)
+	switch {
+	case len(parts) == 3 && parts[0] == "repos" && req.Method == http.MethodGet:
+		return jsonResponse(req, http.StatusOK, map[string]any{
+			"full_name":   parts[1] + "/" + parts[2],
+			"permissions": map[string]bool{"push": g.Push},
+		}), nil
+	case len(parts) >= 4 && parts[0] == "repos" && parts[3] == "contents":
+		filePath := ""
+		if len(parts) == 5 {
+			filePath = strings.Trim(parts[4], "/")
+		}
+		return g.contents(req, filePath)
+	}
+	return jsonResponse(req, http.StatusNotFound, map[string]string{"message": "Not Found"}), nil
+}
+
+// contents 处理 contents API 的读取、目录列表、创建/更新与删除
+func (g *GitHub) contents(req *http.Request, filePath string) (*http.Response, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch req.Method {
+	case http.MethodGet:
+		if data, ok := g.files[filePath]; ok {
+			if strings.Contains(req.Header.Get("Accept"), "raw") {
+				return rawResponse(req, http.StatusOK, data), nil
+			}
+			return jsonResponse(req, http.StatusOK, map[string]any{
+				"name":     path.Base(filePath),
+				"path":     filePath,
+				"sha":      blobSHA(data),
+				"size":     len(data),
+				"type":     "file",
+				"encoding": "base64",
+				"content":  base64.StdEncoding.EncodeToString(data),
+			}), nil
+		}
+		if entries := g.list(filePath); len(entries) > 0 {
+			return jsonResponse(req, http.StatusOK, entries), nil
+		}
+		return jsonResponse(req, http.StatusNotFound, map[string]string{"message": "Not Found"}), nil
+
+	case http.MethodPut, http.MethodDelete:
+		var payload struct {
+			Message string `json:"message"`
+			Content string `json:"content"`
+			SHA     string `json:"sha"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			return jsonResponse(req, http.StatusBadRequest, map[string]string{"message": err.Error()}), nil
+		}
+		current, exists := g.files[filePath]
+		// 与 GitHub 一致：更新、删除已有文件时必须提供当前的 SHA
+		if exists && payload.SHA != blobSHA(current) || !exists && req.Method == http.MethodDelete {
+			return jsonResponse(req, http.StatusConflict, map[string]string{"message": "sha does not match"}), nil
+		}
+		g.commits = append(g.commits, filePath)
+		if req.Method == http.MethodDelete {
+			delete(g.files, filePath)
+			return jsonResponse(req, http.StatusOK, map[string]any{"commit": map[string]string{"message": payload.Message}}), nil
+		}
+		data, err := base64.StdEncoding.DecodeString(payload.Content)
+		if err != nil {
+			return jsonResponse(req, http.StatusBadRequest, map[string]string{"message": err.Error()}), nil
+		}
+		g.files[filePath] = data
+		status := http.StatusCreated
+		if exists {
+			status = http.StatusOK
+		}
+		return jsonResponse(req, status, map[string]any{"content": map[string]string{"path": filePath, "sha": blobSHA(data)}}), nil
+	}
+	return jsonResponse(req, http.StatusMethodNotAllowed, map[string]string{"message": "Method Not Allowed"}), nil
+}
+
+// list 返回目录下的直接子项，调用方需持有锁
+func (g *GitHub) list(dir string) []map[string]any {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	seen := make(map[string]bool)
+	var entries []map[string]any
+	for p, data := range g.files {
+		if !strings.HasPrefix(p, prefix) {
+			continue
+		}
+		name, _, isDir := strings.Cut(strings.TrimPrefix(p, prefix), "/")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		entry := map[string]any{"name": name, "path": prefix + name, "type": "file", "sha": blobSHA(data), "size": len(data)}
+		if isDir {
+			entry = map[string]any{"name": name, "path": prefix + name, "type": "dir", "sha": "", "size": 0}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i]["name"].(string) < entries[j]["name"].(string) })
+	return entries
+}
+
+// blobSHA 按 git blob 的方式计算内容的 SHA
+func blobSHA(data []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(data))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func jsonResponse(req *http.Request, status int, v any) *http.Response {
+	body, _ := json.Marshal(v)
+	resp := rawResponse(req, status, body)
+	resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return resp
+}
+
+func rawResponse(req *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,95 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: testsupport/storage.go
+// Description: 测试用的假存储，实现与 main 包中 Storage 接口相同的方法，可按地址注入读写错误并记录每次写入
+
+// Package testsupport 提供集成测试使用的假存储与假 GitHub API，测试无需网络与任何凭证即可完整运行抓取流程
+package testsupport
+
+import (
+	"context"
+	"sync"
+)
+
+// Storage 保存在内存中的假存储
+//
+// Description:
+//
+//	方法集与 main 包的 Storage 接口一致（Read/Write/String），可直接替换 SAVE_TARGET=MEMORY 使用的实例；
+//	ReadErr/WriteErr 返回非 nil 时对应的读写失败，用于模拟存储不可用、上传失败等情况
+type Storage struct {
+	// ReadErr 读取前调用，返回非 nil 时读取失败，nil 表示不注入错误
+	ReadErr func(target string) error
+	// WriteErr 写入前调用，返回非 nil 时写入失败且不保存内容，nil 表示不注入错误
+	WriteErr func(target string) error
+
+	mu     sync.Mutex
+	files  map[string][]byte
+	writes []string
+}
+
+// NewStorage 创建一个空的假存储
+func NewStorage() *Storage {
+	return &Storage{files: make(map[string][]byte)}
+}
+
+// Read 返回已保存内容的副本，未保存过时返回 nil, nil（与文件不存在相同）
+func (s *Storage) Read(ctx context.Context, target string) ([]byte, error) {
+	if s.ReadErr != nil {
+		if err := s.ReadErr(target); err != nil {
+			return nil, err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[target]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Write 保存内容的副本并记录写入的地址，注入的错误同样记录，便于断言尝试过哪些写入
+func (s *Storage) Write(ctx context.Context, target string, data []byte, commitMsg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes = append(s.writes, target)
+	if s.WriteErr != nil {
+		if err := s.WriteErr(target); err != nil {
+			return err
+		}
+	}
+	s.files[target] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *Storage) String() string {
+	return "fake"
+}
+
+// Put 预置文件内容（如上次运行生成的 data.json），不计入写入记录
+func (s *Storage) Put(target string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[target] = append([]byte(nil), data...)
+}
+
+// File 返回已保存的内容
+func (s *Storage) File(target string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[target]
+	return append([]byte(nil), data...), ok
+}
+
+// Writes 返回指定地址被写入（含失败的尝试）的次数
+func (s *Storage) Writes(target string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, w := range s.writes {
+		if w == target {
+			n++
+		}
+	}
+	return n
+}
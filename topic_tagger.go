@@ -0,0 +1,164 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: topic_tagger.go
+// Description: 文章主题分类，按关键词规则或调用大模型为每篇文章打上主题标签（技术/生活/摄影等）
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// TopicRule 主题关键词规则，文章标题或正文摘录包含任一关键词即归入该主题
+type TopicRule struct {
+	Topic    string   `json:"topic"`    // 主题名称
+	Keywords []string `json:"keywords"` // 关键词（不区分大小写）
+}
+
+// TopicRulesData 主题规则文件的数据结构
+type TopicRulesData struct {
+	Items []TopicRule `json:"items"`
+}
+
+// defaultTopicRules 未配置 TOPIC_RULES 时使用的内置规则，按顺序匹配
+var defaultTopicRules = []TopicRule{
+	{Topic: "技术", Keywords: []string{"golang", "go语言", "python", "java", "linux", "docker", "kubernetes", "前端", "后端", "数据库", "服务器", "编程", "代码", "算法", "开发", "hexo", "hugo", "jekyll", "nginx", "git"}},
+	{Topic: "摄影", Keywords: []string{"摄影", "相机", "镜头", "胶片", "扫街", "照片", "photo"}},
+	{Topic: "读书", Keywords: []string{"读书", "书评", "阅读", "读后感", "书单"}},
+	{Topic: "旅行", Keywords: []string{"旅行", "旅游", "游记", "骑行", "徒步", "自驾"}},
+	{Topic: "生活", Keywords: []string{"生活", "日记", "周记", "月记", "年终总结", "随笔", "碎碎念"}},
+}
+
+// TopicTagger 主题分类器
+type TopicTagger struct {
+	rules  []TopicRule
+	config *Config
+	cache  *fetchCache
+}
+
+// NewTopicTagger 创建主题分类器，默认使用内置规则
+func NewTopicTagger(config *Config, cache *fetchCache) *TopicTagger {
+	return &TopicTagger{rules: defaultTopicRules, config: config, cache: cache}
+}
+
+// LoadTopicRules 从远程URL或本地文件加载主题规则
+//
+// Description:
+//
+//	仅在 TOPIC_TAGGING=rules 且配置了 TOPIC_RULES 时加载，加载成功后替换内置规则
+func (tt *TopicTagger) LoadTopicRules(ctx context.Context) error {
+	src := tt.config.TopicRulesURL
+	if tt.config.TopicTagging != "rules" || src == "" {
+		return nil
+	}
+
+	var body []byte
+	var err error
+	if isRemoteURL(src) {
+		client := &http.Client{Timeout: 30 * time.Second}
+		body, _, err = tt.cache.conditionalGet(ctx, client, src)
+	} else {
+		body, err = os.ReadFile(src)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch topic rules: %w", err)
+	}
+
+	var data TopicRulesData
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("failed to parse topic rules JSON: %w", err)
+	}
+	if len(data.Items) > 0 {
+		tt.rules = data.Items
+	}
+	fmt.Printf("[INFO] 成功加载 %d 条主题规则\n", len(tt.rules))
+	return nil
+}
+
+// TagArticles 为文章打上主题标签
+//
+// Description:
+//
+//	rules 模式按关键词规则匹配，未命中任何规则的文章不打标签；
+//	llm 模式调用大模型从 TOPIC_LIST 中选择一个主题，结果按文章链接缓存，
+//	旧 data.json 中已有标签的文章不会重复调用
+func (tt *TopicTagger) TagArticles(ctx context.Context, articles []Article, previous []Article) {
+	switch tt.config.TopicTagging {
+	case "rules":
+		for i := range articles {
+			articles[i].Topic = tt.matchRules(articles[i].Title + " " + articles[i].content)
+		}
+	case "llm":
+		tt.tagWithLLM(ctx, articles, previous)
+	}
+}
+
+// matchRules 返回第一个命中关键词的主题
+func (tt *TopicTagger) matchRules(text string) string {
+	text = strings.ToLower(text)
+	for _, rule := range tt.rules {
+		for _, kw := range rule.Keywords {
+			if kw != "" && strings.Contains(text, strings.ToLower(kw)) {
+				return rule.Topic
+			}
+		}
+	}
+	return ""
+}
+
+// tagWithLLM 调用大模型为未分类的文章选择主题
+func (tt *TopicTagger) tagWithLLM(ctx context.Context, articles []Article, previous []Article) {
+	prevTopic := make(map[string]string, len(previous))
+	for _, p := range previous {
+		if p.Topic != "" {
+			prevTopic[p.Link] = p.Topic
+		}
+	}
+
+	topics := tt.config.TopicList
+	allowed := make(map[string]bool, len(topics))
+	for _, t := range topics {
+		allowed[t] = true
+	}
+	prompt := fmt.Sprintf("你是一名博客编辑。请从以下主题中为文章选择最合适的一个，只回复主题名称本身: %s", strings.Join(topics, "、"))
+
+	var pending []int
+	for i := range articles {
+		a := &articles[i]
+		if t, ok := prevTopic[a.Link]; ok {
+			a.Topic = t
+			continue
+		}
+		if t, ok := tt.cache.enrichment("topic", a.Link); ok {
+			a.Topic = t
+			continue
+		}
+		if len(pending) < tt.config.LLMMaxPerRun {
+			pending = append(pending, i)
+		}
+	}
+
+	forEachLimited(pending, enrichConcurrency, func(idx int) {
+		a := &articles[idx]
+		defer recoverPanic(trf("文章分类 %s", a.Link), nil)
+
+		input := fmt.Sprintf("标题: %s\n正文摘录: %s", a.Title, a.content)
+		reply, err := chatCompletion(ctx, tt.config, prompt, input)
+		if err != nil {
+			fmt.Printf("[WARN] 文章主题分类失败: %s: %v\n", a.Link, err)
+			return
+		}
+		topic := strings.Trim(reply, " 。.\"'“”")
+		if !allowed[topic] {
+			fmt.Printf("[WARN] 大模型返回了未知主题 %q, 已忽略: %s\n", reply, a.Link)
+			return
+		}
+		a.Topic = topic
+		tt.cache.setEnrichment("topic", a.Link, topic)
+	})
+}
@@ -0,0 +1,187 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: translator.go
+// Description: 文章标题翻译，将非中文标题翻译为目标语言，支持 DeepL、OpenAI 兼容接口与腾讯云机器翻译
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Translator 翻译服务提供方
+type Translator interface {
+	// Translate 将 text 翻译为 target 语言（如 zh、en、ja）
+	Translate(ctx context.Context, text, target string) (string, error)
+}
+
+// newTranslator 根据 TRANSLATE_PROVIDER 创建翻译服务，未开启时返回 nil
+func newTranslator(cfg *Config) (Translator, error) {
+	switch cfg.TranslateProvider {
+	case "", "off":
+		return nil, nil
+	case "deepl":
+		return &deeplTranslator{apiKey: cfg.DeepLAPIKey}, nil
+	case "openai":
+		return &llmTranslator{cfg: cfg}, nil
+	case "tencent":
+		return &tmtTranslator{secretID: cfg.TencentSecretID, secretKey: cfg.TencentSecretKey, region: cfg.TMTRegion}, nil
+	default:
+		return nil, fmt.Errorf("TRANSLATE_PROVIDER 值无效: %s (只能是 'deepl'、'openai' 或 'tencent')", cfg.TranslateProvider)
+	}
+}
+
+// deeplTranslator DeepL 翻译，免费版 Key（以 :fx 结尾）自动使用 api-free 域名
+type deeplTranslator struct {
+	apiKey string
+}
+
+func (t *deeplTranslator) Translate(ctx context.Context, text, target string) (string, error) {
+	endpoint := "https://api.deepl.com/v2/translate"
+	if strings.HasSuffix(t.apiKey, ":fx") {
+		endpoint = "https://api-free.deepl.com/v2/translate"
+	}
+	form := url.Values{"text": {text}, "target_lang": {strings.ToUpper(target)}}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+t.apiKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("deepl translate failed, status: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if len(result.Translations) == 0 {
+		return "", fmt.Errorf("deepl translate returned no result")
+	}
+	return result.Translations[0].Text, nil
+}
+
+// llmTranslator 使用 OpenAI 兼容接口翻译
+type llmTranslator struct {
+	cfg *Config
+}
+
+func (t *llmTranslator) Translate(ctx context.Context, text, target string) (string, error) {
+	prompt := fmt.Sprintf("请将用户给出的文章标题翻译为语言代码 %s 对应的语言，只回复译文，不要添加引号或解释。", target)
+	return chatCompletion(ctx, t.cfg, prompt, text)
+}
+
+// tmtTranslator 腾讯云机器翻译（TMT）TextTranslate 接口
+type tmtTranslator struct {
+	secretID  string
+	secretKey string
+	region    string
+}
+
+func (t *tmtTranslator) Translate(ctx context.Context, text, target string) (string, error) {
+	resp, err := tencentCloudRequest(ctx, t.secretID, t.secretKey, "tmt", "TextTranslate", "2018-03-21", t.region,
+		map[string]interface{}{"SourceText": text, "Source": "auto", "Target": target, "ProjectId": 0})
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		TargetText string `json:"TargetText"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", wrapErrorf(err, "解析翻译结果失败")
+	}
+	return result.TargetText, nil
+}
+
+// needsTranslation 判断标题是否需要翻译
+//
+// Description:
+//
+//	日文标题同样含有汉字，因此先检查平假名、片假名：含假名的标题视为日文
+//	目标语言为中文时，日文标题与不含汉字的标题需要翻译；目标语言为日文时，不含假名的中文标题需要翻译；
+//	其他目标语言时，含汉字或假名的标题需要翻译
+func needsTranslation(title, target string) bool {
+	hasLetter, hasHan, hasKana := false, false, false
+	for _, r := range title {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			hasKana = true
+		case unicode.Is(unicode.Han, r):
+			hasHan = true
+		case unicode.IsLetter(r):
+			hasLetter = true
+		}
+	}
+	target = strings.ToLower(target)
+	switch {
+	case strings.HasPrefix(target, "zh"):
+		return hasKana || hasLetter && !hasHan
+	case strings.HasPrefix(target, "ja"):
+		return hasHan && !hasKana
+	}
+	return hasHan || hasKana
+}
+
+// translateTitles 为需要翻译的文章标题生成 translated_title
+//
+// Description:
+//
+//	译文按 "目标语言:文章链接" 缓存，旧 data.json 中标题未变的文章直接复用上次的译文，
+//	每次运行最多调用 LLMMaxPerRun 次翻译服务
+func translateTitles(ctx context.Context, cfg *Config, translator Translator, articles []Article, previous []Article, cache *fetchCache) {
+	prev := make(map[string]Article, len(previous))
+	for _, p := range previous {
+		prev[p.Link] = p
+	}
+	kind := "translate-" + cfg.TranslateTarget
+
+	var pending []int
+	for i := range articles {
+		a := &articles[i]
+		if !needsTranslation(a.Title, cfg.TranslateTarget) {
+			continue
+		}
+		if p, ok := prev[a.Link]; ok && p.Title == a.Title && p.TranslatedTitle != "" {
+			a.TranslatedTitle = p.TranslatedTitle
+			continue
+		}
+		if t, ok := cache.enrichment(kind, a.Link+"#"+a.Title); ok {
+			a.TranslatedTitle = t
+			continue
+		}
+		if len(pending) < cfg.LLMMaxPerRun {
+			pending = append(pending, i)
+		}
+	}
+
+	forEachLimited(pending, enrichConcurrency, func(idx int) {
+		a := &articles[idx]
+		defer recoverPanic(trf("翻译标题 %s", a.Link), nil)
+
+		translated, err := translator.Translate(ctx, a.Title, cfg.TranslateTarget)
+		if err != nil {
+			fmt.Printf("[WARN] 标题翻译失败: %s: %v\n", a.Link, err)
+			return
+		}
+		a.TranslatedTitle = strings.TrimSpace(translated)
+		cache.setEnrichment(kind, a.Link+"#"+a.Title, a.TranslatedTitle)
+	})
+}
@@ -0,0 +1,31 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: translator_test.go
+// Description: 标题是否需要翻译的判断测试，覆盖含汉字的日文标题
+
+package main
+
+import "testing"
+
+func TestNeedsTranslation(t *testing.T) {
+	tests := []struct {
+		title, target string
+		want          bool
+	}{
+		{"Hello World", "zh", true},
+		{"你好世界", "zh", false},
+		{"用 Go 写一个 RSS 聚合器", "zh", false},
+		{"東京で食べたラーメン", "zh", true},
+		{"ひらがなだけ", "zh-CN", true},
+		{"2024", "zh", false},
+		{"東京で食べたラーメン", "ja", false},
+		{"北京的秋天", "ja", true},
+		{"北京的秋天", "en", true},
+		{"東京で食べたラーメン", "en", true},
+		{"Hello World", "en", false},
+	}
+	for _, tt := range tests {
+		if got := needsTranslation(tt.title, tt.target); got != tt.want {
+			t.Errorf("needsTranslation(%q, %q) = %v, want %v", tt.title, tt.target, got, tt.want)
+		}
+	}
+}
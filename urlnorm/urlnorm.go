@@ -0,0 +1,123 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: urlnorm/urlnorm.go
+// Description: URL 与域名规范化工具，提供规范主机名、可注册域名（eTLD+1）、URL 规范化和去重键，
+//   供头像映射、名称映射、订阅去重和统计共用
+
+// Package urlnorm 提供 URL 与域名的规范化
+package urlnorm
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// CanonicalHost 将 host[:port] 规范化为小写的 ASCII（punycode）形式
+//
+// Description:
+//
+//	中文域名转换为 punycode（如 例子.中国 => xn--fsqu00a.xn--fiqs8s），去掉末尾的点，
+//	去掉默认端口 :80 和 :443，非默认端口保留；无法转换时退回小写原值
+func CanonicalHost(host string) string {
+	hostname, port := strings.TrimSpace(host), ""
+	if h, p, err := net.SplitHostPort(hostname); err == nil {
+		hostname, port = h, p
+	}
+	if port == "80" || port == "443" {
+		port = ""
+	}
+	hostname = strings.TrimSuffix(hostname, ".")
+	if ascii, err := idna.Lookup.ToASCII(hostname); err == nil {
+		hostname = ascii
+	}
+	hostname = strings.ToLower(hostname)
+	if port != "" {
+		return net.JoinHostPort(hostname, port)
+	}
+	return hostname
+}
+
+// Host 从 URL 或裸域名中提取规范主机名，无法解析时返回空字符串
+func Host(rawURL string) string {
+	u := parse(rawURL)
+	if u == nil {
+		return ""
+	}
+	return CanonicalHost(u.Host)
+}
+
+// SiteHost 与 Host 相同，但去掉 www. 前缀，用于判断两个地址是否属于同一站点
+func SiteHost(rawURL string) string {
+	return strings.TrimPrefix(Host(rawURL), "www.")
+}
+
+// RegistrableDomain 返回地址的可注册域名（eTLD+1），如 blog.example.com.cn => example.com.cn
+//
+// Description:
+//
+//	基于公共后缀列表计算，IP 地址、localhost 等无法计算时返回不含端口的主机名
+func RegistrableDomain(rawURL string) string {
+	host := Host(rawURL)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" || net.ParseIP(host) != nil {
+		return host
+	}
+	if d, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return d
+	}
+	return host
+}
+
+// Normalize 返回规范化的 URL
+//
+// Description:
+//
+//	协议小写、主机名规范化、去掉片段（#...），空路径补为 "/"；
+//	路径与查询参数保持原样，避免改变服务端语义
+func Normalize(rawURL string) string {
+	u := parse(rawURL)
+	if u == nil {
+		return strings.TrimSpace(rawURL)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = CanonicalHost(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
+
+// Key 生成用于去重的地址键：忽略协议、主机名大小写、www 前缀、默认端口、末尾斜杠和查询参数的顺序
+func Key(rawURL string) string {
+	u := parse(rawURL)
+	if u == nil {
+		return strings.ToLower(strings.TrimSpace(rawURL))
+	}
+	params := strings.Split(u.RawQuery, "&")
+	sort.Strings(params)
+	return strings.TrimPrefix(CanonicalHost(u.Host), "www.") + strings.TrimSuffix(u.EscapedPath(), "/") + "?" + strings.Join(params, "&")
+}
+
+// parse 解析 URL，缺少协议时按 http:// 补全，无法解析或没有主机名时返回 nil
+func parse(rawURL string) *url.URL {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return nil
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	return u
+}
@@ -0,0 +1,103 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: urlnorm/urlnorm_test.go
+// Description: urlnorm 的表驱动测试，覆盖中文域名、默认端口、末尾斜杠、www 前缀与查询参数顺序
+
+package urlnorm
+
+import "testing"
+
+func TestHost(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"https://Example.COM/path", "example.com"},
+		{"example.com", "example.com"},
+		{"http://example.com:80/", "example.com"},
+		{"https://example.com:443/", "example.com"},
+		{"https://example.com:8443/", "example.com:8443"},
+		{"https://example.com./feed", "example.com"},
+		{"https://例子.中国/feed", "xn--fsqu00a.xn--fiqs8s"},
+		{"https://www.Example.com", "www.example.com"},
+		{"", ""},
+		{"https://", ""},
+	}
+	for _, tt := range tests {
+		if got := Host(tt.in); got != tt.want {
+			t.Errorf("Host(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSiteHost(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"https://www.example.com/", "example.com"},
+		{"https://WWW.Example.com:443", "example.com"},
+		{"https://blog.example.com", "blog.example.com"},
+		{"https://www.例子.中国", "xn--fsqu00a.xn--fiqs8s"},
+	}
+	for _, tt := range tests {
+		if got := SiteHost(tt.in); got != tt.want {
+			t.Errorf("SiteHost(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"https://blog.example.com/feed", "example.com"},
+		{"https://a.b.example.com.cn", "example.com.cn"},
+		{"https://user.github.io/atom.xml", "user.github.io"},
+		{"https://www.cnblogs.com/someone/rss", "cnblogs.com"},
+		{"https://博客.例子.中国", "xn--fsqu00a.xn--fiqs8s"},
+		{"http://127.0.0.1:8080/feed", "127.0.0.1"},
+		{"http://localhost:8080/feed", "localhost"},
+	}
+	for _, tt := range tests {
+		if got := RegistrableDomain(tt.in); got != tt.want {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"HTTPS://Example.com", "https://example.com/"},
+		{"https://example.com:443/a/#top", "https://example.com/a/"},
+		{"http://example.com:8080/a", "http://example.com:8080/a"},
+		{"https://例子.中国/文章", "https://xn--fsqu00a.xn--fiqs8s/%E6%96%87%E7%AB%A0"},
+		// 路径大小写、末尾斜杠与查询参数顺序可能影响服务端语义，保持原样
+		{"https://example.com/Post/?b=2&a=1", "https://example.com/Post/?b=2&a=1"},
+		{"  not a url  ", "not a url"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	same := [][]string{
+		{"https://www.example.com/feed/", "http://example.com/feed", "HTTPS://EXAMPLE.COM:443/feed"},
+		{"https://example.com/feed?a=1&b=2", "https://example.com/feed?b=2&a=1"},
+		{"https://例子.中国/rss", "https://xn--fsqu00a.xn--fiqs8s/rss/"},
+		{"example.com/feed", "https://example.com/feed"},
+	}
+	for _, group := range same {
+		for _, u := range group[1:] {
+			if Key(u) != Key(group[0]) {
+				t.Errorf("Key(%q) = %q, want same as Key(%q) = %q", u, Key(u), group[0], Key(group[0]))
+			}
+		}
+	}
+
+	different := [][2]string{
+		{"https://example.com/feed", "https://example.com/Feed"},
+		{"https://example.com/feed", "https://example.com:8080/feed"},
+		{"https://example.com/feed?a=1", "https://example.com/feed?a=2"},
+		{"https://blog.example.com/feed", "https://example.com/feed"},
+	}
+	for _, pair := range different {
+		if Key(pair[0]) == Key(pair[1]) {
+			t.Errorf("Key(%q) == Key(%q) = %q, want different", pair[0], pair[1], Key(pair[0]))
+		}
+	}
+}
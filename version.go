@@ -0,0 +1,95 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: version.go
+// Description: 构建版本信息（版本号、提交、构建时间），通过 -ldflags 注入，用于 --version、data.json 的 generator 字段与日志
+
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// 构建时通过 -ldflags 注入，例如:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev" // 版本号
+	commit    = ""    // 提交哈希
+	buildDate = ""    // 构建时间（UTC）
+)
+
+// buildInfo 返回版本号、提交与构建时间
+//
+// Description:
+//
+//	未通过 -ldflags 注入提交与构建时间时，退回到 go build 自动记录的 VCS 信息（vcs.revision、vcs.time），
+//	工作区有未提交修改时提交哈希后追加 -dirty
+func buildInfo() (ver, rev, date string) {
+	ver, rev, date = version, commit, buildDate
+	if rev != "" && date != "" {
+		return
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	// 只采用正式的标签版本，伪版本（含时间戳与提交）或带 +dirty 的版本仍显示为 dev
+	if v := info.Main.Version; ver == "dev" && v != "(devel)" && v != "" && !strings.ContainsAny(v, "-+") {
+		ver = v
+	}
+	var vcsRev, vcsTime string
+	dirty := false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			vcsRev = s.Value
+		case "vcs.time":
+			vcsTime = s.Value
+		case "vcs.modified":
+			dirty = s.Value == "true"
+		}
+	}
+	if rev == "" && vcsRev != "" {
+		if len(vcsRev) > 7 {
+			vcsRev = vcsRev[:7]
+		}
+		rev = vcsRev
+		if dirty {
+			rev += "-dirty"
+		}
+	}
+	if date == "" {
+		date = vcsTime
+	}
+	return
+}
+
+// versionString 完整的版本说明，如 "v1.2.0 (commit 1a2b3c4, built 2025-06-01T08:00:00Z)"
+func versionString() string {
+	ver, rev, date := buildInfo()
+	var extra []string
+	if rev != "" {
+		extra = append(extra, "commit "+rev)
+	}
+	if date != "" {
+		extra = append(extra, "built "+date)
+	}
+	if len(extra) == 0 {
+		return ver
+	}
+	return fmt.Sprintf("%s (%s)", ver, strings.Join(extra, ", "))
+}
+
+// generatorName 写入 data.json generator 字段的生成器标识，如 "lhasaRSS v1.2.0+1a2b3c4"
+//
+// Description:
+//
+//	不包含构建时间，同一提交重复构建时输出保持一致，不会因重新编译产生无意义的数据提交
+func generatorName() string {
+	ver, rev, _ := buildInfo()
+	if rev != "" {
+		return "lhasaRSS " + ver + "+" + rev
+	}
+	return "lhasaRSS " + ver
+}
@@ -0,0 +1,62 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: wasm_hook.go
+// Description: WASM 钩子，以 wasm:<路径> 配置钩子时在进程内的 WASI 沙箱中运行模块，与命令钩子一样通过标准输入输出交换 JSON
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// wasmHookPrefix 钩子配置以该前缀开头时视为 WASM 模块路径
+const wasmHookPrefix = "wasm:"
+
+// runWASMHook 在 WASI 沙箱中运行钩子模块，将 input 写入其标准输入，返回标准输出
+//
+// Description:
+//
+//	模块按 WASI 命令（_start）运行，可由 GOOS=wasip1 GOARCH=wasm 的 Go、Rust（wasm32-wasip1）等编译生成；
+//	沙箱内只有标准输入输出、标准错误与环境变量 LHASA_HOOK，不能访问文件系统和网络，
+//	适合运行不便信任的第三方钩子；超过 hookTimeout 时中止模块，退出码非 0 视为失败
+func runWASMHook(ctx context.Context, name, modulePath string, input []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	wasm, err := os.ReadFile(modulePath)
+	if err != nil {
+		return nil, wrapErrorf(err, "读取 %s 钩子模块失败: %s", name, modulePath)
+	}
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+	defer runtime.Close(context.Background())
+	wasi_snapshot_preview1.MustInstantiate(ctx, runtime)
+
+	var stdout bytes.Buffer
+	config := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(modulePath).
+		WithEnv("LHASA_HOOK", name).
+		WithStdin(bytes.NewReader(input)).
+		WithStdout(&stdout).
+		WithStderr(os.Stderr)
+
+	start := time.Now()
+	if _, err := runtime.InstantiateWithConfig(ctx, wasm, config); err != nil {
+		return nil, wrapErrorf(err, "执行 %s 钩子失败: %s", name, modulePath)
+	}
+	fmt.Printf("[INFO] %s 钩子执行完成, 耗时 %v\n", name, time.Since(start).Round(time.Millisecond))
+	return stdout.Bytes(), nil
+}
+
+// wasmHookPath 返回 WASM 钩子的模块路径，command 不是 WASM 钩子时返回 false
+func wasmHookPath(command string) (string, bool) {
+	path, ok := strings.CutPrefix(strings.TrimSpace(command), wasmHookPrefix)
+	return strings.TrimSpace(path), ok
+}
@@ -0,0 +1,89 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: wasm_hook_test.go
+// Description: WASM 钩子的测试，用本机 Go 工具链编译一个 wasip1 模块后通过 runHook 执行
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// buildWASMHook 将 Go 源码编译为 wasip1 模块，返回模块路径
+func buildWASMHook(t *testing.T, src string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("编译 WASM 模块较慢, -short 时跳过")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("未找到 go 工具链")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module hook\n\ngo 1.24\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "hook.wasm")
+	cmd := exec.Command(goBin, "build", "-o", out, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm", "GOFLAGS=")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("编译 WASM 钩子失败: %v\n%s", err, output)
+	}
+	return out
+}
+
+func TestWASMHookPostParse(t *testing.T) {
+	module := buildWASMHook(t, `package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// 过滤掉标题包含 "ad" 的文章，并确认只能访问标准输入输出
+func main() {
+	if os.Getenv("LHASA_HOOK") != "post-parse" {
+		os.Exit(2)
+	}
+	if _, err := os.ReadFile("/etc/hostname"); err == nil {
+		os.Exit(3)
+	}
+	var payload struct {
+		Items []map[string]any `+"`json:\"items\"`"+`
+	}
+	if err := json.NewDecoder(os.Stdin).Decode(&payload); err != nil {
+		os.Exit(4)
+	}
+	kept := payload.Items[:0]
+	for _, it := range payload.Items {
+		if !strings.Contains(it["title"].(string), "ad") {
+			kept = append(kept, it)
+		}
+	}
+	payload.Items = kept
+	json.NewEncoder(os.Stdout).Encode(payload)
+}
+`)
+	articles := []Article{{Title: "hello", Link: "https://a/1"}, {Title: "an ad", Link: "https://a/2"}}
+	articles[0].content = "正文"
+	got, err := runPostParseHook(context.Background(), "wasm:"+module, articles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Title != "hello" || got[0].content != "正文" {
+		t.Fatalf("post-parse 结果 = %+v, want 只保留 hello 并恢复正文摘录", got)
+	}
+
+	failing := buildWASMHook(t, "package main\n\nimport \"os\"\n\nfunc main() { os.Exit(1) }\n")
+	if _, err := runHook(context.Background(), "pre-upload", "wasm:"+failing, nil); err == nil {
+		t.Fatal("退出码非 0 的 WASM 钩子没有返回错误")
+	}
+}
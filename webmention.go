@@ -0,0 +1,371 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: webmention.go
+// Description: 向新收录的文章发送 Webmention（不支持时退回 Pingback），告知作者文章出现在了聚合页中。
+// 参考: https://www.w3.org/TR/webmention/ 、https://www.hixie.ch/specs/pingback/pingback
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+	"golang.org/x/net/html"
+)
+
+const (
+	webmentionMaxAttempts = 3                   // 每篇文章最多尝试的运行次数（每次运行内另按重试策略重试）
+	webmentionTTL         = 30 * 24 * time.Hour // 记录的保留时长，超过后从缓存中清理
+)
+
+// webmentionRecord 单篇文章的通知状态，保存在抓取缓存中
+type webmentionRecord struct {
+	Queued   time.Time `json:"queued"`             // 加入队列的时间
+	SentAt   time.Time `json:"sent_at,omitempty"`  // 发送成功的时间
+	Protocol string    `json:"protocol,omitempty"` // webmention / pingback，文章页面两者均不支持时为 none
+	Attempts int       `json:"attempts,omitempty"` // 已尝试的运行次数
+	Error    string    `json:"error,omitempty"`    // 最近一次失败的原因
+}
+
+// done 是否已无需再处理（已发送、不支持或多次失败）
+func (r webmentionRecord) done() bool {
+	return !r.SentAt.IsZero() || r.Protocol == "none" || r.Attempts >= webmentionMaxAttempts
+}
+
+// queueWebmentions 将新收录的文章加入待发送队列，已记录过的文章跳过，并清理过期记录
+func (c *fetchCache) queueWebmentions(articles []Article, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Webmentions == nil {
+		c.Webmentions = make(map[string]webmentionRecord)
+	}
+	for link, rec := range c.Webmentions {
+		if now.Sub(rec.Queued) > webmentionTTL {
+			delete(c.Webmentions, link)
+		}
+	}
+	for _, a := range articles {
+		if _, ok := c.Webmentions[a.Link]; ok || a.Link == "" {
+			continue
+		}
+		c.Webmentions[a.Link] = webmentionRecord{Queued: now}
+	}
+}
+
+// pendingWebmentions 返回待发送的文章链接，按加入队列的时间排序，最多 limit 个
+func (c *fetchCache) pendingWebmentions(limit int) []string {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var links []string
+	for link, rec := range c.Webmentions {
+		if !rec.done() {
+			links = append(links, link)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		a, b := c.Webmentions[links[i]].Queued, c.Webmentions[links[j]].Queued
+		if a.Equal(b) {
+			return links[i] < links[j]
+		}
+		return a.Before(b)
+	})
+	if limit > 0 && len(links) > limit {
+		links = links[:limit]
+	}
+	return links
+}
+
+// updateWebmention 更新单篇文章的通知状态
+func (c *fetchCache) updateWebmention(link string, update func(*webmentionRecord)) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rec := c.Webmentions[link]
+	update(&rec)
+	c.Webmentions[link] = rec
+}
+
+// sendWebmentions 向队列中的文章发送通知
+//
+// Description:
+//
+//	每篇文章先发现通知端点（Webmention 优先，其次 Pingback），再以聚合页地址为 source、文章地址为 target 发送；
+//	网络错误、429 与 5xx 按重试策略重试，本次运行仍失败的文章留在队列中，最多尝试 webmentionMaxAttempts 次运行；
+//	与聚合页同一站点的文章不发送
+func sendWebmentions(ctx context.Context, cfg *Config, cache *fetchCache) {
+	links := cache.pendingWebmentions(cfg.WebmentionMaxPerRun)
+	if len(links) == 0 {
+		return
+	}
+	sourceHost := urlnorm.SiteHost(cfg.WebmentionSource)
+
+	var sent, failed int
+	var mu sync.Mutex
+	sem := make(chan struct{}, 5)
+	var wg sync.WaitGroup
+	for _, link := range links {
+		if urlnorm.SiteHost(link) == sourceHost {
+			cache.updateWebmention(link, func(r *webmentionRecord) { r.Protocol = "none" })
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(target string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer recoverPanic(trf("发送 Webmention %s", target), nil)
+
+			protocol, err := sendMention(ctx, cfg.Retry, cfg.WebmentionSource, target)
+			cache.updateWebmention(target, func(r *webmentionRecord) {
+				r.Attempts++
+				r.Protocol = protocol
+				r.Error = ""
+				if err != nil {
+					r.Error = err.Error()
+				} else if protocol != "none" {
+					r.SentAt = time.Now()
+				}
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				failed++
+				appendLog("[WARN] " + trf("发送 Webmention 失败: %s: %v", target, err))
+			case protocol != "none":
+				sent++
+			}
+		}(link)
+	}
+	wg.Wait()
+	appendLog("[INFO] " + trf("已发送 %d 个 Webmention/Pingback, %d 个失败", sent, failed))
+}
+
+// sendMention 发现文章的通知端点并发送
+//
+// Returns:
+//   - string : 使用的协议：webmention、pingback，文章不支持时为 none
+//   - error  : 发现端点或发送失败的原因
+func sendMention(ctx context.Context, retry retryStrategy, source, target string) (string, error) {
+	var protocol, endpoint string
+	err := withRetry(ctx, retry, func(int) error {
+		var err error
+		protocol, endpoint, err = discoverMentionEndpoint(ctx, target)
+		return err
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+	if protocol == "none" {
+		return protocol, nil
+	}
+	return protocol, withRetry(ctx, retry, func(int) error {
+		if protocol == "pingback" {
+			return sendPingback(ctx, endpoint, source, target)
+		}
+		return sendWebmention(ctx, endpoint, source, target)
+	}, nil)
+}
+
+// discoverMentionEndpoint 发现文章页面声明的通知端点
+//
+// Description:
+//
+//	按 Webmention 规范依次查找响应头 Link: <...>; rel="webmention" 与页面中第一个 rel 含 webmention 的 <link>/<a>，
+//	相对地址基于跟随重定向后的页面地址解析，href 为空表示端点即页面本身；
+//	未声明 Webmention 时查找 X-Pingback 响应头与 <link rel="pingback">
+//
+// Returns:
+//   - protocol : webmention、pingback 或 none
+//   - endpoint : 端点地址
+//   - err      : 请求失败（网络错误、429、5xx 可重试，其他状态码不可重试）
+func discoverMentionEndpoint(ctx context.Context, target string) (protocol, endpoint string, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		return "", "", noRetry(err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+	resp, err := pageClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if err := mentionStatusError(resp); err != nil {
+		return "", "", err
+	}
+	base := resp.Request.URL.String()
+
+	pingback := resp.Header.Get("X-Pingback")
+	for _, h := range resp.Header.Values("Link") {
+		for _, part := range strings.Split(h, ",") {
+			href, params, ok := strings.Cut(part, ";")
+			if ok && hasRelToken(linkParamRel(params), "webmention") {
+				return "webmention", makeAbsoluteURL(base, strings.Trim(strings.TrimSpace(href), "<>")), nil
+			}
+		}
+	}
+
+	z := html.NewTokenizer(io.LimitReader(resp.Body, logoPageMaxBytes))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if pingback != "" {
+				return "pingback", makeAbsoluteURL(base, pingback), nil
+			}
+			return "none", "", nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if !hasAttr || (string(name) != "link" && string(name) != "a") {
+				continue
+			}
+			var rel, href string
+			hasHref := false
+			for {
+				key, val, more := z.TagAttr()
+				switch strings.ToLower(string(key)) {
+				case "rel":
+					rel = string(val)
+				case "href":
+					href, hasHref = strings.TrimSpace(string(val)), true
+				}
+				if !more {
+					break
+				}
+			}
+			switch {
+			case hasHref && hasRelToken(rel, "webmention"):
+				if href == "" {
+					return "webmention", base, nil
+				}
+				return "webmention", makeAbsoluteURL(base, href), nil
+			case pingback == "" && href != "" && string(name) == "link" && hasRelToken(rel, "pingback"):
+				pingback = href
+			}
+		}
+	}
+}
+
+// linkParamRel 提取 Link 响应头参数中的 rel 值
+func linkParamRel(params string) string {
+	for _, p := range strings.Split(params, ";") {
+		key, val, ok := strings.Cut(strings.TrimSpace(p), "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "rel") {
+			return strings.Trim(strings.TrimSpace(val), `"`)
+		}
+	}
+	return ""
+}
+
+// hasRelToken rel 属性（以空白分隔的多个值）是否包含 token
+func hasRelToken(rel, token string) bool {
+	for _, r := range strings.Fields(rel) {
+		if strings.EqualFold(r, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionStatusError 将非 2xx 响应转换为错误：429 与 5xx 可重试，其余不可重试
+func mentionStatusError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err := fmt.Errorf("HTTP %d", resp.StatusCode)
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return err
+	}
+	return noRetry(err)
+}
+
+// sendWebmention 向 Webmention 端点提交 source 与 target
+func sendWebmention(ctx context.Context, endpoint, source, target string) error {
+	form := url.Values{"source": {source}, "target": {target}}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return noRetry(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+	resp, err := pageClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return mentionStatusError(resp)
+}
+
+// pingbackFaultAlreadyRegistered Pingback 错误码 48：该通知已登记过，视为成功
+const pingbackFaultAlreadyRegistered = 48
+
+// sendPingback 通过 XML-RPC 调用 pingback.ping(source, target)
+func sendPingback(ctx context.Context, endpoint, source, target string) error {
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0"?><methodCall><methodName>pingback.ping</methodName><params>`)
+	for _, v := range []string{source, target} {
+		body.WriteString("<param><value><string>")
+		xml.EscapeText(&body, []byte(v))
+		body.WriteString("</string></value></param>")
+	}
+	body.WriteString("</params></methodCall>")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, &body)
+	if err != nil {
+		return noRetry(err)
+	}
+	req.Header.Set("Content-Type", "text/xml")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; RSSFetcher/1.0)")
+	resp, err := pageClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := mentionStatusError(resp); err != nil {
+		return err
+	}
+
+	var result struct {
+		Fault []struct {
+			Name  string `xml:"name"`
+			Value struct {
+				Int    string `xml:"int"`
+				I4     string `xml:"i4"`
+				String string `xml:"string"`
+				Text   string `xml:",chardata"` // 未标注类型的值按字符串处理
+			} `xml:"value"`
+		} `xml:"fault>value>struct>member"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil || len(result.Fault) == 0 {
+		return nil
+	}
+	var code, msg string
+	for _, m := range result.Fault {
+		switch m.Name {
+		case "faultCode":
+			code = strings.TrimSpace(m.Value.Int + m.Value.I4)
+		case "faultString":
+			msg = strings.TrimSpace(m.Value.String + m.Value.Text)
+		}
+	}
+	if code == fmt.Sprint(pingbackFaultAlreadyRegistered) {
+		return nil
+	}
+	return noRetry(fmt.Errorf("pingback fault %s: %s", code, msg))
+}
@@ -15,6 +15,8 @@ import (
 //
 //	本函数在生成错误时，通过 runtime.Caller(1) 获取到上层调用位置的文件名和行号，
 //	并将其包装到最终错误信息中，方便定位问题
+//	原始错误通过 %w 保留，判断错误类别（如 ErrParse、ErrTimeout）时应使用 errors.Is / errors.As，
+//	不要匹配错误信息字符串，错误信息会随措辞和语言（LHASA_LANG）变化
 //
 // Parameters:
 //   - err           : 原始错误
@@ -27,6 +29,6 @@ func wrapErrorf(err error, format string, args ...interface{}) error {
 		return nil
 	}
 	_, file, line, _ := runtime.Caller(1) // 获取上层调用者的文件名和行号
-	msg := fmt.Sprintf(format, args...)
-	return fmt.Errorf("%s:%d => %s | 原因: %w", file, line, msg, err)
+	msg := trf(format, args...)
+	return fmt.Errorf("%s:%d => %s | %s: %w", file, line, msg, tr("原因"), err)
 }
@@ -0,0 +1,189 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: zombie_detector.go
+// Description: 僵尸内容检测，识别域名过期后被停放、出售或被换成完全不同站点的订阅，避免把垃圾内容发布到 data.json
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/achuanya/lhasaRSS/urlnorm"
+	"github.com/mmcdole/gofeed"
+)
+
+// feedProfile 订阅上次正常时的特征，用于与本次抓取结果对比
+type feedProfile struct {
+	Title  string    `json:"title"`   // RSS 原始标题
+	Script string    `json:"script"`  // 主要文字类型: han / latin / other
+	Host   string    `json:"host"`    // 博客主页域名
+	SeenAt time.Time `json:"seen_at"` // 记录时间
+}
+
+// parkedHosts 域名停放、出售服务的可注册域名，订阅跟随重定向后落到这些域名（或主页指向它们）即视为停放
+var parkedHosts = []string{
+	"sedoparking.com", "sedo.com", "parkingcrew.net", "bodis.com", "above.com", "hugedomains.com",
+	"afternic.com", "dan.com", "undeveloped.com", "domainmarket.com", "godaddy.com",
+}
+
+// parkedKeywords 域名停放、出售页面的常见文字，只在订阅标题与简介中匹配（文章正文可能正常地讨论域名交易）
+var parkedKeywords = []string{
+	"domain is for sale", "buy this domain", "this domain may be for sale", "domain parking",
+	"域名出售", "此域名出售", "该域名正在出售", "域名正在出售", "域名停放", "购买此域名",
+}
+
+// spamKeywords 广告站、博彩站的常见文字
+var spamKeywords = []string{
+	"casino", "betting", "viagra", "slot online", "judi online", "togel", "bet365",
+	"博彩", "娱乐城", "百家乐", "彩票平台", "体育投注", "真人荷官", "贷款秒批",
+}
+
+// feedProfile 读取订阅上次的特征
+func (c *fetchCache) feedProfile(feedURL string) (feedProfile, bool) {
+	return cacheLookup(c, func(c *fetchCache) *map[string]feedProfile { return &c.FeedProfiles }, feedURL)
+}
+
+// setFeedProfile 保存订阅本次的特征
+func (c *fetchCache) setFeedProfile(feedURL string, p feedProfile) {
+	cacheAssign(c, func(c *fetchCache) *map[string]feedProfile { return &c.FeedProfiles }, feedURL, p)
+}
+
+// detectZombieFeeds 检查抓取成功的订阅是否已变成停放页、广告站或完全不同的站点
+//
+// Description:
+//
+//	满足以下任一条件即视为僵尸订阅：跟随重定向后的地址或主页落在域名停放服务上；订阅标题、简介包含域名出售文字；
+//	半数以上文章包含博彩等垃圾关键词；与上次正常时相比，RSS 标题改变且主要文字类型或主页域名也发生变化
+//	ack（ZOMBIE_ACK）中的域名（含子域名）或订阅地址视为已确认的站点迁移，跳过标题与域名变化的对比并以本次特征为新基准
+//	僵尸订阅的结果会被标记为错误，不进入 data.json，并记入 problems["zombieFeeds"]；
+//	只有正常的订阅才会更新缓存中的特征，确保对比基准始终是最后一次正常的状态
+func detectZombieFeeds(results []feedResult, cache *fetchCache, ack []string, problems map[string][]string) {
+	for i := range results {
+		r := &results[i]
+		if r.Err != nil || r.Feed == nil {
+			continue
+		}
+		current := profileOf(r.Feed)
+		reason := zombieReason(r.Feed, r.FinalURL, current, cache, r.FeedLink, zombieAcked(ack, r.FeedLink))
+		if reason == "" {
+			cache.setFeedProfile(r.FeedLink, current)
+			continue
+		}
+		r.Err = fmt.Errorf("疑似僵尸订阅: %s (%s)", r.FeedLink, reason)
+		r.Article = nil
+		problems["zombieFeeds"] = append(problems["zombieFeeds"], fmt.Sprintf("%s (%s)", r.FeedLink, reason))
+	}
+}
+
+// zombieReason 返回判定为僵尸订阅的原因，正常时返回空字符串
+//
+// Parameters:
+//   - finalURL : 跟随重定向后实际抓取的地址，为空时只检查订阅主页
+//   - acked    : 是否已确认站点迁移，为 true 时不与上次的特征对比
+func zombieReason(feed *gofeed.Feed, finalURL string, current feedProfile, cache *fetchCache, feedURL string, acked bool) string {
+	for _, u := range []string{finalURL, feed.Link} {
+		if d := urlnorm.RegistrableDomain(u); d != "" && slices.Contains(parkedHosts, d) {
+			return tr("跳转到域名停放服务: ") + d
+		}
+	}
+	if kw := firstMatch(strings.ToLower(feed.Title+" "+feed.Description), parkedKeywords); kw != "" {
+		return tr("包含域名停放文字: ") + kw
+	}
+	spamItems := 0
+	for _, item := range feed.Items {
+		if containsAny(strings.ToLower(item.Title+" "+item.Description), spamKeywords) {
+			spamItems++
+		}
+	}
+	if len(feed.Items) > 0 && spamItems*2 >= len(feed.Items) {
+		return trf("%d/%d 篇文章包含垃圾关键词", spamItems, len(feed.Items))
+	}
+
+	prev, ok := cache.feedProfile(feedURL)
+	if acked || !ok || prev.Title == "" || prev.Title == current.Title {
+		return ""
+	}
+	switch {
+	case prev.Script != "" && current.Script != "" && prev.Script != current.Script:
+		return trf("标题由 %q 变为 %q, 且语言发生变化", prev.Title, current.Title)
+	case prev.Host != "" && current.Host != "" && prev.Host != current.Host:
+		return trf("标题由 %q 变为 %q, 且主页由 %s 变为 %s", prev.Title, current.Title, prev.Host, current.Host)
+	}
+	return ""
+}
+
+// zombieAcked 判断订阅是否在 ZOMBIE_ACK 中：按订阅地址完整匹配，或按域名匹配（含子域名）
+func zombieAcked(ack []string, feedURL string) bool {
+	host, key := urlnorm.Host(feedURL), urlnorm.Key(feedURL)
+	for _, a := range ack {
+		if strings.Contains(a, "/") {
+			if urlnorm.Key(a) == key {
+				return true
+			}
+			continue
+		}
+		d := strings.TrimPrefix(strings.ToLower(a), ".")
+		if host != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
+
+// profileOf 提取订阅的特征
+func profileOf(feed *gofeed.Feed) feedProfile {
+	sample := feed.Title
+	for i, item := range feed.Items {
+		if i >= 10 {
+			break
+		}
+		sample += " " + item.Title
+	}
+	p := feedProfile{Title: strings.TrimSpace(feed.Title), Script: dominantScript(sample), SeenAt: time.Now()}
+	p.Host = urlnorm.SiteHost(feed.Link)
+	return p
+}
+
+// dominantScript 统计文本中汉字与拉丁字母的比例，返回主要文字类型
+func dominantScript(s string) string {
+	var han, latin, other int
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.IsLetter(r):
+			other++
+		}
+	}
+	// 中文标题中常夹杂英文单词，汉字超过 1/5 即视为中文
+	switch {
+	case han == 0 && latin == 0 && other == 0:
+		return ""
+	case han*5 >= han+latin+other:
+		return "han"
+	case latin >= other:
+		return "latin"
+	default:
+		return "other"
+	}
+}
+
+// containsAny 判断 s 是否包含任一关键词
+func containsAny(s string, keywords []string) bool {
+	return firstMatch(s, keywords) != ""
+}
+
+// firstMatch 返回 s 中包含的第一个关键词
+func firstMatch(s string, keywords []string) string {
+	for _, kw := range keywords {
+		if strings.Contains(s, kw) {
+			return kw
+		}
+	}
+	return ""
+}
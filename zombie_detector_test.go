@@ -0,0 +1,49 @@
+// Author: 游钓四方 <haibao1027@gmail.com>
+// File: zombie_detector_test.go
+// Description: 僵尸订阅检测的测试，覆盖停放域名跳转、正文中提及域名交易、站点迁移确认
+
+package main
+
+import (
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestZombieReason(t *testing.T) {
+	normal := &gofeed.Feed{Title: "My Blog", Link: "https://blog.example.com/", Items: []*gofeed.Item{
+		{Title: "Why I bought a domain on dan.com", Description: "The domain is for sale on godaddy.com/domain, buy this domain now"},
+	}}
+	if got := zombieReason(normal, "https://blog.example.com/feed", profileOf(normal), nil, "https://blog.example.com/feed", false); got != "" {
+		t.Errorf("正文提及域名交易被误判: %s", got)
+	}
+	if got := zombieReason(normal, "https://www.dan.com/buy-domain/example.com", profileOf(normal), nil, "https://blog.example.com/feed", false); got == "" {
+		t.Error("跳转到 dan.com 的订阅未被识别")
+	}
+	parked := &gofeed.Feed{Title: "example.com - This domain may be for sale", Link: "https://example.com/"}
+	if got := zombieReason(parked, "", profileOf(parked), nil, "https://example.com/feed", false); got == "" {
+		t.Error("标题含域名出售文字的订阅未被识别")
+	}
+}
+
+func TestZombieReasonAck(t *testing.T) {
+	feedURL := "https://old.example.com/feed"
+	cache := &fetchCache{}
+	cache.setFeedProfile(feedURL, feedProfile{Title: "旧博客", Script: "han", Host: "old.example.com"})
+	moved := &gofeed.Feed{Title: "New Blog", Link: "https://new.example.org/", Items: []*gofeed.Item{{Title: "Hello world"}}}
+
+	if got := zombieReason(moved, feedURL, profileOf(moved), cache, feedURL, false); got == "" {
+		t.Fatal("标题与语言同时变化的订阅未被识别")
+	}
+	for _, ack := range [][]string{{"example.com"}, {"https://old.example.com/feed/"}} {
+		if !zombieAcked(ack, feedURL) {
+			t.Errorf("zombieAcked(%v) = false, want true", ack)
+		}
+	}
+	if zombieAcked([]string{"ample.com", "https://old.example.com/other"}, feedURL) {
+		t.Error("zombieAcked 匹配了无关的域名或地址")
+	}
+	if got := zombieReason(moved, feedURL, profileOf(moved), cache, feedURL, true); got != "" {
+		t.Errorf("已确认迁移的订阅仍被判定为僵尸订阅: %s", got)
+	}
+}